@@ -0,0 +1,47 @@
+package audio
+
+import "math"
+
+// RMS returns the root mean square of samples, zero if empty.
+func RMS(samples []float32) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// Peak returns the largest absolute value of samples, zero if empty.
+func Peak(samples []float32) float32 {
+	var peak float32
+	for _, s := range samples {
+		peak = max(peak, s, -s)
+	}
+	return peak
+}
+
+// ToDecibels converts a linear level, such as returned by RMS, to decibels
+// relative to full scale. Zero maps to negative infinity.
+func ToDecibels(level float64) float64 {
+	return 20 * math.Log10(level)
+}
+
+// ZeroCrossingRate returns the fraction of consecutive samples changing sign,
+// zero if there are fewer than two samples.
+func ZeroCrossingRate(samples []float32) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	var crossings int
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1] >= 0) != (samples[i] >= 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / float64(len(samples)-1)
+}
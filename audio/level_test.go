@@ -0,0 +1,29 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	require.Zero(t, RMS(nil))
+	require.Zero(t, Peak(nil))
+	require.Equal(t, 0.5, RMS([]float32{0.5, -0.5, 0.5, -0.5}))
+	require.InDelta(t, 1/math.Sqrt2, RMS(sine(100, 16000, 16000)), 1e-3)
+	require.Equal(t, float32(0.75), Peak([]float32{0.25, -0.75, 0.5}))
+
+	require.Equal(t, 0.0, ToDecibels(1))
+	require.InDelta(t, -6.02, ToDecibels(0.5), 1e-2)
+	require.True(t, math.IsInf(ToDecibels(0), -1))
+}
+
+func TestZeroCrossingRate(t *testing.T) {
+	require.Zero(t, ZeroCrossingRate(nil))
+	require.Zero(t, ZeroCrossingRate([]float32{0.5}))
+	require.Zero(t, ZeroCrossingRate([]float32{0.1, 0.2, 0, 0.3}))
+	require.Equal(t, 1.0, ZeroCrossingRate([]float32{0.5, -0.5, 0.5, -0.5}))
+	// A 100Hz tone crosses zero twice per period.
+	require.InDelta(t, 200.0/16000, ZeroCrossingRate(sine(100, 16000, 16000)), 1e-3)
+}
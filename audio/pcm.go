@@ -0,0 +1,117 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Encoding identifies the sample format of raw PCM audio.
+type Encoding int
+
+const (
+	// EncodingFloat32LE is 32-bit IEEE float little-endian PCM.
+	EncodingFloat32LE Encoding = iota + 1
+	// EncodingS16LE is 16-bit signed integer little-endian PCM.
+	EncodingS16LE
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingFloat32LE:
+		return "f32le"
+	case EncodingS16LE:
+		return "s16le"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+// ParseEncoding parses an encoding from its string representation (e.g. "s16le").
+func ParseEncoding(s string) (Encoding, error) {
+	for e := EncodingFloat32LE; e <= EncodingS16LE; e++ {
+		if s == e.String() {
+			return e, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid encoding %q", s)
+}
+
+// SampleSize returns the size in bytes of a single sample.
+func (e Encoding) SampleSize() int {
+	switch e {
+	case EncodingFloat32LE:
+		return 4
+	case EncodingS16LE:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// DecodeSamples appends the samples encoded in data to dst and returns the
+// extended slice. Trailing bytes not forming a full sample are ignored.
+func DecodeSamples(dst []float32, data []byte, enc Encoding) []float32 {
+	switch enc {
+	case EncodingFloat32LE:
+		for i := 0; i+4 <= len(data); i += 4 {
+			dst = append(dst, math.Float32frombits(binary.LittleEndian.Uint32(data[i:])))
+		}
+	case EncodingS16LE:
+		for i := 0; i+2 <= len(data); i += 2 {
+			dst = append(dst, float32(int16(binary.LittleEndian.Uint16(data[i:])))/32768)
+		}
+	}
+	return dst
+}
+
+// EncodeSamples appends the encoded samples to dst and returns the extended
+// slice. Integer encodings clip samples to the [-1, 1] range.
+func EncodeSamples(dst []byte, samples []float32, enc Encoding) []byte {
+	switch enc {
+	case EncodingFloat32LE:
+		for _, s := range samples {
+			dst = binary.LittleEndian.AppendUint32(dst, math.Float32bits(s))
+		}
+	case EncodingS16LE:
+		for _, s := range samples {
+			dst = binary.LittleEndian.AppendUint16(dst, uint16(floatToInt16(s)))
+		}
+	}
+	return dst
+}
+
+func floatToInt16(s float32) int16 {
+	v := math.Round(float64(s) * 32768)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+// ReadPCM reads raw mono PCM samples from r until EOF.
+func ReadPCM(r io.Reader, enc Encoding) ([]float32, error) {
+	sampleSize := enc.SampleSize()
+	if sampleSize == 0 {
+		return nil, fmt.Errorf("invalid encoding: %s", enc)
+	}
+
+	br := bufio.NewReader(r)
+	buf := make([]byte, 4096*sampleSize)
+	var samples []float32
+	for {
+		n, err := io.ReadFull(br, buf)
+		samples = DecodeSamples(samples, buf[:n], enc)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read samples: %w", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEncoding(t *testing.T) {
+	for _, enc := range []Encoding{EncodingFloat32LE, EncodingS16LE} {
+		parsed, err := ParseEncoding(enc.String())
+		require.NoError(t, err)
+		require.Equal(t, enc, parsed)
+	}
+
+	_, err := ParseEncoding("u8")
+	require.EqualError(t, err, `invalid encoding "u8"`)
+}
+
+func TestEncodeDecodeSamples(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 1, -1}
+
+	t.Run("f32le", func(t *testing.T) {
+		data := EncodeSamples(nil, samples, EncodingFloat32LE)
+		require.Len(t, data, len(samples)*4)
+		require.Equal(t, samples, DecodeSamples(nil, data, EncodingFloat32LE))
+	})
+
+	t.Run("s16le", func(t *testing.T) {
+		data := EncodeSamples(nil, samples, EncodingS16LE)
+		require.Len(t, data, len(samples)*2)
+		decoded := DecodeSamples(nil, data, EncodingS16LE)
+		require.Len(t, decoded, len(samples))
+		for i := range samples {
+			require.InDelta(t, samples[i], decoded[i], 1.0/32768)
+		}
+	})
+
+	t.Run("clipping", func(t *testing.T) {
+		data := EncodeSamples(nil, []float32{2, -2}, EncodingS16LE)
+		require.Equal(t, []byte{0xff, 0x7f, 0x00, 0x80}, data)
+	})
+
+	t.Run("partial sample", func(t *testing.T) {
+		data := EncodeSamples(nil, samples, EncodingFloat32LE)
+		require.Len(t, DecodeSamples(nil, data[:len(data)-1], EncodingFloat32LE), len(samples)-1)
+	})
+}
+
+func TestReadPCM(t *testing.T) {
+	samples := make([]float32, 10000)
+	for i := range samples {
+		samples[i] = float32(i%100) / 100
+	}
+
+	read, err := ReadPCM(bytes.NewReader(EncodeSamples(nil, samples, EncodingFloat32LE)), EncodingFloat32LE)
+	require.NoError(t, err)
+	require.Equal(t, samples, read)
+
+	_, err = ReadPCM(bytes.NewReader(nil), Encoding(0))
+	require.EqualError(t, err, "invalid encoding: Encoding(0)")
+}
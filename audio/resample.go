@@ -0,0 +1,151 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// The number of input samples, on each side, contributing to an output
+// sample when upsampling. It grows proportionally when downsampling.
+const resampleHalfTaps = 16
+
+// Resample converts samples from one sample rate to another using windowed
+// sinc interpolation. When downsampling, the signal is low-pass filtered at
+// the target Nyquist frequency to avoid aliasing.
+func Resample(samples []float32, from, to int) []float32 {
+	if from == to || from <= 0 || to <= 0 {
+		return append([]float32(nil), samples...)
+	}
+
+	r, _ := NewResampler(from, to)
+	out := make([]float32, 0, int(int64(len(samples))*int64(to)/int64(from)))
+	out = r.Process(out, samples)
+	return r.Flush(out)
+}
+
+// Resampler converts a stream of samples from one sample rate to another,
+// producing the same output as Resample would on the whole stream. Output
+// samples are emitted as soon as all the input samples they depend on are
+// available, so the output lags behind the input by a few milliseconds until
+// Flush is called.
+type Resampler struct {
+	from, to int
+	// The ratio of the output rate to the input rate.
+	ratio float64
+	// The cutoff frequency relative to the input Nyquist frequency.
+	cutoff float64
+	// The number of input samples, on each side, contributing to an output sample.
+	halfWidth float64
+
+	// The input samples still needed, buf[0] being at index offset in the stream.
+	buf    []float32
+	offset int
+	// The number of input samples received.
+	consumed int
+	// The number of output samples produced.
+	produced int
+}
+
+func NewResampler(from, to int) (*Resampler, error) {
+	if from <= 0 || to <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: should be a positive number")
+	}
+
+	ratio := float64(to) / float64(from)
+	cutoff := math.Min(1, ratio)
+
+	return &Resampler{
+		from:      from,
+		to:        to,
+		ratio:     ratio,
+		cutoff:    cutoff,
+		halfWidth: resampleHalfTaps / cutoff,
+	}, nil
+}
+
+// Process appends the output samples which can be computed after receiving
+// samples to dst and returns the extended slice.
+func (r *Resampler) Process(dst, samples []float32) []float32 {
+	r.consumed += len(samples)
+	if r.from == r.to {
+		r.produced += len(samples)
+		return append(dst, samples...)
+	}
+
+	r.buf = append(r.buf, samples...)
+	for {
+		pos := float64(r.produced) / r.ratio
+		if int(math.Floor(pos+r.halfWidth)) >= r.consumed {
+			break
+		}
+		dst = append(dst, r.interpolate(pos))
+		r.produced++
+	}
+
+	// Dropping the samples which won't contribute to any further output.
+	pos := float64(r.produced) / r.ratio
+	start := min(max(int(math.Ceil(pos-r.halfWidth)), 0), r.consumed)
+	if n := start - r.offset; n > 0 {
+		r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+		r.offset = start
+	}
+
+	return dst
+}
+
+// Flush appends the remaining output samples, treating the samples received
+// so far as the end of the stream, to dst and returns the extended slice. The
+// resampler is reset afterwards.
+func (r *Resampler) Flush(dst []float32) []float32 {
+	if r.from != r.to {
+		total := int(int64(r.consumed) * int64(r.to) / int64(r.from))
+		for ; r.produced < total; r.produced++ {
+			dst = append(dst, r.interpolate(float64(r.produced)/r.ratio))
+		}
+	}
+
+	r.Reset()
+
+	return dst
+}
+
+// Reset discards any buffered input so that the resampler can be used for a
+// new stream.
+func (r *Resampler) Reset() {
+	r.buf = r.buf[:0]
+	r.offset = 0
+	r.consumed = 0
+	r.produced = 0
+}
+
+// interpolate returns the output sample at the given input position.
+func (r *Resampler) interpolate(pos float64) float32 {
+	start := max(int(math.Ceil(pos-r.halfWidth)), 0)
+	end := min(int(math.Floor(pos+r.halfWidth)), r.consumed-1)
+
+	var sum, weights float64
+	for j := start; j <= end; j++ {
+		x := float64(j) - pos
+		w := r.cutoff * sinc(r.cutoff*x) * blackman(x/r.halfWidth)
+		sum += w * float64(r.buf[j-r.offset])
+		weights += w
+	}
+	// Normalizing by the sum of weights keeps unity gain, including near
+	// the edges where the kernel is truncated.
+	if weights == 0 {
+		return 0
+	}
+	return float32(sum / weights)
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman returns the Blackman window value for x in [-1, 1].
+func blackman(x float64) float64 {
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
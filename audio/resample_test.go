@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sine(freq float64, sampleRate, n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(math.Sin(2 * math.Pi * freq * float64(i) / float64(sampleRate)))
+	}
+	return samples
+}
+
+func TestResample(t *testing.T) {
+	t.Run("same rate", func(t *testing.T) {
+		samples := []float32{1, 2, 3}
+		out := Resample(samples, 16000, 16000)
+		require.Equal(t, samples, out)
+		out[0] = 0
+		require.Equal(t, float32(1), samples[0])
+	})
+
+	t.Run("downsample", func(t *testing.T) {
+		in := sine(440, 48000, 48000)
+		out := Resample(in, 48000, 16000)
+		require.Len(t, out, 16000)
+
+		expected := sine(440, 16000, 16000)
+		// Skipping the edges where the kernel is truncated.
+		for i := 100; i < len(out)-100; i++ {
+			require.InDelta(t, expected[i], out[i], 0.01)
+		}
+	})
+
+	t.Run("upsample", func(t *testing.T) {
+		in := sine(440, 8000, 8000)
+		out := Resample(in, 8000, 16000)
+		require.Len(t, out, 16000)
+
+		expected := sine(440, 16000, 16000)
+		for i := 100; i < len(out)-100; i++ {
+			require.InDelta(t, expected[i], out[i], 0.01)
+		}
+	})
+
+	t.Run("anti-aliasing", func(t *testing.T) {
+		// A 12kHz tone is above the 8kHz Nyquist frequency of the output and
+		// should get filtered out rather than aliased.
+		out := Resample(sine(12000, 48000, 48000), 48000, 16000)
+		require.Less(t, RMS(out[100:len(out)-100]), 0.05)
+	})
+}
+
+func TestResampler(t *testing.T) {
+	_, err := NewResampler(0, 16000)
+	require.EqualError(t, err, "invalid sample rate: should be a positive number")
+
+	in := sine(440, 48000, 4800)
+	for _, rates := range [][2]int{{48000, 16000}, {8000, 16000}, {44100, 16000}, {16000, 16000}} {
+		expected := Resample(in, rates[0], rates[1])
+
+		r, err := NewResampler(rates[0], rates[1])
+		require.NoError(t, err)
+
+		// Uneven chunks, including empty ones.
+		var out []float32
+		sizes := []int{0, 1, 97, 500, 13}
+		for i, n := 0, 0; i < len(in); n++ {
+			size := min(sizes[n%len(sizes)], len(in)-i)
+			out = r.Process(out, in[i:i+size])
+			i += size
+		}
+		require.LessOrEqual(t, len(out), len(expected))
+		out = r.Flush(out)
+		require.Equal(t, expected, out, "%d -> %d", rates[0], rates[1])
+
+		// Flush resets the resampler.
+		require.Equal(t, expected, r.Flush(r.Process(nil, in)))
+	}
+}
@@ -0,0 +1,216 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+
+	// Data chunk sizes written by encoders which can't seek back to fill in the
+	// actual size, e.g. when writing to a pipe.
+	wavUnknownSize = 0xFFFFFFFF
+)
+
+// WAVFormat describes the sample format of a WAV stream.
+type WAVFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	// Whether samples are IEEE floats rather than integers.
+	Float bool
+}
+
+func (f WAVFormat) IsValid() error {
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("invalid SampleRate: should be a positive number")
+	}
+
+	if f.Channels <= 0 {
+		return fmt.Errorf("invalid Channels: should be a positive number")
+	}
+
+	if f.Float {
+		if f.BitsPerSample != 32 && f.BitsPerSample != 64 {
+			return fmt.Errorf("invalid BitsPerSample: valid values for float samples are 32 and 64")
+		}
+	} else if f.BitsPerSample != 8 && f.BitsPerSample != 16 && f.BitsPerSample != 24 && f.BitsPerSample != 32 {
+		return fmt.Errorf("invalid BitsPerSample: valid values for integer samples are 8, 16, 24 and 32")
+	}
+
+	return nil
+}
+
+// ReadWAV reads a WAV stream from r and returns its samples, downmixed to mono
+// by averaging channels, along with its format.
+func ReadWAV(r io.Reader) ([]float32, WAVFormat, error) {
+	br := bufio.NewReader(r)
+
+	var header [12]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, WAVFormat{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, WAVFormat{}, fmt.Errorf("invalid WAV header")
+	}
+
+	var format WAVFormat
+	var hasFormat bool
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(br, chunkHeader[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, WAVFormat{}, fmt.Errorf("missing data chunk")
+			}
+			return nil, WAVFormat{}, fmt.Errorf("failed to read chunk header: %w", err)
+		}
+		id := string(chunkHeader[0:4])
+		size := binary.LittleEndian.Uint32(chunkHeader[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, WAVFormat{}, fmt.Errorf("invalid fmt chunk size %d", size)
+			}
+			data := make([]byte, size+size%2)
+			if _, err := io.ReadFull(br, data); err != nil {
+				return nil, WAVFormat{}, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+			var err error
+			if format, err = parseWAVFormat(data[:size]); err != nil {
+				return nil, WAVFormat{}, err
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, WAVFormat{}, fmt.Errorf("missing fmt chunk")
+			}
+			var data io.Reader = br
+			if size != 0 && size != wavUnknownSize {
+				data = io.LimitReader(br, int64(size))
+			}
+			samples, err := readWAVSamples(data, format)
+			if err != nil {
+				return nil, WAVFormat{}, err
+			}
+			return samples, format, nil
+		default:
+			if _, err := io.CopyN(io.Discard, br, int64(size)+int64(size%2)); err != nil {
+				return nil, WAVFormat{}, fmt.Errorf("failed to skip %q chunk: %w", id, err)
+			}
+		}
+	}
+}
+
+func parseWAVFormat(data []byte) (WAVFormat, error) {
+	tag := binary.LittleEndian.Uint16(data[0:2])
+	if tag == wavFormatExtensible {
+		if len(data) < 26 {
+			return WAVFormat{}, fmt.Errorf("invalid extensible fmt chunk size %d", len(data))
+		}
+		// The sub format GUID starts with the actual format tag.
+		tag = binary.LittleEndian.Uint16(data[24:26])
+	}
+
+	if tag != wavFormatPCM && tag != wavFormatFloat {
+		return WAVFormat{}, fmt.Errorf("unsupported WAV format %d", tag)
+	}
+
+	format := WAVFormat{
+		Channels:      int(binary.LittleEndian.Uint16(data[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(data[4:8])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(data[14:16])),
+		Float:         tag == wavFormatFloat,
+	}
+	if err := format.IsValid(); err != nil {
+		return WAVFormat{}, fmt.Errorf("unsupported WAV format: %w", err)
+	}
+
+	return format, nil
+}
+
+func readWAVSamples(r io.Reader, format WAVFormat) ([]float32, error) {
+	sampleSize := format.BitsPerSample / 8
+	frameSize := sampleSize * format.Channels
+
+	buf := make([]byte, 1024*frameSize)
+	var samples []float32
+	for {
+		n, err := io.ReadFull(r, buf)
+		for i := 0; i+frameSize <= n; i += frameSize {
+			var sum float32
+			for c := 0; c < format.Channels; c++ {
+				sum += decodeWAVSample(buf[i+c*sampleSize:], format)
+			}
+			samples = append(samples, sum/float32(format.Channels))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read samples: %w", err)
+		}
+	}
+}
+
+func decodeWAVSample(data []byte, format WAVFormat) float32 {
+	if format.Float {
+		if format.BitsPerSample == 64 {
+			return float32(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(data))
+	}
+
+	switch format.BitsPerSample {
+	case 8:
+		// 8-bit samples are unsigned.
+		return float32(int(data[0])-128) / 128
+	case 16:
+		return float32(int16(binary.LittleEndian.Uint16(data))) / (1 << 15)
+	case 24:
+		v := int32(uint32(data[0])<<8|uint32(data[1])<<16|uint32(data[2])<<24) >> 8
+		return float32(v) / (1 << 23)
+	default:
+		return float32(float64(int32(binary.LittleEndian.Uint32(data))) / (1 << 31))
+	}
+}
+
+// WriteWAV writes samples to w as a mono 16-bit PCM WAV stream.
+func WriteWAV(w io.Writer, samples []float32, sampleRate int) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: should be a positive number")
+	}
+
+	dataSize := len(samples) * 2
+	header := make([]byte, 0, 44)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(36+dataSize))
+	header = append(header, "WAVE"...)
+	header = append(header, "fmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16)
+	header = binary.LittleEndian.AppendUint16(header, wavFormatPCM)
+	header = binary.LittleEndian.AppendUint16(header, 1)
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate*2))
+	header = binary.LittleEndian.AppendUint16(header, 2)
+	header = binary.LittleEndian.AppendUint16(header, 16)
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(dataSize))
+
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	if _, err := w.Write(EncodeSamples(nil, samples, EncodingS16LE)); err != nil {
+		return fmt.Errorf("failed to write samples: %w", err)
+	}
+
+	return nil
+}
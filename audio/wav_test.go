@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func buildWAV(format uint16, channels, sampleRate, bits int, data []byte, dataSize uint32, extra ...[]byte) []byte {
+	var buf []byte
+	buf = append(buf, "RIFF"...)
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	buf = append(buf, "WAVE"...)
+	for _, chunk := range extra {
+		buf = append(buf, chunk...)
+	}
+	buf = append(buf, "fmt "...)
+	buf = binary.LittleEndian.AppendUint32(buf, 16)
+	buf = binary.LittleEndian.AppendUint16(buf, format)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(channels))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(sampleRate))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(sampleRate*channels*bits/8))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(channels*bits/8))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(bits))
+	buf = append(buf, "data"...)
+	buf = binary.LittleEndian.AppendUint32(buf, dataSize)
+	return append(buf, data...)
+}
+
+func TestReadWAV(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		samples := []float32{0, 0.25, -0.25, 0.5, -0.5, 0.999}
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteWAV(&buf, samples, 16000))
+		require.Equal(t, 44+len(samples)*2, buf.Len())
+
+		read, format, err := ReadWAV(&buf)
+		require.NoError(t, err)
+		require.Equal(t, WAVFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, format)
+		require.Len(t, read, len(samples))
+		for i := range samples {
+			require.InDelta(t, samples[i], read[i], 1.0/32768)
+		}
+	})
+
+	t.Run("sample formats", func(t *testing.T) {
+		f32 := binary.LittleEndian.AppendUint32(nil, math.Float32bits(0.5))
+		f64 := binary.LittleEndian.AppendUint64(nil, math.Float64bits(-0.5))
+
+		tcs := []struct {
+			name   string
+			format uint16
+			bits   int
+			data   []byte
+		}{
+			{name: "u8", format: wavFormatPCM, bits: 8, data: []byte{192}},
+			{name: "s16", format: wavFormatPCM, bits: 16, data: []byte{0x00, 0x40}},
+			{name: "s24", format: wavFormatPCM, bits: 24, data: []byte{0x00, 0x00, 0xc0}},
+			{name: "s32", format: wavFormatPCM, bits: 32, data: []byte{0x00, 0x00, 0x00, 0x40}},
+			{name: "f32", format: wavFormatFloat, bits: 32, data: f32},
+			{name: "f64", format: wavFormatFloat, bits: 64, data: f64},
+		}
+
+		expected := []float32{0.5, 0.5, -0.5, 0.5, 0.5, -0.5}
+		for i, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				data := buildWAV(tc.format, 1, 8000, tc.bits, tc.data, uint32(len(tc.data)))
+				samples, format, err := ReadWAV(bytes.NewReader(data))
+				require.NoError(t, err)
+				require.Equal(t, tc.bits, format.BitsPerSample)
+				require.Equal(t, []float32{expected[i]}, samples)
+			})
+		}
+	})
+
+	t.Run("stereo downmix", func(t *testing.T) {
+		data := EncodeSamples(nil, []float32{0.5, 0, -0.5, -0.5}, EncodingS16LE)
+		samples, format, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatPCM, 2, 44100, 16, data, uint32(len(data)))))
+		require.NoError(t, err)
+		require.Equal(t, 2, format.Channels)
+		require.Equal(t, []float32{0.25, -0.5}, samples)
+	})
+
+	t.Run("unknown size and extra chunks", func(t *testing.T) {
+		list := append([]byte("LIST"), binary.LittleEndian.AppendUint32(nil, 3)...)
+		list = append(list, 'a', 'b', 'c', 0)
+		data := EncodeSamples(nil, []float32{0.5, -0.5}, EncodingS16LE)
+		samples, _, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatPCM, 1, 16000, 16, data, wavUnknownSize, list)))
+		require.NoError(t, err)
+		require.Equal(t, []float32{0.5, -0.5}, samples)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := ReadWAV(bytes.NewReader([]byte("RIFF")))
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+		_, _, err = ReadWAV(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI ")))
+		require.EqualError(t, err, "invalid WAV header")
+
+		_, _, err = ReadWAV(bytes.NewReader(buildWAV(2, 1, 16000, 4, nil, 0)))
+		require.EqualError(t, err, "unsupported WAV format 2")
+
+		_, _, err = ReadWAV(bytes.NewReader(buildWAV(wavFormatPCM, 1, 16000, 12, nil, 0)))
+		require.EqualError(t, err, "unsupported WAV format: invalid BitsPerSample: valid values for integer samples are 8, 16, 24 and 32")
+
+		_, _, err = ReadWAV(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WAVE")))
+		require.EqualError(t, err, "missing data chunk")
+	})
+}
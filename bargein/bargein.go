@@ -0,0 +1,382 @@
+package bargein
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+type Config struct {
+	// The input audio settings.
+	Stream stream.Config
+	// The speech probability threshold applied during playback, stricter
+	// than the detector one to reject residual echo. Defaults to 0.8.
+	PlaybackThreshold float32
+	// The duration speech should last above PlaybackThreshold during
+	// playback to confirm a barge-in. Defaults to 200ms.
+	MinBargeIn time.Duration
+	// The attenuation in dB the echo path is expected to apply at least.
+	// When reference audio is given, windows whose level is lower than the
+	// reference one minus this margin are considered echo. Defaults to 10dB.
+	EchoReturnLossDb float64
+}
+
+func (c Config) IsValid() error {
+	if err := c.Stream.IsValid(); err != nil {
+		return fmt.Errorf("invalid Stream: %w", err)
+	}
+
+	if c.PlaybackThreshold < 0 || c.PlaybackThreshold >= 1 {
+		return fmt.Errorf("invalid PlaybackThreshold: should be in range [0, 1)")
+	}
+
+	if c.MinBargeIn < 0 {
+		return fmt.Errorf("invalid MinBargeIn: should be a positive duration")
+	}
+
+	if c.EchoReturnLossDb < 0 {
+		return fmt.Errorf("invalid EchoReturnLossDb: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	if c.PlaybackThreshold == 0 {
+		c.PlaybackThreshold = 0.8
+	}
+	if c.MinBargeIn == 0 {
+		c.MinBargeIn = 200 * time.Millisecond
+	}
+	if c.EchoReturnLossDb == 0 {
+		c.EchoReturnLossDb = 10
+	}
+}
+
+type EventType int
+
+const (
+	// EventSpeechStart is emitted when speech starts outside playback, or
+	// along with EventBargeIn.
+	EventSpeechStart EventType = iota + 1
+	// EventSpeechEnd is emitted when a speech segment reported as started ends.
+	EventSpeechEnd
+	// EventProbability is emitted for each window when Stream.Probabilities is set.
+	EventProbability
+	// EventBargeIn is emitted once speech is confirmed during playback.
+	EventBargeIn
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSpeechStart:
+		return "start"
+	case EventSpeechEnd:
+		return "end"
+	case EventProbability:
+		return "probability"
+	case EventBargeIn:
+		return "barge_in"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a speech update. Times are in seconds from the beginning of the
+// stream.
+type Event struct {
+	Type EventType
+	// The start of the speech segment for speech events, or of the confirmed
+	// speech for EventBargeIn.
+	Start float64
+	// The end of the speech segment, for EventSpeechEnd.
+	End float64
+	// The end of the window, for EventProbability and EventBargeIn.
+	Time float64
+	// The speech probability of the window, for EventProbability.
+	Probability float32
+}
+
+// Monitor runs streaming detection while a bot plays audio back, detecting
+// the caller interrupting it. While playback is active, regular speech events
+// are held back and windows only count as speech above PlaybackThreshold and,
+// when reference playback audio is given, when loud enough compared to it.
+// Once such speech lasts MinBargeIn, EventBargeIn is emitted along with the
+// start of the speech segment.
+//
+// As with stream.Session, the detector isn't owned and shouldn't be used by
+// anything else meanwhile.
+type Monitor struct {
+	sd      *speech.Detector
+	cfg     Config
+	session *stream.Session
+	// The duration of detector windows in seconds, and the number of input
+	// samples they span, fractional when the input rate isn't a multiple of
+	// the model one.
+	windowDuration float64
+	windowSamples  float64
+	// Bytes not yet forming a full sample.
+	pending []byte
+
+	playback bool
+	bargedIn bool
+	// The confirmed speech during playback, in seconds, and since when.
+	run      float64
+	runStart float64
+	// Whether a start was reported, and the start held back during playback.
+	started bool
+	held    *Event
+
+	// Reference samples not yet matched with input ones.
+	reference []float32
+	// The input and reference energies of the window being accumulated, the
+	// number of input samples and windows measured so far, and the input to
+	// reference level ratios in dB of the windows not yet processed by the
+	// detector.
+	inputEnergy float64
+	refEnergy   float64
+	measured    int
+	windows     int
+	ratios      []float64
+}
+
+func NewMonitor(sd *speech.Detector, cfg Config) (*Monitor, error) {
+	if sd == nil {
+		return nil, speech.ErrNilDetector
+	}
+
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.setDefaults()
+
+	m := &Monitor{
+		sd:  sd,
+		cfg: cfg,
+	}
+	if err := m.reset(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Monitor) reset() error {
+	// Probabilities are needed to confirm barge-ins, but only reported if
+	// requested.
+	sessionCfg := m.cfg.Stream
+	sessionCfg.Probabilities = true
+	session, err := stream.NewSession(m.sd, sessionCfg)
+	if err != nil {
+		return err
+	}
+
+	inputRate := session.Config().SampleRate
+	m.cfg.Stream.SampleRate = inputRate
+	m.cfg.Stream.Encoding = session.Config().Encoding
+	*m = Monitor{
+		sd:             m.sd,
+		cfg:            m.cfg,
+		session:        session,
+		windowDuration: session.WindowDuration(),
+		windowSamples:  session.WindowDuration() * float64(inputRate),
+	}
+
+	return nil
+}
+
+// Config returns the monitor configuration, with defaults applied.
+func (m *Monitor) Config() Config {
+	return m.cfg
+}
+
+// Reset resets the detector and the monitor state, playback included. Times
+// start over from zero.
+func (m *Monitor) Reset() error {
+	if err := m.sd.Reset(); err != nil {
+		return err
+	}
+	return m.reset()
+}
+
+// Playback returns whether playback is active.
+func (m *Monitor) Playback() bool {
+	return m.playback
+}
+
+// SetPlayback sets whether playback is active. Each activation can result in
+// a single EventBargeIn. When playback stops, a speech segment that started
+// during playback without confirming a barge-in is reported as started since
+// it can't be told apart from the caller speaking right after.
+func (m *Monitor) SetPlayback(active bool) []Event {
+	if active == m.playback {
+		return nil
+	}
+	m.playback = active
+
+	if active {
+		m.bargedIn = false
+		m.run = 0
+		return nil
+	}
+
+	m.reference = m.reference[:0]
+	if m.held == nil {
+		return nil
+	}
+	events := []Event{*m.held}
+	m.held = nil
+	m.started = true
+
+	return events
+}
+
+// WriteReference queues audio being played back, at the input sample rate and
+// aligned with it, so that residual echo can be rejected. It's matched with
+// the following input samples and dropped when playback stops.
+func (m *Monitor) WriteReference(samples []float32) {
+	if !m.playback {
+		return
+	}
+	m.reference = append(m.reference, samples...)
+}
+
+// Write decodes data according to the configured encoding and processes the
+// resulting samples.
+func (m *Monitor) Write(ctx context.Context, data []byte) ([]Event, error) {
+	sampleSize := m.cfg.Stream.Encoding.SampleSize()
+	if len(m.pending) > 0 {
+		data = append(m.pending, data...)
+	}
+	n := len(data) - len(data)%sampleSize
+	samples := audio.DecodeSamples(nil, data[:n], m.cfg.Stream.Encoding)
+	m.pending = append(m.pending[:0], data[n:]...)
+
+	return m.WriteSamples(ctx, samples)
+}
+
+// WriteSamples processes samples at the configured sample rate and returns
+// the resulting events.
+func (m *Monitor) WriteSamples(ctx context.Context, samples []float32) ([]Event, error) {
+	m.measure(samples)
+	events, err := m.session.WriteSamples(ctx, samples)
+	return m.handle(events), err
+}
+
+// Flush processes the remaining audio and ends any reported speech segment.
+func (m *Monitor) Flush(ctx context.Context) ([]Event, error) {
+	events, err := m.session.Flush(ctx)
+	return m.handle(events), err
+}
+
+// measure accumulates the input and reference energies of samples, per window.
+// Windows end on the input sample nearest to their exact end, so that the
+// fraction of a sample they may span carries over instead of drifting.
+func (m *Monitor) measure(samples []float32) {
+	for len(samples) > 0 {
+		end := int(math.Round(float64(m.windows+1) * m.windowSamples))
+		n := min(end-m.measured, len(samples))
+		m.inputEnergy += energy(samples[:n])
+		ref := min(n, len(m.reference))
+		m.refEnergy += energy(m.reference[:ref])
+		m.reference = m.reference[ref:]
+		m.measured += n
+		samples = samples[n:]
+
+		if m.measured == end {
+			m.ratios = append(m.ratios, levelRatio(m.inputEnergy, m.refEnergy))
+			m.inputEnergy, m.refEnergy = 0, 0
+			m.windows++
+		}
+	}
+}
+
+func energy(samples []float32) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return sum
+}
+
+// levelRatio returns the ratio in dB between the input and reference levels,
+// positive infinity without reference.
+func levelRatio(input, ref float64) float64 {
+	if ref == 0 {
+		return math.Inf(1)
+	}
+	if input == 0 {
+		return math.Inf(-1)
+	}
+	return 10 * math.Log10(input/ref)
+}
+
+// handle turns session events into monitor events.
+func (m *Monitor) handle(events []stream.Event) []Event {
+	var out []Event
+	for _, ev := range events {
+		switch ev.Type {
+		case stream.EventProbability:
+			// Windows are measured as samples are written, so before the
+			// detector processes them.
+			ratio := math.Inf(1)
+			if len(m.ratios) > 0 {
+				ratio = m.ratios[0]
+				m.ratios = m.ratios[1:]
+			}
+			if m.cfg.Stream.Probabilities {
+				out = append(out, Event{Type: EventProbability, Time: ev.Time, Probability: ev.Probability})
+			}
+			out = m.window(out, ev.Time, ev.Probability, ratio)
+		case stream.EventSpeechStart:
+			start := Event{Type: EventSpeechStart, Start: ev.Start}
+			if m.playback && !m.bargedIn {
+				m.held = &start
+				continue
+			}
+			m.started = true
+			out = append(out, start)
+		case stream.EventSpeechEnd:
+			m.held = nil
+			if m.started {
+				m.started = false
+				out = append(out, Event{Type: EventSpeechEnd, Start: ev.Start, End: ev.End})
+			}
+		}
+	}
+	return out
+}
+
+// window processes a window ending at t during playback, confirming barge-ins.
+func (m *Monitor) window(out []Event, t float64, prob float32, ratio float64) []Event {
+	if !m.playback || m.bargedIn {
+		return out
+	}
+
+	threshold := max(m.cfg.PlaybackThreshold, m.sd.EffectiveThreshold())
+	if prob < threshold || ratio < -m.cfg.EchoReturnLossDb {
+		m.run = 0
+		return out
+	}
+
+	if m.run == 0 {
+		m.runStart = t - m.windowDuration
+	}
+	m.run += m.windowDuration
+	if m.run+1e-9 < m.cfg.MinBargeIn.Seconds() {
+		return out
+	}
+
+	m.bargedIn = true
+	if m.held != nil {
+		out = append(out, *m.held)
+		m.held = nil
+		m.started = true
+	}
+	return append(out, Event{Type: EventBargeIn, Start: m.runStart, Time: t})
+}
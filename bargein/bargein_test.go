@@ -0,0 +1,229 @@
+package bargein
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{
+		Stream:            stream.Config{SampleRate: 8000},
+		PlaybackThreshold: 0.9,
+		MinBargeIn:        time.Second,
+		EchoReturnLossDb:  20,
+	}.IsValid())
+
+	tcs := []struct {
+		cfg Config
+		err string
+	}{
+		{Config{Stream: stream.Config{SampleRate: -1}}, "invalid Stream: invalid SampleRate: should be a positive number"},
+		{Config{PlaybackThreshold: -0.1}, "invalid PlaybackThreshold: should be in range [0, 1)"},
+		{Config{PlaybackThreshold: 1}, "invalid PlaybackThreshold: should be in range [0, 1)"},
+		{Config{MinBargeIn: -1}, "invalid MinBargeIn: should be a positive duration"},
+		{Config{EchoReturnLossDb: -1}, "invalid EchoReturnLossDb: should be a positive number"},
+	}
+	for _, tc := range tcs {
+		require.EqualError(t, tc.cfg.IsValid(), tc.err)
+	}
+}
+
+func TestEventType(t *testing.T) {
+	require.Equal(t, "start", EventSpeechStart.String())
+	require.Equal(t, "end", EventSpeechEnd.String())
+	require.Equal(t, "probability", EventProbability.String())
+	require.Equal(t, "barge_in", EventBargeIn.String())
+	require.Equal(t, "EventType(0)", EventType(0).String())
+}
+
+func TestNewMonitor(t *testing.T) {
+	_, err := NewMonitor(nil, Config{})
+	require.ErrorIs(t, err, speech.ErrNilDetector)
+}
+
+func TestMeasure(t *testing.T) {
+	m := &Monitor{windowSamples: 4}
+
+	// Reference is ignored outside playback.
+	m.WriteReference([]float32{1, 1, 1, 1})
+	m.measure([]float32{0.5, 0.5})
+	require.Empty(t, m.ratios)
+	m.measure([]float32{0.5, 0.5, 0})
+	require.Equal(t, []float64{math.Inf(1)}, m.ratios)
+
+	m.SetPlayback(true)
+	m.WriteReference([]float32{1, 1, 1, 1, 1, 1, 1, 1, 1})
+	m.measure([]float32{0, 0, 0, 0.1, 0.1, 0.1, 0.1, 0.1})
+	require.Len(t, m.ratios, 3)
+	require.Equal(t, math.Inf(-1), m.ratios[1])
+	require.InDelta(t, -20, m.ratios[2], 1e-6)
+	require.Equal(t, []float32{1}, m.reference)
+
+	m.SetPlayback(false)
+	require.Empty(t, m.reference)
+
+	// Fractional windows, as with 44.1kHz input, alternate between sizes
+	// without drifting.
+	m = &Monitor{windowSamples: 1411.2}
+	samples := make([]float32, 14112)
+	for i := range samples {
+		samples[i] = 1
+	}
+	for i := 0; i < len(samples); i += 1000 {
+		m.measure(samples[i:min(i+1000, len(samples))])
+	}
+	require.Len(t, m.ratios, 10)
+	require.Zero(t, m.inputEnergy)
+}
+
+func TestMonitorHandle(t *testing.T) {
+	const window = 0.032
+
+	// windows returns the probability events of windows starting after from.
+	windows := func(from int, probs ...float32) []stream.Event {
+		events := make([]stream.Event, len(probs))
+		for i, p := range probs {
+			events[i] = stream.Event{Type: stream.EventProbability, Time: float64(from+i+1) * window, Probability: p}
+		}
+		return events
+	}
+	start := stream.Event{Type: stream.EventSpeechStart, Start: window}
+	end := stream.Event{Type: stream.EventSpeechEnd, Start: window, End: 10 * window}
+
+	newMonitor := func(playback bool, ratios ...float64) *Monitor {
+		cfg := Config{MinBargeIn: 64 * time.Millisecond}
+		cfg.setDefaults()
+		return &Monitor{
+			cfg:            cfg,
+			windowDuration: window,
+			windowSamples:  512,
+			playback:       playback,
+			ratios:         ratios,
+		}
+	}
+
+	t.Run("no playback", func(t *testing.T) {
+		m := newMonitor(false)
+		events := m.handle(append(append(windows(0, 0.1, 0.9), start), windows(2, 0.9, 0.9)...))
+		events = append(events, m.handle([]stream.Event{end})...)
+		require.Equal(t, []Event{
+			{Type: EventSpeechStart, Start: window},
+			{Type: EventSpeechEnd, Start: window, End: 10 * window},
+		}, events)
+	})
+
+	t.Run("echo below playback threshold", func(t *testing.T) {
+		m := newMonitor(true)
+		events := m.handle(append(append(windows(0, 0.1, 0.6), start), windows(2, 0.7, 0.6, 0.7)...))
+		events = append(events, m.handle([]stream.Event{end})...)
+		require.Empty(t, events)
+	})
+
+	t.Run("echo below reference", func(t *testing.T) {
+		m := newMonitor(true, -20, -20, -20, -20, -20)
+		events := m.handle(append(append(windows(0, 0.1, 0.9), start), windows(2, 0.9, 0.9, 0.9)...))
+		require.Empty(t, events)
+		require.Empty(t, m.ratios)
+	})
+
+	t.Run("barge-in", func(t *testing.T) {
+		m := newMonitor(true)
+		events := m.handle(append(append(windows(0, 0.1, 0.9), start), windows(2, 0.5, 0.9, 0.95, 0.9)...))
+		events = append(events, m.handle([]stream.Event{end})...)
+		require.Equal(t, []Event{
+			{Type: EventSpeechStart, Start: window},
+			{Type: EventBargeIn, Start: 3 * window, Time: 5 * window},
+			{Type: EventSpeechEnd, Start: window, End: 10 * window},
+		}, events)
+
+		// A single barge-in per playback.
+		require.Empty(t, m.handle(windows(6, 0.9, 0.9, 0.9)))
+		require.Empty(t, m.SetPlayback(false))
+		require.Empty(t, m.SetPlayback(true))
+		events = m.handle(windows(9, 0.9, 0.9))
+		require.Len(t, events, 1)
+		require.Equal(t, EventBargeIn, events[0].Type)
+		require.InDelta(t, 9*window, events[0].Start, 1e-9)
+		require.InDelta(t, 11*window, events[0].Time, 1e-9)
+	})
+
+	t.Run("playback stops", func(t *testing.T) {
+		m := newMonitor(true)
+		m.cfg.Stream.Probabilities = true
+		events := m.handle(append(windows(0, 0.6), start))
+		require.Equal(t, []Event{{Type: EventProbability, Time: window, Probability: 0.6}}, events)
+		require.Equal(t, []Event{{Type: EventSpeechStart, Start: window}}, m.SetPlayback(false))
+		require.Equal(t, []Event{{Type: EventSpeechEnd, Start: window, End: 10 * window}}, m.handle([]stream.Event{end}))
+	})
+}
+
+func TestMonitor(t *testing.T) {
+	sd, err := speech.NewDetector(speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+
+	m, err := NewMonitor(sd, Config{})
+	require.NoError(t, err)
+	require.Equal(t, 16000, m.Config().Stream.SampleRate)
+
+	run := func(t *testing.T, input, reference []float32) []Event {
+		t.Helper()
+		require.NoError(t, m.Reset())
+		require.Empty(t, m.SetPlayback(true))
+		require.True(t, m.Playback())
+
+		var events []Event
+		for i := 0; i < len(input); i += 1600 {
+			j := min(i+1600, len(input))
+			if reference != nil {
+				m.WriteReference(reference[i:j])
+			}
+			evs, err := m.WriteSamples(context.Background(), input[i:j])
+			require.NoError(t, err)
+			events = append(events, evs...)
+		}
+		evs, err := m.Flush(context.Background())
+		require.NoError(t, err)
+		return append(events, evs...)
+	}
+
+	t.Run("barge-in", func(t *testing.T) {
+		events := run(t, samples, nil)
+		require.GreaterOrEqual(t, len(events), 3)
+		require.Equal(t, EventSpeechStart, events[0].Type)
+		require.Equal(t, EventBargeIn, events[1].Type)
+		require.Equal(t, EventSpeechEnd, events[2].Type)
+		require.GreaterOrEqual(t, events[1].Start, events[0].Start)
+	})
+
+	t.Run("echo", func(t *testing.T) {
+		// The input being the reference attenuated by 26dB.
+		echo := make([]float32, len(samples))
+		for i, s := range samples {
+			echo[i] = s * 0.05
+		}
+		for _, ev := range run(t, echo, samples) {
+			require.NotEqual(t, EventBargeIn, ev.Type)
+		}
+	})
+}
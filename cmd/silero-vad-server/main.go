@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/vadgrpc"
+	"github.com/streamer45/silero-vad-go/vadpb"
+)
+
+type config struct {
+	Addr            string
+	GRPCAddr        string
+	Pool            speech.PoolConfig
+	Server          serverConfig
+	ShutdownTimeout time.Duration
+}
+
+func parseConfig(args []string, output io.Writer) (config, error) {
+	fs := flag.NewFlagSet("silero-vad-server", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	cfg := config{
+		Pool: speech.PoolConfig{
+			DetectorConfig: speech.DetectorConfig{
+				Threshold: 0.5,
+				LogLevel:  speech.LogLevelWarn,
+			},
+		},
+	}
+	det := &cfg.Pool.DetectorConfig
+
+	fs.StringVar(&cfg.Addr, "addr", ":8080", "address to listen on")
+	fs.StringVar(&cfg.GRPCAddr, "grpc-addr", "", "address to serve the gRPC service on (disabled if empty)")
+	fs.StringVar(&det.ModelPath, "model", os.Getenv("SILERO_VAD_MODEL"),
+		"path to the Silero VAD ONNX model (defaults to $SILERO_VAD_MODEL)")
+	fs.IntVar(&det.SampleRate, "sample-rate", 16000,
+		"model sample rate, 8000 or 16000 (input audio is resampled to it)")
+	fs.Func("threshold", "speech probability threshold (default 0.5)", func(s string) error {
+		v, err := parseFloat32(s)
+		det.Threshold = v
+		return err
+	})
+	fs.IntVar(&det.MinSilenceDurationMs, "min-silence-duration-ms", 100,
+		"duration of silence to wait for before ending a speech segment")
+	fs.IntVar(&det.SpeechPadMs, "speech-pad-ms", 30, "padding added to each side of speech segments")
+	fs.Func("log-level", "ONNX Runtime log level: verbose, info, warn, error or fatal (default warn)", func(s string) error {
+		v, err := speech.ParseLogLevel(s)
+		det.LogLevel = v
+		return err
+	})
+	fs.Func("model-version", "model version: auto, v4, v5 or v6 (default auto)", func(s string) error {
+		v, err := speech.ParseModelVersion(s)
+		det.ModelVersion = v
+		return err
+	})
+	fs.BoolVar(&det.AdaptiveThreshold.Enabled, "adaptive-threshold", false,
+		"adapt the threshold to the background noise, starting from -threshold")
+	fs.Func("adaptive-min-threshold", "lower bound of the adaptive threshold (default 0.3)", func(s string) error {
+		v, err := parseFloat32(s)
+		det.AdaptiveThreshold.MinThreshold = v
+		return err
+	})
+	fs.Func("adaptive-max-threshold", "upper bound of the adaptive threshold (default 0.8)", func(s string) error {
+		v, err := parseFloat32(s)
+		det.AdaptiveThreshold.MaxThreshold = v
+		return err
+	})
+	fs.Func("adaptive-margin", "margin of the adaptive threshold above noise probabilities (default 0.25)", func(s string) error {
+		v, err := parseFloat32(s)
+		det.AdaptiveThreshold.Margin = v
+		return err
+	})
+	fs.IntVar(&det.AdaptiveThreshold.AdaptationMs, "adaptive-adaptation-ms", 0,
+		"time constant of the noise estimates (default 5000)")
+	fs.Float64Var(&det.AdaptiveThreshold.EnergyMarginDb, "adaptive-energy-margin-db", 0,
+		"level above the noise floor beyond which windows aren't considered noise (default 6)")
+	fs.BoolVar(&det.EnergyGate.Enabled, "energy-gate", false, "skip inference on windows of near silence")
+	fs.Float64Var(&det.EnergyGate.RMSFloorDb, "energy-gate-rms-floor-db", 0,
+		"RMS level in dBFS below which windows can be skipped (default -60)")
+	fs.Float64Var(&det.EnergyGate.PeakFloorDb, "energy-gate-peak-floor-db", 0,
+		"peak level in dBFS below which windows can be skipped (default -50)")
+	fs.IntVar(&det.EnergyGate.HangoverMs, "energy-gate-hangover-ms", 0,
+		"duration of near silence still inferred before skipping (default 100)")
+	fs.IntVar(&cfg.Pool.MaxSize, "pool-size", runtime.NumCPU(), "maximum number of detectors running at once")
+	fs.DurationVar(&cfg.Pool.IdleTimeout, "idle-timeout", 5*time.Minute,
+		"duration after which idle detectors are destroyed (0 disables eviction)")
+	fs.Int64Var(&cfg.Server.MaxBodySize, "max-body-size", 32<<20,
+		"maximum size in bytes of request bodies and streamed messages")
+	fs.DurationVar(&cfg.Server.RequestTimeout, "request-timeout", 30*time.Second,
+		"maximum duration of a request, including waiting for a detector")
+	fs.DurationVar(&cfg.Server.PingInterval, "ping-interval", 20*time.Second,
+		"interval at which streaming clients get pinged")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", 30*time.Second,
+		"maximum duration to wait for in-flight requests on shutdown")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+	if fs.NArg() > 0 {
+		return config{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if err := cfg.Pool.IsValid(); err != nil {
+		return config{}, err
+	}
+	if err := cfg.Server.IsValid(); err != nil {
+		return config{}, err
+	}
+	if cfg.ShutdownTimeout < 0 {
+		return config{}, fmt.Errorf("invalid ShutdownTimeout: should be a positive duration")
+	}
+
+	return cfg, nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	cfg, err := parseConfig(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "silero-vad-server: %s\n", err)
+		return 2
+	}
+
+	log := slog.New(slog.NewTextHandler(stderr, nil))
+
+	if err := serve(ctx, cfg, log); err != nil {
+		log.Error("server failed", slog.String("err", err.Error()))
+		return 1
+	}
+
+	return 0
+}
+
+// serve runs the server until ctx is done, then waits for in-flight requests
+// to complete and destroys the detectors.
+func serve(ctx context.Context, cfg config, log *slog.Logger) (err error) {
+	pool, err := speech.NewPool(cfg.Pool)
+	if err != nil {
+		return fmt.Errorf("failed to create pool: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, pool.Close())
+	}()
+
+	// Creating a first detector upfront ensures the model loads fine before
+	// accepting any traffic.
+	sd, err := pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if err := pool.Put(sd); err != nil {
+		return err
+	}
+
+	srv := newServer(cfg.Server, pool, cfg.Pool.DetectorConfig.SampleRate, log)
+	httpSrv := &http.Server{
+		Handler:           srv.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       cfg.Server.RequestTimeout,
+		// Leaving some room for the response to be written once the request
+		// times out.
+		WriteTimeout: cfg.Server.RequestTimeout + 5*time.Second,
+		IdleTimeout:  2 * time.Minute,
+		ErrorLog:     slog.NewLogLogger(log.Handler(), slog.LevelWarn),
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- httpSrv.Serve(ln)
+	}()
+	log.Info("listening", slog.String("addr", ln.Addr().String()))
+
+	var grpcSrv *grpc.Server
+	healthSrv := health.NewServer()
+	if cfg.GRPCAddr != "" {
+		vadSrv, err := vadgrpc.NewServer(pool, vadgrpc.Config{AcquireTimeout: cfg.Server.RequestTimeout})
+		if err != nil {
+			return err
+		}
+		grpcSrv = grpc.NewServer(grpc.MaxRecvMsgSize(int(cfg.Server.MaxBodySize)))
+		vadpb.RegisterVADServer(grpcSrv, vadSrv)
+		healthpb.RegisterHealthServer(grpcSrv, healthSrv)
+
+		grpcLn, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			return errors.Join(fmt.Errorf("failed to listen: %w", err), httpSrv.Close())
+		}
+		go func() {
+			serveErr <- grpcSrv.Serve(grpcLn)
+		}()
+		log.Info("listening for gRPC", slog.String("addr", grpcLn.Addr().String()))
+	}
+
+	srv.setReady(true)
+
+	select {
+	case err := <-serveErr:
+		return errors.Join(err, httpSrv.Close())
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down")
+	srv.setReady(false)
+	healthSrv.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	streamsErr := make(chan error, 1)
+	go func() {
+		streamsErr <- srv.closeStreams(shutdownCtx)
+	}()
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcSrv != nil {
+			stopGRPC(shutdownCtx, grpcSrv)
+		}
+	}()
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		err = fmt.Errorf("failed to shut down: %w", err)
+		<-grpcStopped
+		return errors.Join(err, <-streamsErr)
+	}
+	<-grpcStopped
+
+	return <-streamsErr
+}
+
+// stopGRPC gracefully stops srv, waiting for pending calls to complete until
+// ctx is done, at which point they get canceled.
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	stop := context.AfterFunc(ctx, srv.Stop)
+	defer stop()
+	srv.GracefulStop()
+}
+
+func parseFloat32(s string) (float32, error) {
+	v, err := strconv.ParseFloat(s, 32)
+	return float32(v), err
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+type serverConfig struct {
+	// The maximum size in bytes of request bodies and streamed messages.
+	MaxBodySize int64
+	// The maximum duration of a detection request, including the time spent
+	// waiting for a detector. It also bounds the time to wait for a detector
+	// when streaming.
+	RequestTimeout time.Duration
+	// The interval at which streaming clients get pinged. Clients not replying
+	// within twice the interval are disconnected.
+	PingInterval time.Duration
+}
+
+func (c serverConfig) IsValid() error {
+	if c.MaxBodySize <= 0 {
+		return fmt.Errorf("invalid MaxBodySize: should be a positive number")
+	}
+
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("invalid RequestTimeout: should be a positive duration")
+	}
+
+	if c.PingInterval <= 0 {
+		return fmt.Errorf("invalid PingInterval: should be a positive duration")
+	}
+
+	return nil
+}
+
+// server serves speech detection over HTTP using a pool of detectors.
+type server struct {
+	cfg        serverConfig
+	pool       *speech.Pool
+	sampleRate int
+	log        *slog.Logger
+	ready      atomic.Bool
+
+	// Streams are hijacked connections which the HTTP server doesn't track
+	// so we close them ourselves on shutdown.
+	streamsCtx    context.Context
+	cancelStreams context.CancelFunc
+	streams       sync.WaitGroup
+}
+
+func newServer(cfg serverConfig, pool *speech.Pool, sampleRate int, log *slog.Logger) *server {
+	streamsCtx, cancelStreams := context.WithCancel(context.Background())
+	return &server{
+		cfg:           cfg,
+		pool:          pool,
+		sampleRate:    sampleRate,
+		log:           log,
+		streamsCtx:    streamsCtx,
+		cancelStreams: cancelStreams,
+	}
+}
+
+// closeStreams closes all the streaming connections and waits for their
+// handlers to return, or ctx to be done.
+func (s *server) closeStreams(ctx context.Context) error {
+	s.cancelStreams()
+
+	done := make(chan struct{})
+	go func() {
+		s.streams.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to close streams: %w", ctx.Err())
+	}
+}
+
+// setReady sets whether the server should be receiving traffic, as reported
+// by the readiness endpoint.
+func (s *server) setReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/detect", s.handleDetect)
+	mux.HandleFunc("/v1/stream", s.handleStream)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	return mux
+}
+
+type segmentResponse struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type detectResponse struct {
+	Duration float64           `json:"duration"`
+	Segments []segmentResponse `json:"segments"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// httpError is an error carrying the status code to reply with.
+type httpError struct {
+	code int
+	err  error
+}
+
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e httpError) Unwrap() error {
+	return e.err
+}
+
+func (s *server) writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Debug("failed to write response", slog.String("err", err.Error()))
+	}
+}
+
+func (s *server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusInternalServerError
+	var httpErr httpError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &httpErr):
+		code = httpErr.code
+	case errors.As(err, &maxBytesErr):
+		code = http.StatusRequestEntityTooLarge
+		err = fmt.Errorf("request body exceeds %d bytes", maxBytesErr.Limit)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, speech.ErrPoolClosed):
+		code = http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled):
+		// The client went away, there's nobody to reply to.
+		return
+	}
+
+	if code >= http.StatusInternalServerError {
+		s.log.Error("request failed", slog.String("path", r.URL.Path), slog.String("err", err.Error()))
+	}
+
+	s.writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) handleReady(w http.ResponseWriter, _ *http.Request) {
+	if !s.ready.Load() {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) handleDetect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		s.writeError(w, r, httpError{http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := s.detect(ctx, r)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, res)
+}
+
+func (s *server) detect(ctx context.Context, r *http.Request) (detectResponse, error) {
+	samples, sampleRate, err := s.readAudio(r)
+	if err != nil {
+		return detectResponse{}, err
+	}
+	duration := float64(len(samples)) / float64(sampleRate)
+	samples = audio.Resample(samples, sampleRate, s.sampleRate)
+
+	sd, err := s.pool.Get(ctx)
+	if err != nil {
+		return detectResponse{}, fmt.Errorf("failed to get detector: %w", err)
+	}
+	defer func() {
+		if err := s.pool.Put(sd); err != nil {
+			s.log.Error("failed to return detector", slog.String("err", err.Error()))
+		}
+	}()
+
+	res := detectResponse{Duration: duration, Segments: []segmentResponse{}}
+	if len(samples) < sd.WindowSize() {
+		return res, nil
+	}
+
+	segments, err := sd.DetectContext(ctx, samples)
+	if err != nil {
+		return detectResponse{}, fmt.Errorf("failed to detect speech: %w", err)
+	}
+
+	for _, seg := range segments {
+		// A segment still open at the end of the input ends with it.
+		if seg.SpeechEndAt == 0 {
+			seg.SpeechEndAt = duration
+		}
+		res.Segments = append(res.Segments, segmentResponse{Start: seg.SpeechStartAt, End: seg.SpeechEndAt})
+	}
+
+	return res, nil
+}
+
+// readAudio decodes the request body according to the format and rate query
+// parameters. The format defaults to auto, which detects WAV from the content
+// type or header and falls back to f32le. The rate of raw PCM defaults to the
+// model sample rate.
+func (s *server) readAudio(r *http.Request) ([]float32, int, error) {
+	query := r.URL.Query()
+	br := bufio.NewReader(http.MaxBytesReader(nil, r.Body, s.cfg.MaxBodySize))
+
+	format := query.Get("format")
+	if format == "" || format == "auto" {
+		format = "f32le"
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		magic, _ := br.Peek(4)
+		if string(magic) == "RIFF" || mediaType == "audio/wav" || mediaType == "audio/x-wav" || mediaType == "audio/wave" {
+			format = "wav"
+		}
+	}
+
+	if format == "wav" {
+		samples, wavFormat, err := audio.ReadWAV(br)
+		if err != nil {
+			return nil, 0, badRequest(err)
+		}
+		return samples, wavFormat.SampleRate, nil
+	}
+
+	enc, err := audio.ParseEncoding(format)
+	if err != nil {
+		return nil, 0, httpError{http.StatusBadRequest, fmt.Errorf("invalid format %q", format)}
+	}
+
+	rate := s.sampleRate
+	if v := query.Get("rate"); v != "" {
+		rate, err = strconv.Atoi(v)
+		if err != nil || rate <= 0 {
+			return nil, 0, httpError{http.StatusBadRequest, fmt.Errorf("invalid rate %q", v)}
+		}
+	}
+
+	samples, err := audio.ReadPCM(br, enc)
+	if err != nil {
+		return nil, 0, badRequest(err)
+	}
+
+	return samples, rate, nil
+}
+
+// badRequest turns decoding errors into bad requests, unless caused by the
+// body size limit.
+func badRequest(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+	return httpError{http.StatusBadRequest, err}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+const (
+	testModelPath   = "../../testfiles/silero_vad.onnx"
+	testSamplesPath = "../../testfiles/samples.pcm"
+)
+
+func newTestServer(t *testing.T, cfg serverConfig) *server {
+	t.Helper()
+
+	pool, err := speech.NewPool(speech.PoolConfig{
+		DetectorConfig: speech.DetectorConfig{
+			ModelPath:  testModelPath,
+			SampleRate: 16000,
+			Threshold:  0.5,
+		},
+		MaxSize: 2,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, pool.Close())
+	})
+
+	return newServer(cfg, pool, 16000, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func doRequest(t *testing.T, s *server, method, target string, body []byte, header http.Header) (int, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+
+	return rec.Code, res
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := parseConfig([]string{"-model", "model.onnx"}, io.Discard)
+		require.NoError(t, err)
+		require.Equal(t, ":8080", cfg.Addr)
+		require.Equal(t, speech.DetectorConfig{
+			ModelPath:            "model.onnx",
+			SampleRate:           16000,
+			Threshold:            0.5,
+			MinSilenceDurationMs: 100,
+			SpeechPadMs:          30,
+			LogLevel:             speech.LogLevelWarn,
+		}, cfg.Pool.DetectorConfig)
+		require.Equal(t, int64(32<<20), cfg.Server.MaxBodySize)
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		cfg, err := parseConfig([]string{
+			"-model", "model.onnx",
+			"-addr", "127.0.0.1:9000",
+			"-threshold", "0.3",
+			"-log-level", "error",
+			"-model-version", "v4",
+			"-adaptive-threshold",
+			"-adaptive-min-threshold", "0.2",
+			"-adaptive-max-threshold", "0.9",
+			"-adaptive-margin", "0.1",
+			"-adaptive-adaptation-ms", "1000",
+			"-adaptive-energy-margin-db", "3",
+			"-energy-gate",
+			"-energy-gate-rms-floor-db", "-70",
+			"-energy-gate-peak-floor-db", "-55",
+			"-energy-gate-hangover-ms", "200",
+			"-pool-size", "3",
+			"-max-body-size", "1024",
+			"-request-timeout", "5s",
+		}, io.Discard)
+		require.NoError(t, err)
+		require.Equal(t, "127.0.0.1:9000", cfg.Addr)
+		require.Equal(t, float32(0.3), cfg.Pool.DetectorConfig.Threshold)
+		require.Equal(t, speech.LogLevelError, cfg.Pool.DetectorConfig.LogLevel)
+		require.Equal(t, speech.ModelVersionV4, cfg.Pool.DetectorConfig.ModelVersion)
+		require.Equal(t, speech.AdaptiveThresholdConfig{
+			Enabled:        true,
+			MinThreshold:   0.2,
+			MaxThreshold:   0.9,
+			Margin:         0.1,
+			AdaptationMs:   1000,
+			EnergyMarginDb: 3,
+		}, cfg.Pool.DetectorConfig.AdaptiveThreshold)
+		require.Equal(t, speech.EnergyGateConfig{
+			Enabled:     true,
+			RMSFloorDb:  -70,
+			PeakFloorDb: -55,
+			HangoverMs:  200,
+		}, cfg.Pool.DetectorConfig.EnergyGate)
+		require.Equal(t, 3, cfg.Pool.MaxSize)
+		require.Equal(t, serverConfig{
+			MaxBodySize:    1024,
+			RequestTimeout: 5 * time.Second,
+			PingInterval:   20 * time.Second,
+		}, cfg.Server)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseConfig([]string{"-model", ""}, io.Discard)
+		require.EqualError(t, err, "invalid DetectorConfig: invalid ModelPath: should not be empty")
+
+		_, err = parseConfig([]string{"-model", "model.onnx", "-pool-size", "0"}, io.Discard)
+		require.EqualError(t, err, "invalid MaxSize: should be a positive number")
+
+		_, err = parseConfig([]string{"-model", "model.onnx", "-max-body-size", "0"}, io.Discard)
+		require.EqualError(t, err, "invalid MaxBodySize: should be a positive number")
+
+		_, err = parseConfig([]string{"-model", "model.onnx", "extra"}, io.Discard)
+		require.EqualError(t, err, "unexpected arguments: [extra]")
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		var stderr bytes.Buffer
+		require.Equal(t, 2, run(context.Background(), []string{"-model", ""}, &stderr))
+		require.Equal(t, "silero-vad-server: invalid DetectorConfig: invalid ModelPath: should not be empty\n", stderr.String())
+	})
+}
+
+func TestHealth(t *testing.T) {
+	s := newTestServer(t, serverConfig{MaxBodySize: 1024, RequestTimeout: time.Second, PingInterval: time.Second})
+
+	code, res := doRequest(t, s, http.MethodGet, "/healthz", nil, nil)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", res["status"])
+
+	code, _ = doRequest(t, s, http.MethodGet, "/readyz", nil, nil)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	s.setReady(true)
+	code, _ = doRequest(t, s, http.MethodGet, "/readyz", nil, nil)
+	require.Equal(t, http.StatusOK, code)
+
+	s.setReady(false)
+	code, _ = doRequest(t, s, http.MethodGet, "/readyz", nil, nil)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestDetectErrors(t *testing.T) {
+	s := newTestServer(t, serverConfig{MaxBodySize: 1024, RequestTimeout: time.Second, PingInterval: time.Second})
+
+	tcs := []struct {
+		name   string
+		method string
+		target string
+		body   []byte
+		code   int
+		err    string
+	}{
+		{
+			name:   "method not allowed",
+			method: http.MethodGet,
+			target: "/v1/detect",
+			code:   http.StatusMethodNotAllowed,
+			err:    "method GET not allowed",
+		},
+		{
+			name:   "invalid format",
+			method: http.MethodPost,
+			target: "/v1/detect?format=mp3",
+			code:   http.StatusBadRequest,
+			err:    `invalid format "mp3"`,
+		},
+		{
+			name:   "invalid rate",
+			method: http.MethodPost,
+			target: "/v1/detect?format=s16le&rate=-1",
+			code:   http.StatusBadRequest,
+			err:    `invalid rate "-1"`,
+		},
+		{
+			name:   "invalid wav",
+			method: http.MethodPost,
+			target: "/v1/detect?format=wav",
+			body:   []byte("RIFF....WAVX"),
+			code:   http.StatusBadRequest,
+			err:    "invalid WAV header",
+		},
+		{
+			name:   "too large",
+			method: http.MethodPost,
+			target: "/v1/detect?format=s16le",
+			body:   make([]byte, 2048),
+			code:   http.StatusRequestEntityTooLarge,
+			err:    "request body exceeds 1024 bytes",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			code, res := doRequest(t, s, tc.method, tc.target, tc.body, nil)
+			require.Equal(t, tc.code, code)
+			require.Equal(t, tc.err, res["error"])
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	pcm, err := os.ReadFile(testSamplesPath)
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+
+	s := newTestServer(t, serverConfig{MaxBodySize: 32 << 20, RequestTimeout: 10 * time.Second, PingInterval: time.Second})
+
+	decode := func(t *testing.T, method, target string, body []byte, header http.Header) detectResponse {
+		t.Helper()
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var res detectResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res
+	}
+
+	t.Run("f32le", func(t *testing.T) {
+		res := decode(t, http.MethodPost, "/v1/detect", pcm, nil)
+		require.InDelta(t, float64(len(samples))/16000, res.Duration, 1e-9)
+		require.Len(t, res.Segments, 3)
+		require.Equal(t, res.Duration, res.Segments[2].End)
+	})
+
+	t.Run("s16le at 8kHz", func(t *testing.T) {
+		body := audio.EncodeSamples(nil, audio.Resample(samples, 16000, 8000), audio.EncodingS16LE)
+		res := decode(t, http.MethodPost, "/v1/detect?format=s16le&rate=8000", body, nil)
+		require.Len(t, res.Segments, 3)
+	})
+
+	t.Run("wav", func(t *testing.T) {
+		var wav bytes.Buffer
+		require.NoError(t, audio.WriteWAV(&wav, samples, 16000))
+		res := decode(t, http.MethodPost, "/v1/detect", wav.Bytes(), http.Header{"Content-Type": {"audio/wav"}})
+		require.Len(t, res.Segments, 3)
+	})
+
+	t.Run("short input", func(t *testing.T) {
+		res := decode(t, http.MethodPost, "/v1/detect", pcm[:100], nil)
+		require.Empty(t, res.Segments)
+	})
+
+	t.Run("closed pool", func(t *testing.T) {
+		require.NoError(t, s.pool.Close())
+		code, res := doRequest(t, s, http.MethodPost, "/v1/detect", pcm, nil)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Contains(t, res["error"], speech.ErrPoolClosed.Error())
+	})
+}
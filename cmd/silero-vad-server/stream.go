@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+// Messages exchanged over the streaming endpoint are JSON objects with a type
+// field. Clients first send a config message, then binary messages of PCM
+// audio and finally an end message. The server replies to the config message
+// with its own, holding the effective settings, then sends start, end and,
+// if requested, probability events as audio gets processed.
+const (
+	msgTypeConfig      = "config"
+	msgTypeStart       = "start"
+	msgTypeEnd         = "end"
+	msgTypeProbability = "probability"
+	msgTypeError       = "error"
+)
+
+// streamConfig is the config message negotiating the stream settings.
+type streamConfig struct {
+	Type string `json:"type"`
+	// The sample rate of the PCM audio sent by the client. Defaults to the
+	// model sample rate.
+	SampleRate int `json:"sample_rate,omitempty"`
+	// The encoding of the PCM audio sent by the client, f32le (default) or s16le.
+	Format string `json:"format,omitempty"`
+	// The speech probability threshold. Defaults to the server one.
+	Threshold float32 `json:"threshold,omitempty"`
+	// Whether to send the speech probability of each window.
+	Probabilities bool `json:"probabilities,omitempty"`
+	// The duration of each window in seconds, set by the server.
+	WindowDuration float64 `json:"window_duration,omitempty"`
+}
+
+func (c streamConfig) IsValid() error {
+	if c.Type != msgTypeConfig {
+		return fmt.Errorf("invalid type: expected %q, got %q", msgTypeConfig, c.Type)
+	}
+
+	if c.SampleRate < 0 {
+		return fmt.Errorf("invalid sample_rate: should be a positive number")
+	}
+
+	if c.Format != "" {
+		if _, err := audio.ParseEncoding(c.Format); err != nil {
+			return fmt.Errorf("invalid format: %w", err)
+		}
+	}
+
+	if c.Threshold < 0 || c.Threshold >= 1 {
+		return fmt.Errorf("invalid threshold: should be in range (0, 1)")
+	}
+
+	return nil
+}
+
+// streamEvent is a message sent by the server. Times are in seconds from the
+// start of the stream.
+type streamEvent struct {
+	Type        string   `json:"type"`
+	Start       *float64 `json:"start,omitempty"`
+	End         *float64 `json:"end,omitempty"`
+	Time        *float64 `json:"time,omitempty"`
+	Probability *float32 `json:"probability,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// streamMessage is a text message sent by the client once configured.
+type streamMessage struct {
+	Type string `json:"type"`
+}
+
+var upgrader = websocket.Upgrader{
+	// Browser clients are expected to be served from other origins.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+func (s *server) handleStream(w http.ResponseWriter, r *http.Request) {
+	s.streams.Add(1)
+	defer s.streams.Done()
+
+	if s.streamsCtx.Err() != nil {
+		s.writeError(w, r, speech.ErrPoolClosed)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader already replied with an error.
+		return
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(s.streamsCtx, func() {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+			time.Now().Add(time.Second))
+		conn.Close()
+	})
+	defer stop()
+
+	st := &streamConn{
+		srv:  s,
+		conn: conn,
+		done: make(chan struct{}),
+	}
+	defer close(st.done)
+
+	if err := st.run(r.Context()); err != nil {
+		code := websocket.CloseInternalServerErr
+		var closeErr *websocket.CloseError
+		var netErr net.Error
+		var httpErr httpError
+		switch {
+		case errors.As(err, &closeErr), errors.Is(err, context.Canceled), s.streamsCtx.Err() != nil:
+			// The client went away or we are shutting down.
+			return
+		case errors.As(err, &netErr) && netErr.Timeout():
+			s.log.Debug("stream timed out", slog.String("err", err.Error()))
+			return
+		case errors.As(err, &httpErr) && httpErr.code == http.StatusBadRequest:
+			code = websocket.ClosePolicyViolation
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, speech.ErrPoolClosed):
+			code = websocket.CloseTryAgainLater
+		default:
+			s.log.Error("stream failed", slog.String("err", err.Error()))
+		}
+		_ = st.writeJSON(streamEvent{Type: msgTypeError, Error: err.Error()})
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""),
+			time.Now().Add(time.Second))
+		return
+	}
+
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+}
+
+// streamConn is a streaming session over a single connection.
+type streamConn struct {
+	srv  *server
+	conn *websocket.Conn
+	done chan struct{}
+
+	cfg     streamConfig
+	sd      *speech.Detector
+	session *stream.Session
+	// The detector threshold to restore before returning it to the pool.
+	threshold float32
+}
+
+func (st *streamConn) writeJSON(v any) error {
+	if err := st.conn.SetWriteDeadline(time.Now().Add(st.srv.cfg.RequestTimeout)); err != nil {
+		return err
+	}
+	return st.conn.WriteJSON(v)
+}
+
+func (st *streamConn) keepAlive() {
+	ticker := time.NewTicker(st.srv.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := st.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(st.srv.cfg.PingInterval)); err != nil {
+				return
+			}
+		case <-st.done:
+			return
+		}
+	}
+}
+
+func (st *streamConn) run(ctx context.Context) error {
+	conn := st.conn
+	conn.SetReadLimit(st.srv.cfg.MaxBodySize)
+
+	// Clients failing to answer pings in time are considered gone.
+	pongWait := 2 * st.srv.cfg.PingInterval
+	if err := conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
+		return err
+	}
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	go st.keepAlive()
+
+	// Releasing first covers configure failing after acquiring the detector.
+	defer st.release()
+	if err := st.configure(ctx); err != nil {
+		return err
+	}
+
+	// Clients closing the connection normally end the stream as with an end
+	// message, so the close reply waits for the flushed events.
+	closeHandler := conn.CloseHandler()
+	conn.SetCloseHandler(func(code int, text string) error {
+		if code == websocket.CloseNormalClosure {
+			return nil
+		}
+		return closeHandler(code, text)
+	})
+
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return st.end(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		if msgType == websocket.BinaryMessage {
+			events, err := st.session.Write(ctx, data)
+			if err != nil {
+				return err
+			}
+			if err := st.writeEvents(events); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var msg streamMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return httpError{http.StatusBadRequest, fmt.Errorf("invalid message: %w", err)}
+		}
+		if msg.Type != msgTypeEnd {
+			return httpError{http.StatusBadRequest, fmt.Errorf("unexpected message type %q", msg.Type)}
+		}
+
+		return st.end(ctx)
+	}
+}
+
+// end flushes the session and sends the remaining events.
+func (st *streamConn) end(ctx context.Context) error {
+	events, err := st.session.Flush(ctx)
+	if err != nil {
+		return err
+	}
+	return st.writeEvents(events)
+}
+
+// configure reads the config message, acquires a detector and replies with
+// the effective settings.
+func (st *streamConn) configure(ctx context.Context) error {
+	msgType, data, err := st.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if msgType != websocket.TextMessage {
+		return httpError{http.StatusBadRequest, fmt.Errorf("expected config message")}
+	}
+
+	if err := json.Unmarshal(data, &st.cfg); err != nil {
+		return httpError{http.StatusBadRequest, fmt.Errorf("invalid config message: %w", err)}
+	}
+	if err := st.cfg.IsValid(); err != nil {
+		return httpError{http.StatusBadRequest, fmt.Errorf("invalid config message: %w", err)}
+	}
+
+	sessionCfg := stream.Config{
+		SampleRate:    st.cfg.SampleRate,
+		Probabilities: st.cfg.Probabilities,
+	}
+	if st.cfg.Format != "" {
+		sessionCfg.Encoding, _ = audio.ParseEncoding(st.cfg.Format)
+	}
+
+	getCtx, cancel := context.WithTimeout(ctx, st.srv.cfg.RequestTimeout)
+	defer cancel()
+	sd, err := st.srv.pool.Get(getCtx)
+	if err != nil {
+		return fmt.Errorf("failed to get detector: %w", err)
+	}
+	st.sd = sd
+	st.threshold = sd.Config().Threshold
+
+	if st.cfg.Threshold == 0 {
+		st.cfg.Threshold = st.threshold
+	}
+	sd.SetThreshold(st.cfg.Threshold)
+
+	if st.session, err = stream.NewSession(sd, sessionCfg); err != nil {
+		return err
+	}
+	st.cfg.SampleRate = st.session.Config().SampleRate
+	st.cfg.Format = st.session.Config().Encoding.String()
+	st.cfg.WindowDuration = st.session.WindowDuration()
+
+	return st.writeJSON(st.cfg)
+}
+
+// release returns the detector to the pool, if acquired, restoring its
+// threshold first.
+func (st *streamConn) release() {
+	if st.sd == nil {
+		return
+	}
+
+	st.sd.SetThreshold(st.threshold)
+	if err := st.srv.pool.Put(st.sd); err != nil {
+		st.srv.log.Error("failed to return detector", slog.String("err", err.Error()))
+	}
+}
+
+func (st *streamConn) writeEvents(events []stream.Event) error {
+	for _, ev := range events {
+		msg := streamEvent{Type: ev.Type.String()}
+		switch ev.Type {
+		case stream.EventSpeechStart:
+			msg.Start = &ev.Start
+		case stream.EventSpeechEnd:
+			msg.Start = &ev.Start
+			msg.End = &ev.End
+		case stream.EventProbability:
+			msg.Time = &ev.Time
+			msg.Probability = &ev.Probability
+		}
+		if err := st.writeJSON(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
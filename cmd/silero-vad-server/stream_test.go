@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func dialStream(t *testing.T, s *server) *websocket.Conn {
+	t.Helper()
+
+	httpSrv := httptest.NewServer(s.handler())
+	t.Cleanup(httpSrv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/v1/stream", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return conn
+}
+
+// readEvents reads events until the server closes the connection, returning
+// them along with the close code.
+func readEvents(t *testing.T, conn *websocket.Conn) ([]streamEvent, int) {
+	t.Helper()
+
+	var events []streamEvent
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			require.ErrorAs(t, err, &closeErr)
+			return events, closeErr.Code
+		}
+		var ev streamEvent
+		require.NoError(t, json.Unmarshal(data, &ev))
+		events = append(events, ev)
+	}
+}
+
+func TestStreamConfigIsValid(t *testing.T) {
+	tcs := []struct {
+		name string
+		cfg  streamConfig
+		err  string
+	}{
+		{
+			name: "defaults",
+			cfg:  streamConfig{Type: "config"},
+		},
+		{
+			name: "valid",
+			cfg:  streamConfig{Type: "config", SampleRate: 48000, Format: "s16le", Threshold: 0.6, Probabilities: true},
+		},
+		{
+			name: "invalid type",
+			cfg:  streamConfig{Type: "start"},
+			err:  `invalid type: expected "config", got "start"`,
+		},
+		{
+			name: "invalid sample rate",
+			cfg:  streamConfig{Type: "config", SampleRate: -1},
+			err:  "invalid sample_rate: should be a positive number",
+		},
+		{
+			name: "invalid format",
+			cfg:  streamConfig{Type: "config", Format: "mp3"},
+			err:  `invalid format: invalid encoding "mp3"`,
+		},
+		{
+			name: "invalid threshold",
+			cfg:  streamConfig{Type: "config", Threshold: 1},
+			err:  "invalid threshold: should be in range (0, 1)",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.IsValid()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	s := newTestServer(t, serverConfig{MaxBodySize: 1024, RequestTimeout: time.Second, PingInterval: time.Second})
+
+	t.Run("invalid config", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config", Format: "mp3"}))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.ClosePolicyViolation, code)
+		require.Equal(t, []streamEvent{{
+			Type:  msgTypeError,
+			Error: `invalid config message: invalid format: invalid encoding "mp3"`,
+		}}, events)
+	})
+
+	t.Run("binary config", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{0, 0}))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.ClosePolicyViolation, code)
+		require.Equal(t, "expected config message", events[0].Error)
+	})
+
+	t.Run("malformed config", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.ClosePolicyViolation, code)
+		require.Contains(t, events[0].Error, "invalid config message")
+	})
+
+	t.Run("closed after config", func(t *testing.T) {
+		// Detectors acquired by streams failing during configuration get
+		// returned, leaving the pool available.
+		for i := 0; i < 4; i++ {
+			conn := dialStream(t, s)
+			require.NoError(t, conn.WriteJSON(streamConfig{Type: "config"}))
+			require.NoError(t, conn.Close())
+		}
+
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config"}))
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var cfg streamConfig
+		require.NoError(t, json.Unmarshal(data, &cfg))
+		require.Equal(t, 16000, cfg.SampleRate)
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		conn := dialStream(t, s)
+		pinged := make(chan struct{}, 1)
+		conn.SetPingHandler(func(string) error {
+			select {
+			case pinged <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		go func() {
+			_, _, _ = conn.ReadMessage()
+		}()
+
+		select {
+		case <-pinged:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timed out waiting for ping")
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		s := newTestServer(t, serverConfig{MaxBodySize: 1024, RequestTimeout: time.Second, PingInterval: time.Second})
+		conn := dialStream(t, s)
+
+		closed := make(chan int)
+		go func() {
+			_, code := readEvents(t, conn)
+			closed <- code
+		}()
+
+		// The stream is tracked from before the upgrade completes.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, s.closeStreams(ctx))
+		require.Equal(t, websocket.CloseGoingAway, <-closed)
+
+		// New streams are refused.
+		httpSrv := httptest.NewServer(s.handler())
+		defer httpSrv.Close()
+		_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/v1/stream", nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+}
+
+func TestStream(t *testing.T) {
+	pcm, err := os.ReadFile(testSamplesPath)
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+
+	s := newTestServer(t, serverConfig{MaxBodySize: 1 << 20, RequestTimeout: 10 * time.Second, PingInterval: time.Second})
+
+	sd, err := speech.NewDetector(speech.DetectorConfig{
+		ModelPath:  testModelPath,
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+	probs, err := sd.Probabilities(samples)
+	require.NoError(t, err)
+	require.NoError(t, sd.Reset())
+	expected, err := sd.Detect(samples)
+	require.NoError(t, err)
+	duration := float64(len(samples)) / 16000
+	expected[len(expected)-1].SpeechEndAt = duration
+
+	// segmentsFromEvents checks start events are followed by matching end
+	// events and returns the resulting segments.
+	segmentsFromEvents := func(t *testing.T, events []streamEvent) []speech.Segment {
+		t.Helper()
+		var segments []speech.Segment
+		var start *float64
+		for _, ev := range events {
+			switch ev.Type {
+			case msgTypeStart:
+				require.Nil(t, start)
+				start = ev.Start
+			case msgTypeEnd:
+				require.NotNil(t, start)
+				require.Equal(t, *start, *ev.Start)
+				segments = append(segments, speech.Segment{SpeechStartAt: *ev.Start, SpeechEndAt: *ev.End})
+				start = nil
+			}
+		}
+		return segments
+	}
+
+	t.Run("f32le", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config", Probabilities: true}))
+
+		var cfg streamConfig
+		require.NoError(t, conn.ReadJSON(&cfg))
+		require.Equal(t, streamConfig{
+			Type:           "config",
+			SampleRate:     16000,
+			Format:         "f32le",
+			Threshold:      0.5,
+			Probabilities:  true,
+			WindowDuration: 0.032,
+		}, cfg)
+
+		// Chunks not aligned on samples nor windows.
+		for i := 0; i < len(pcm); i += 1001 {
+			require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, pcm[i:min(i+1001, len(pcm))]))
+		}
+		require.NoError(t, conn.WriteJSON(streamMessage{Type: msgTypeEnd}))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.CloseNormalClosure, code)
+		require.Equal(t, expected, segmentsFromEvents(t, events))
+
+		var streamProbs []float32
+		for _, ev := range events {
+			if ev.Type == msgTypeProbability {
+				require.InDelta(t, float64(len(streamProbs)+1)*0.032, *ev.Time, 1e-9)
+				streamProbs = append(streamProbs, *ev.Probability)
+			}
+		}
+		require.Equal(t, probs, streamProbs)
+	})
+
+	t.Run("s16le at 48kHz", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config", SampleRate: 48000, Format: "s16le"}))
+		var cfg streamConfig
+		require.NoError(t, conn.ReadJSON(&cfg))
+
+		data := audio.EncodeSamples(nil, audio.Resample(samples, 16000, 48000), audio.EncodingS16LE)
+		for i := 0; i < len(data); i += 4000 {
+			require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, data[i:min(i+4000, len(data))]))
+		}
+		require.NoError(t, conn.WriteJSON(streamMessage{Type: msgTypeEnd}))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.CloseNormalClosure, code)
+		segments := segmentsFromEvents(t, events)
+		require.Len(t, segments, len(expected))
+		for i := range segments {
+			require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+			require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.1)
+		}
+	})
+
+	t.Run("closed after audio", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config"}))
+		var cfg streamConfig
+		require.NoError(t, conn.ReadJSON(&cfg))
+
+		// Closing the connection ends the stream like an end message.
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, pcm))
+		require.NoError(t, conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.CloseNormalClosure, code)
+		require.Equal(t, expected, segmentsFromEvents(t, events))
+	})
+
+	t.Run("closed before audio", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config"}))
+		var cfg streamConfig
+		require.NoError(t, conn.ReadJSON(&cfg))
+
+		require.NoError(t, conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.CloseNormalClosure, code)
+		require.Empty(t, events)
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config", Threshold: 0.99}))
+		var cfg streamConfig
+		require.NoError(t, conn.ReadJSON(&cfg))
+		require.Equal(t, float32(0.99), cfg.Threshold)
+		require.NoError(t, conn.WriteJSON(streamMessage{Type: msgTypeEnd}))
+		_, code := readEvents(t, conn)
+		require.Equal(t, websocket.CloseNormalClosure, code)
+
+		// Detectors are returned to the pool with their original threshold.
+		sd, err := s.pool.Get(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, float32(0.5), sd.Config().Threshold)
+		require.NoError(t, s.pool.Put(sd))
+	})
+
+	t.Run("unexpected message", func(t *testing.T) {
+		conn := dialStream(t, s)
+		require.NoError(t, conn.WriteJSON(streamConfig{Type: "config"}))
+		var cfg streamConfig
+		require.NoError(t, conn.ReadJSON(&cfg))
+		require.NoError(t, conn.WriteJSON(streamMessage{Type: "pause"}))
+
+		events, code := readEvents(t, conn)
+		require.Equal(t, websocket.ClosePolicyViolation, code)
+		require.Equal(t, `unexpected message type "pause"`, events[len(events)-1].Error)
+	})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func runBench(e *env, args []string) (err error) {
+	fs := newFlagSet(e, "bench", "[input]")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	runs := fs.Int("runs", 5, "number of detection runs")
+	workers := fs.Int("workers", 1, "number of detectors processing the input concurrently")
+
+	path, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := cfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+	if *runs <= 0 {
+		return usageError{fmt.Errorf("invalid runs: should be a positive number")}
+	}
+	if *workers <= 0 {
+		return usageError{fmt.Errorf("invalid workers: should be a positive number")}
+	}
+
+	in, err := inFlags.read(e, path, cfg.SampleRate)
+	if err != nil {
+		return err
+	}
+	pcm := audio.Resample(in.samples, in.sampleRate, cfg.SampleRate)
+
+	start := time.Now()
+	sd, err := speech.NewDetector(*cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create detector: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, sd.Destroy())
+	}()
+	loadTime := time.Since(start)
+
+	var total, best time.Duration
+	for i := 0; i < *runs; i++ {
+		if err := sd.Reset(); err != nil {
+			return err
+		}
+
+		start := time.Now()
+		if *workers > 1 {
+			_, err = sd.DetectParallel(pcm, *workers)
+		} else {
+			_, err = sd.Detect(pcm)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to detect speech: %w", err)
+		}
+		elapsed := time.Since(start)
+
+		total += elapsed
+		if i == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+
+	audioDuration := time.Duration(in.duration() * float64(time.Second))
+	mean := total / time.Duration(*runs)
+	stats := sd.Stats()
+
+	fmt.Fprintf(e.stdout, "audio duration:   %s\n", audioDuration.Round(time.Millisecond))
+	fmt.Fprintf(e.stdout, "model load:       %s\n", loadTime.Round(time.Microsecond))
+	fmt.Fprintf(e.stdout, "runs:             %d\n", *runs)
+	fmt.Fprintf(e.stdout, "mean:             %s\n", mean.Round(time.Microsecond))
+	fmt.Fprintf(e.stdout, "best:             %s\n", best.Round(time.Microsecond))
+	fmt.Fprintf(e.stdout, "real-time factor: %.4f (%.1fx faster than real time)\n",
+		mean.Seconds()/audioDuration.Seconds(), audioDuration.Seconds()/mean.Seconds())
+	fmt.Fprintf(e.stdout, "windows:          %d\n", stats.Windows)
+	fmt.Fprintf(e.stdout, "skipped windows:  %d (%.1f%%)\n",
+		stats.SkippedWindows, 100*float64(stats.SkippedWindows)/float64(max(stats.Windows, 1)))
+
+	return nil
+}
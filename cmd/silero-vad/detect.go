@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func runDetect(e *env, args []string) (err error) {
+	fs := newFlagSet(e, "detect", "[input]")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	format := fs.String("format", "text", "output format: "+segmentsFormatsUsage())
+	outPath := fs.String("o", "", "output file (defaults to stdout)")
+	workers := fs.Int("workers", 1, "number of detectors processing the input concurrently")
+
+	path, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := validateSegmentsFormat(*format); err != nil {
+		return err
+	}
+
+	in, segments, err := detect(e, *cfg, inFlags, path, *workers)
+	if err != nil {
+		return err
+	}
+	segments = closeSegments(segments, in.duration())
+
+	out, err := openOutput(e, *outPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	return writeSegments(out, *format, segments, fileID(path))
+}
+
+// detect reads the input and runs speech detection over it.
+func detect(e *env, cfg speech.DetectorConfig, inFlags *inputFlags, path string, workers int) (in input, segments []speech.Segment, err error) {
+	if err := cfg.IsValid(); err != nil {
+		return input{}, nil, usageError{err}
+	}
+	if workers <= 0 {
+		return input{}, nil, usageError{fmt.Errorf("invalid workers: should be a positive number")}
+	}
+
+	in, err = inFlags.read(e, path, cfg.SampleRate)
+	if err != nil {
+		return input{}, nil, err
+	}
+
+	sd, err := speech.NewDetector(cfg)
+	if err != nil {
+		return input{}, nil, fmt.Errorf("failed to create detector: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, sd.Destroy())
+	}()
+
+	pcm := audio.Resample(in.samples, in.sampleRate, cfg.SampleRate)
+	if workers > 1 {
+		segments, err = sd.DetectParallel(pcm, workers)
+	} else {
+		segments, err = sd.Detect(pcm)
+	}
+	if err != nil {
+		return input{}, nil, fmt.Errorf("failed to detect speech: %w", err)
+	}
+
+	return in, segments, nil
+}
+
+// fileID returns the identifier of the input at path, its base name without
+// extension, as used in RTTM records.
+func fileID(path string) string {
+	if path == "" || path == "-" {
+		return "stdin"
+	}
+	base := filepath.Base(path)
+	id := strings.Join(strings.Fields(strings.TrimSuffix(base, filepath.Ext(base))), "_")
+	if id == "" {
+		return "audio"
+	}
+	return id
+}
+
+// closeSegments ends a trailing segment still open at the end of the input.
+func closeSegments(segments []speech.Segment, duration float64) []speech.Segment {
+	if n := len(segments); n > 0 && segments[n-1].SpeechEndAt == 0 {
+		segments[n-1].SpeechEndAt = duration
+	}
+	return segments
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/eval"
+	"github.com/streamer45/silero-vad-go/format"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+// The audio file extensions looked up in dataset directories.
+var audioExts = []string{".wav", ".pcm", ".raw"}
+
+// referenceExts maps reference formats to their file extensions.
+var referenceExts = map[string]string{
+	"rttm":                  ".rttm",
+	string(format.Audacity): ".txt",
+	string(format.WebVTT):   ".vtt",
+	string(format.SRT):      ".srt",
+	string(format.TextGrid): ".TextGrid",
+	string(format.CSV):      ".csv",
+	string(format.JSON):     ".json",
+}
+
+// datasetItem is an audio file along with its reference labels.
+type datasetItem struct {
+	audioPath string
+	refPath   string
+}
+
+// findDataset lists the audio files in dir having a reference file with the
+// same base name, sorted by name.
+func findDataset(dir, refFormat string) ([]datasetItem, error) {
+	refExt, ok := referenceExts[refFormat]
+	if !ok {
+		return nil, usageError{fmt.Errorf("invalid reference format %q", refFormat)}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset directory: %w", err)
+	}
+
+	var items []datasetItem
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !containsFold(audioExts, ext) {
+			continue
+		}
+
+		refPath := filepath.Join(dir, strings.TrimSuffix(entry.Name(), ext)+refExt)
+		if _, err := os.Stat(refPath); err != nil {
+			continue
+		}
+
+		items = append(items, datasetItem{
+			audioPath: filepath.Join(dir, entry.Name()),
+			refPath:   refPath,
+		})
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no audio files with %s references found in %s", refExt, dir)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].audioPath < items[j].audioPath
+	})
+
+	return items, nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// readReference reads the reference segments at path. RTTM files can hold
+// several inputs, the records matching fileID are used if any, all of them
+// otherwise.
+func readReference(path, refFormat, fileID string) ([]speech.Segment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reference: %w", err)
+	}
+	defer file.Close()
+
+	if refFormat != "rttm" {
+		f, err := format.ParseFormat(refFormat)
+		if err != nil {
+			return nil, usageError{fmt.Errorf("invalid reference format %q", refFormat)}
+		}
+		segments, err := format.Decode(file, f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read reference %s: %w", path, err)
+		}
+		return segments, nil
+	}
+
+	files, err := format.ReadRTTM(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reference %s: %w", path, err)
+	}
+	if segments, ok := files[fileID]; ok {
+		return segments, nil
+	}
+	var segments []speech.Segment
+	for _, s := range files {
+		segments = append(segments, s...)
+	}
+	return segments, nil
+}
+
+type evalMetrics struct {
+	Precision          float64 `json:"precision"`
+	Recall             float64 `json:"recall"`
+	F1                 float64 `json:"f1"`
+	FalseAlarmRate     float64 `json:"false_alarm_rate"`
+	MissRate           float64 `json:"miss_rate"`
+	DetectionErrorRate float64 `json:"detection_error_rate"`
+}
+
+func newEvalMetrics(res eval.Result) evalMetrics {
+	return evalMetrics{
+		Precision:          res.Precision(),
+		Recall:             res.Recall(),
+		F1:                 res.F1(),
+		FalseAlarmRate:     res.FalseAlarmRate(),
+		MissRate:           res.MissRate(),
+		DetectionErrorRate: res.DetectionErrorRate(),
+	}
+}
+
+type evalFileReport struct {
+	File     string      `json:"file"`
+	Duration float64     `json:"duration"`
+	Result   eval.Result `json:"result"`
+	evalMetrics
+}
+
+type evalReport struct {
+	Files  []evalFileReport `json:"files"`
+	Total  eval.Result      `json:"total"`
+	Scores evalMetrics      `json:"scores"`
+}
+
+func runEval(e *env, args []string) (err error) {
+	fs := newFlagSet(e, "eval", "<dir>")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	var evalCfg eval.Config
+	fs.IntVar(&evalCfg.FrameMs, "frame-ms", 10, "duration of the scored frames")
+	fs.IntVar(&evalCfg.CollarMs, "collar-ms", 0, "duration excluded from scoring on each side of reference boundaries")
+	refFormat := fs.String("ref-format", "rttm",
+		"format of the reference files, named after the audio files: rttm, "+strings.Join(formatNames(), ", "))
+	outFormat := fs.String("format", "text", "output format: text or json")
+	outPath := fs.String("o", "", "output file (defaults to stdout)")
+
+	dir, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		return usageError{fmt.Errorf("missing dataset directory")}
+	}
+	if *outFormat != "text" && *outFormat != "json" {
+		return usageError{fmt.Errorf("invalid format %q", *outFormat)}
+	}
+	if err := evalCfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+	if err := cfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+
+	items, err := findDataset(dir, *refFormat)
+	if err != nil {
+		return err
+	}
+
+	sd, err := speech.NewDetector(*cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create detector: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, sd.Destroy())
+	}()
+
+	var report evalReport
+	for _, item := range items {
+		id := fileID(item.audioPath)
+
+		reference, err := readReference(item.refPath, *refFormat, id)
+		if err != nil {
+			return err
+		}
+
+		in, err := inFlags.read(e, item.audioPath, cfg.SampleRate)
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.audioPath, err)
+		}
+
+		if err := sd.Reset(); err != nil {
+			return err
+		}
+		hypothesis, err := sd.Detect(audio.Resample(in.samples, in.sampleRate, cfg.SampleRate))
+		if err != nil {
+			return fmt.Errorf("%s: failed to detect speech: %w", item.audioPath, err)
+		}
+
+		res, err := eval.Evaluate(reference, hypothesis, in.duration(), evalCfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.audioPath, err)
+		}
+
+		report.Files = append(report.Files, evalFileReport{
+			File:        filepath.Base(item.audioPath),
+			Duration:    in.duration(),
+			Result:      res,
+			evalMetrics: newEvalMetrics(res),
+		})
+		report.Total = report.Total.Add(res)
+	}
+	report.Scores = newEvalMetrics(report.Total)
+
+	out, err := openOutput(e, *outPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	if *outFormat == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "file\tduration\tprecision\trecall\tf1\tfalse alarm\tmiss\tDER\t")
+	row := func(name string, duration float64, m evalMetrics) {
+		fmt.Fprintf(tw, "%s\t%.3f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t\n", name, duration,
+			m.Precision, m.Recall, m.F1, m.FalseAlarmRate, m.MissRate, m.DetectionErrorRate)
+	}
+	var totalDuration float64
+	for _, f := range report.Files {
+		row(f.File, f.Duration, f.evalMetrics)
+		totalDuration += f.Duration
+	}
+	row("total", totalDuration, report.Scores)
+
+	return tw.Flush()
+}
+
+func formatNames() []string {
+	names := make([]string, 0, len(format.Formats))
+	for _, f := range format.Formats {
+		names = append(names, string(f))
+	}
+	return names
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+// newFlagSet returns a flag set for the given command which reports errors
+// instead of exiting.
+func newFlagSet(e *env, name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: silero-vad %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args and returns the single optional positional argument.
+func parseFlags(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return "", err
+		}
+		return "", errFlagParse
+	}
+
+	if fs.NArg() > 1 {
+		return "", usageError{fmt.Errorf("too many arguments: %s", strings.Join(fs.Args(), " "))}
+	}
+
+	return fs.Arg(0), nil
+}
+
+type float32Value struct {
+	v *float32
+}
+
+func (f float32Value) String() string {
+	if f.v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(*f.v), 'g', -1, 32)
+}
+
+func (f float32Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return err
+	}
+	*f.v = float32(v)
+	return nil
+}
+
+// float32ListValue is a comma separated list of float32 values.
+type float32ListValue struct {
+	v *[]float32
+}
+
+func (f float32ListValue) String() string {
+	if f.v == nil {
+		return ""
+	}
+	values := make([]string, len(*f.v))
+	for i, v := range *f.v {
+		values[i] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return strings.Join(values, ",")
+}
+
+func (f float32ListValue) Set(s string) error {
+	var values []float32
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 32)
+		if err != nil {
+			return err
+		}
+		values = append(values, float32(v))
+	}
+	*f.v = values
+	return nil
+}
+
+// intListValue is a comma separated list of int values.
+type intListValue struct {
+	v *[]int
+}
+
+func (l intListValue) String() string {
+	if l.v == nil {
+		return ""
+	}
+	values := make([]string, len(*l.v))
+	for i, v := range *l.v {
+		values[i] = strconv.Itoa(v)
+	}
+	return strings.Join(values, ",")
+}
+
+func (l intListValue) Set(s string) error {
+	var values []int
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+	}
+	*l.v = values
+	return nil
+}
+
+type logLevelValue struct {
+	v *speech.LogLevel
+}
+
+func (l logLevelValue) String() string {
+	if l.v == nil {
+		return ""
+	}
+	return l.v.String()
+}
+
+func (l logLevelValue) Set(s string) error {
+	v, err := speech.ParseLogLevel(s)
+	if err != nil {
+		return err
+	}
+	*l.v = v
+	return nil
+}
+
+type modelVersionValue struct {
+	v *speech.ModelVersion
+}
+
+func (m modelVersionValue) String() string {
+	if m.v == nil {
+		return ""
+	}
+	return m.v.String()
+}
+
+func (m modelVersionValue) Set(s string) error {
+	v, err := speech.ParseModelVersion(s)
+	if err != nil {
+		return err
+	}
+	*m.v = v
+	return nil
+}
+
+// addDetectorFlags registers a flag for each DetectorConfig field.
+func addDetectorFlags(fs *flag.FlagSet) *speech.DetectorConfig {
+	cfg := &speech.DetectorConfig{
+		Threshold: 0.5,
+		LogLevel:  speech.LogLevelWarn,
+	}
+
+	fs.StringVar(&cfg.ModelPath, "model", os.Getenv("SILERO_VAD_MODEL"),
+		"path to the Silero VAD ONNX model (defaults to $SILERO_VAD_MODEL)")
+	fs.IntVar(&cfg.SampleRate, "sample-rate", 16000,
+		"model sample rate, 8000 or 16000 (input audio is resampled to it)")
+	fs.Var(float32Value{&cfg.Threshold}, "threshold", "speech probability threshold")
+	fs.IntVar(&cfg.MinSilenceDurationMs, "min-silence-duration-ms", 100,
+		"duration of silence to wait for before ending a speech segment")
+	fs.IntVar(&cfg.SpeechPadMs, "speech-pad-ms", 30, "padding added to each side of speech segments")
+	fs.Var(logLevelValue{&cfg.LogLevel}, "log-level",
+		"ONNX Runtime log level: verbose, info, warn, error or fatal")
+	fs.Var(modelVersionValue{&cfg.ModelVersion}, "model-version", "model version: auto, v4, v5 or v6")
+
+	adaptive := &cfg.AdaptiveThreshold
+	fs.BoolVar(&adaptive.Enabled, "adaptive-threshold", false,
+		"adapt the threshold to the background noise, starting from -threshold")
+	fs.Func("adaptive-min-threshold", "lower bound of the adaptive threshold (default 0.3)",
+		float32Value{&adaptive.MinThreshold}.Set)
+	fs.Func("adaptive-max-threshold", "upper bound of the adaptive threshold (default 0.8)",
+		float32Value{&adaptive.MaxThreshold}.Set)
+	fs.Func("adaptive-margin", "margin of the adaptive threshold above noise probabilities (default 0.25)",
+		float32Value{&adaptive.Margin}.Set)
+	fs.IntVar(&adaptive.AdaptationMs, "adaptive-adaptation-ms", 0,
+		"time constant of the noise estimates (default 5000)")
+	fs.Float64Var(&adaptive.EnergyMarginDb, "adaptive-energy-margin-db", 0,
+		"level above the noise floor beyond which windows aren't considered noise (default 6)")
+
+	gate := &cfg.EnergyGate
+	fs.BoolVar(&gate.Enabled, "energy-gate", false, "skip inference on windows of near silence")
+	fs.Float64Var(&gate.RMSFloorDb, "energy-gate-rms-floor-db", 0,
+		"RMS level in dBFS below which windows can be skipped (default -60)")
+	fs.Float64Var(&gate.PeakFloorDb, "energy-gate-peak-floor-db", 0,
+		"peak level in dBFS below which windows can be skipped (default -50)")
+	fs.IntVar(&gate.HangoverMs, "energy-gate-hangover-ms", 0,
+		"duration of near silence still inferred before skipping (default 100)")
+
+	return cfg
+}
+
+// inputFlags selects how the input audio is decoded.
+type inputFlags struct {
+	format string
+	rate   int
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	f := &inputFlags{}
+	fs.StringVar(&f.format, "input-format", "auto",
+		"input format: auto, wav, f32le or s16le (auto detects WAV and falls back to f32le)")
+	fs.IntVar(&f.rate, "input-rate", 0, "sample rate of raw PCM input (defaults to -sample-rate)")
+	return f
+}
+
+// input holds decoded mono audio at its original sample rate.
+type input struct {
+	samples    []float32
+	sampleRate int
+}
+
+func (in input) duration() float64 {
+	return float64(len(in.samples)) / float64(in.sampleRate)
+}
+
+// read decodes the audio from path, or stdin if path is empty or "-". Raw PCM
+// input is assumed to be at defaultRate unless -input-rate is set.
+func (f *inputFlags) read(e *env, path string, defaultRate int) (input, error) {
+	var r io.Reader = e.stdin
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return input{}, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	br := bufio.NewReader(r)
+
+	format := f.format
+	if format == "auto" {
+		format = "f32le"
+		if magic, _ := br.Peek(4); string(magic) == "RIFF" || strings.EqualFold(filepath.Ext(path), ".wav") {
+			format = "wav"
+		}
+	}
+
+	if format == "wav" {
+		samples, wavFormat, err := audio.ReadWAV(br)
+		if err != nil {
+			return input{}, fmt.Errorf("failed to read WAV input: %w", err)
+		}
+		return input{samples: samples, sampleRate: wavFormat.SampleRate}, nil
+	}
+
+	enc, err := audio.ParseEncoding(format)
+	if err != nil {
+		return input{}, usageError{fmt.Errorf("invalid input format %q", f.format)}
+	}
+
+	rate := f.rate
+	if rate == 0 {
+		rate = defaultRate
+	}
+	if rate < 0 {
+		return input{}, usageError{fmt.Errorf("invalid input rate: should be a positive number")}
+	}
+
+	samples, err := audio.ReadPCM(br, enc)
+	if err != nil {
+		return input{}, fmt.Errorf("failed to read PCM input: %w", err)
+	}
+
+	return input{samples: samples, sampleRate: rate}, nil
+}
+
+// openOutput creates the file at path, or returns stdout if path is empty or "-".
+func openOutput(e *env, path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{e.stdout}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output: %w", err)
+	}
+	return file, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+// env holds the standard streams commands read from and write to.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = []command{
+	{name: "detect", summary: "detect speech segments", run: runDetect},
+	{name: "split", summary: "write each speech segment to a separate WAV file", run: runSplit},
+	{name: "trim", summary: "write speech segments only to a single WAV file", run: runTrim},
+	{name: "probs", summary: "print the speech probability of each window", run: runProbs},
+	{name: "bench", summary: "measure detection speed", run: runBench},
+	{name: "eval", summary: "score detection against reference labels", run: runEval},
+	{name: "tune", summary: "search the segmentation settings best matching reference labels", run: runTune},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: silero-vad <command> [flags] [input]\n\n")
+	fmt.Fprintf(w, "The input is a WAV or raw PCM file, read from stdin if omitted or \"-\".\n")
+	fmt.Fprintf(w, "The eval command reads a directory of audio files and reference labels instead.\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'silero-vad <command> -h' for the flags of a command.\n")
+}
+
+func main() {
+	os.Exit(run(&env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}, os.Args[1:]))
+}
+
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		usage(e.stdout)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		err := cmd.run(e, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		// Flag parsing errors are already reported by the flag set.
+		if errors.Is(err, errFlagParse) {
+			return 2
+		}
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(e.stderr, "silero-vad %s: %s\n", cmd.name, err)
+			return 2
+		}
+		if err != nil {
+			fmt.Fprintf(e.stderr, "silero-vad %s: %s\n", cmd.name, err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(e.stderr, "silero-vad: unknown command %q\n\n", args[0])
+	usage(e.stderr)
+	return 2
+}
+
+// errFlagParse is returned when command line flags fail to parse.
+var errFlagParse = errors.New("failed to parse flags")
+
+// usageError is returned on invalid command line arguments.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/format"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/split"
+	"github.com/streamer45/silero-vad-go/trim"
+)
+
+const (
+	testModelPath   = "../../testfiles/silero_vad.onnx"
+	testSamplesPath = "../../testfiles/samples.pcm"
+)
+
+func runCmd(t *testing.T, stdin []byte, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(&env{
+		stdin:  bytes.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+	}, args)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunUsage(t *testing.T) {
+	t.Run("no command", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil)
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, "Usage: silero-vad <command>")
+	})
+
+	t.Run("help", func(t *testing.T) {
+		code, stdout, _ := runCmd(t, nil, "help")
+		require.Equal(t, 0, code)
+		for _, cmd := range commands {
+			require.Contains(t, stdout, cmd.name)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "foo")
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, `unknown command "foo"`)
+	})
+
+	t.Run("command help", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "detect", "-h")
+		require.Equal(t, 0, code)
+		require.Contains(t, stderr, "-min-silence-duration-ms")
+		require.Contains(t, stderr, "-model-version")
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "detect", "-foo")
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, "flag provided but not defined: -foo")
+	})
+
+	t.Run("invalid flag value", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "detect", "-model-version", "v7")
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, `invalid model version "v7"`)
+	})
+
+	t.Run("missing model", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "detect", "-model", "")
+		require.Equal(t, 2, code)
+		require.Equal(t, "silero-vad detect: invalid ModelPath: should not be empty\n", stderr)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "detect", "-format", "xml")
+		require.Equal(t, 2, code)
+		require.Equal(t, "silero-vad detect: invalid format \"xml\"\n", stderr)
+	})
+
+	t.Run("invalid tune method", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "tune", "-method", "random", "dir")
+		require.Equal(t, 2, code)
+		require.Equal(t, "silero-vad tune: invalid method \"random\"\n", stderr)
+	})
+
+	t.Run("too many arguments", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "probs", "-model", testModelPath, "a", "b")
+		require.Equal(t, 2, code)
+		require.Equal(t, "silero-vad probs: too many arguments: a b\n", stderr)
+	})
+}
+
+func TestDetectorFlags(t *testing.T) {
+	fs := newFlagSet(&env{stderr: &bytes.Buffer{}}, "test", "")
+	cfg := addDetectorFlags(fs)
+	require.NoError(t, fs.Parse([]string{
+		"-model", "model.onnx",
+		"-sample-rate", "8000",
+		"-threshold", "0.3",
+		"-min-silence-duration-ms", "200",
+		"-speech-pad-ms", "10",
+		"-log-level", "error",
+		"-model-version", "v4",
+		"-adaptive-threshold",
+		"-adaptive-min-threshold", "0.2",
+		"-adaptive-max-threshold", "0.9",
+		"-adaptive-margin", "0.1",
+		"-adaptive-adaptation-ms", "1000",
+		"-adaptive-energy-margin-db", "3",
+		"-energy-gate",
+		"-energy-gate-rms-floor-db", "-70",
+		"-energy-gate-peak-floor-db", "-55",
+		"-energy-gate-hangover-ms", "200",
+	}))
+	require.Equal(t, speech.DetectorConfig{
+		ModelPath:            "model.onnx",
+		SampleRate:           8000,
+		Threshold:            0.3,
+		MinSilenceDurationMs: 200,
+		SpeechPadMs:          10,
+		LogLevel:             speech.LogLevelError,
+		ModelVersion:         speech.ModelVersionV4,
+		AdaptiveThreshold: speech.AdaptiveThresholdConfig{
+			Enabled:        true,
+			MinThreshold:   0.2,
+			MaxThreshold:   0.9,
+			Margin:         0.1,
+			AdaptationMs:   1000,
+			EnergyMarginDb: 3,
+		},
+		EnergyGate: speech.EnergyGateConfig{
+			Enabled:     true,
+			RMSFloorDb:  -70,
+			PeakFloorDb: -55,
+			HangoverMs:  200,
+		},
+	}, *cfg)
+}
+
+func TestListFlags(t *testing.T) {
+	var thresholds []float32
+	var durations []int
+	fs := newFlagSet(&env{stderr: &bytes.Buffer{}}, "test", "")
+	fs.Var(float32ListValue{&thresholds}, "thresholds", "")
+	fs.Var(intListValue{&durations}, "durations", "")
+	require.NoError(t, fs.Parse([]string{"-thresholds", "0.3, 0.5", "-durations", "0,100,200"}))
+	require.Equal(t, []float32{0.3, 0.5}, thresholds)
+	require.Equal(t, []int{0, 100, 200}, durations)
+	require.Equal(t, "0.3,0.5", float32ListValue{&thresholds}.String())
+	require.Equal(t, "0,100,200", intListValue{&durations}.String())
+
+	require.Error(t, fs.Parse([]string{"-durations", "0,a"}))
+}
+
+func TestInputRead(t *testing.T) {
+	samples := []float32{0.5, -0.5, 0.25, -0.25}
+
+	var wav bytes.Buffer
+	require.NoError(t, audio.WriteWAV(&wav, samples, 8000))
+
+	tcs := []struct {
+		name     string
+		format   string
+		rate     int
+		data     []byte
+		expected input
+	}{
+		{
+			name:     "auto wav",
+			format:   "auto",
+			data:     wav.Bytes(),
+			expected: input{samples: samples, sampleRate: 8000},
+		},
+		{
+			name:     "auto raw",
+			format:   "auto",
+			data:     audio.EncodeSamples(nil, samples, audio.EncodingFloat32LE),
+			expected: input{samples: samples, sampleRate: 16000},
+		},
+		{
+			name:     "s16le",
+			format:   "s16le",
+			rate:     8000,
+			data:     audio.EncodeSamples(nil, samples, audio.EncodingS16LE),
+			expected: input{samples: samples, sampleRate: 8000},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &inputFlags{format: tc.format, rate: tc.rate}
+			in, err := f.read(&env{stdin: bytes.NewReader(tc.data)}, "-", 16000)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, in)
+		})
+	}
+
+	t.Run("invalid format", func(t *testing.T) {
+		f := &inputFlags{format: "mp3"}
+		_, err := f.read(&env{stdin: bytes.NewReader(nil)}, "", 16000)
+		require.EqualError(t, err, `invalid input format "mp3"`)
+	})
+}
+
+func TestFileID(t *testing.T) {
+	require.Equal(t, "stdin", fileID(""))
+	require.Equal(t, "stdin", fileID("-"))
+	require.Equal(t, "rec1", fileID("/data/rec1.wav"))
+	require.Equal(t, "my_rec", fileID("my rec.wav"))
+	require.Equal(t, "audio", fileID(".wav"))
+}
+
+func TestCloseSegments(t *testing.T) {
+	require.Empty(t, closeSegments(nil, 10))
+
+	require.Equal(t, []speech.Segment{
+		{SpeechStartAt: 1, SpeechEndAt: 2},
+		{SpeechStartAt: 3, SpeechEndAt: 10},
+	}, closeSegments([]speech.Segment{
+		{SpeechStartAt: 1, SpeechEndAt: 2},
+		{SpeechStartAt: 3},
+	}, 10))
+}
+
+func TestWriteSegments(t *testing.T) {
+	segments := []speech.Segment{
+		{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+		{SpeechStartAt: 2.88, SpeechEndAt: 3.232},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeSegments(&buf, "text", segments, "rec"))
+		require.Equal(t, "start=1.056 end=1.632\nstart=2.880 end=3.232\n", buf.String())
+	})
+
+	t.Run("rttm", func(t *testing.T) {
+		require.NoError(t, validateSegmentsFormat("rttm"))
+
+		var buf bytes.Buffer
+		require.NoError(t, writeSegments(&buf, "rttm", segments, "rec"))
+		files, err := format.ReadRTTM(&buf)
+		require.NoError(t, err)
+		require.Len(t, files["rec"], len(segments))
+	})
+
+	for _, f := range format.Formats {
+		t.Run(string(f), func(t *testing.T) {
+			require.NoError(t, validateSegmentsFormat(string(f)))
+
+			var buf bytes.Buffer
+			require.NoError(t, writeSegments(&buf, string(f), segments, "rec"))
+			decoded, err := format.Decode(&buf, f)
+			require.NoError(t, err)
+			require.Equal(t, segments, decoded)
+		})
+	}
+}
+
+func TestFindDataset(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.wav", "b.rttm", "a.pcm", "a.rttm", "c.wav", "d.txt", "e.WAV", "e.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	items, err := findDataset(dir, "rttm")
+	require.NoError(t, err)
+	require.Equal(t, []datasetItem{
+		{audioPath: filepath.Join(dir, "a.pcm"), refPath: filepath.Join(dir, "a.rttm")},
+		{audioPath: filepath.Join(dir, "b.wav"), refPath: filepath.Join(dir, "b.rttm")},
+	}, items)
+
+	items, err = findDataset(dir, "audacity")
+	require.NoError(t, err)
+	require.Equal(t, []datasetItem{
+		{audioPath: filepath.Join(dir, "e.WAV"), refPath: filepath.Join(dir, "e.txt")},
+	}, items)
+
+	_, err = findDataset(dir, "srt")
+	require.EqualError(t, err, "no audio files with .srt references found in "+dir)
+
+	_, err = findDataset(dir, "xml")
+	require.EqualError(t, err, `invalid reference format "xml"`)
+}
+
+func TestReadReference(t *testing.T) {
+	dir := t.TempDir()
+	rttmPath := filepath.Join(dir, "ref.rttm")
+	require.NoError(t, os.WriteFile(rttmPath, []byte(
+		"SPEAKER a 1 1.000 1.000 <NA> <NA> x <NA> <NA>\n"+
+			"SPEAKER b 1 3.000 1.000 <NA> <NA> x <NA> <NA>\n"), 0o644))
+
+	segments, err := readReference(rttmPath, "rttm", "a")
+	require.NoError(t, err)
+	require.Equal(t, []speech.Segment{{SpeechStartAt: 1, SpeechEndAt: 2}}, segments)
+
+	segments, err = readReference(rttmPath, "rttm", "c")
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+
+	csvPath := filepath.Join(dir, "ref.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("start,end\n1,2\n"), 0o644))
+	segments, err = readReference(csvPath, "csv", "ref")
+	require.NoError(t, err)
+	require.Equal(t, []speech.Segment{{SpeechStartAt: 1, SpeechEndAt: 2}}, segments)
+}
+
+func TestCommands(t *testing.T) {
+	pcm, err := os.ReadFile(testSamplesPath)
+	require.NoError(t, err)
+
+	t.Run("detect", func(t *testing.T) {
+		code, stdout, stderr := runCmd(t, pcm, "detect", "-model", testModelPath,
+			"-min-silence-duration-ms", "0", "-speech-pad-ms", "0", "-format", "json")
+		require.Equal(t, 0, code, stderr)
+
+		segments, err := format.Decode(strings.NewReader(stdout), format.JSON)
+		require.NoError(t, err)
+		require.Equal(t, []speech.Segment{
+			{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+			{SpeechStartAt: 2.88, SpeechEndAt: 3.232},
+			{SpeechStartAt: 4.448, SpeechEndAt: float64(len(pcm)/4) / 16000},
+		}, segments)
+	})
+
+	t.Run("detect srt", func(t *testing.T) {
+		code, stdout, stderr := runCmd(t, pcm, "detect", "-model", testModelPath,
+			"-min-silence-duration-ms", "0", "-speech-pad-ms", "0", "-format", "srt")
+		require.Equal(t, 0, code, stderr)
+		require.True(t, strings.HasPrefix(stdout, "1\n00:00:01,056 --> 00:00:01,632\nspeech\n"))
+	})
+
+	t.Run("detect wav file", func(t *testing.T) {
+		samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+		path := filepath.Join(t.TempDir(), "input.wav")
+		var wav bytes.Buffer
+		require.NoError(t, audio.WriteWAV(&wav, audio.Resample(samples, 16000, 8000), 8000))
+		require.NoError(t, os.WriteFile(path, wav.Bytes(), 0o644))
+
+		code, stdout, stderr := runCmd(t, nil, "detect", "-model", testModelPath,
+			"-min-silence-duration-ms", "0", "-speech-pad-ms", "0", path)
+		require.Equal(t, 0, code, stderr)
+		require.Contains(t, stdout, "start=")
+	})
+
+	t.Run("probs", func(t *testing.T) {
+		code, stdout, stderr := runCmd(t, pcm, "probs", "-model", testModelPath, "-format", "csv")
+		require.Equal(t, 0, code, stderr)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Equal(t, "time,probability", lines[0])
+		require.Len(t, lines, 1+len(pcm)/4/512)
+		require.True(t, strings.HasPrefix(lines[2], "0.032,"))
+	})
+
+	t.Run("split", func(t *testing.T) {
+		dir := t.TempDir()
+		code, stdout, stderr := runCmd(t, pcm, "split", "-model", testModelPath, "-dir", dir, "-padding-ms", "100")
+		require.Equal(t, 0, code, stderr)
+
+		manifest, err := os.ReadFile(filepath.Join(dir, "manifest.jsonl"))
+		require.NoError(t, err)
+		require.Equal(t, string(manifest), stdout)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 3)
+
+		for i, line := range lines {
+			var entry split.Entry
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			require.Equal(t, fmt.Sprintf("segment_%04d.wav", i+1), entry.File)
+			require.Equal(t, i == len(lines)-1, entry.Truncated)
+
+			file, err := os.Open(filepath.Join(dir, entry.File))
+			require.NoError(t, err)
+			samples, format, err := audio.ReadWAV(file)
+			require.NoError(t, file.Close())
+			require.NoError(t, err)
+			require.Equal(t, 16000, format.SampleRate)
+			require.InDelta(t, entry.Duration, float64(len(samples))/16000, 0.001)
+		}
+	})
+
+	t.Run("trim", func(t *testing.T) {
+		timeMapPath := filepath.Join(t.TempDir(), "timemap.json")
+		code, stdout, stderr := runCmd(t, pcm, "trim", "-model", testModelPath,
+			"-max-gap-ms", "500", "-gap-ms", "200", "-crossfade-ms", "10", "-time-map", timeMapPath)
+		require.Equal(t, 0, code, stderr)
+
+		samples, _, err := audio.ReadWAV(strings.NewReader(stdout))
+		require.NoError(t, err)
+		require.NotEmpty(t, samples)
+		require.Less(t, len(samples), len(pcm)/4)
+
+		data, err := os.ReadFile(timeMapPath)
+		require.NoError(t, err)
+		var timeMap trim.TimeMap
+		require.NoError(t, json.Unmarshal(data, &timeMap))
+		require.NotEmpty(t, timeMap)
+		last := timeMap[len(timeMap)-1]
+		require.InDelta(t, float64(len(samples))/16000, last.OutputStart+last.Duration, 1e-6)
+	})
+
+	t.Run("trim invalid config", func(t *testing.T) {
+		code, _, stderr := runCmd(t, nil, "trim", "-model", testModelPath, "-gap-ms", "100")
+		require.Equal(t, 2, code)
+		require.Equal(t, "silero-vad trim: invalid GapMs: should be in range [0, MaxGapMs]\n", stderr)
+	})
+
+	t.Run("eval", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "samples.pcm"), pcm, 0o644))
+
+		var ref bytes.Buffer
+		require.NoError(t, format.WriteRTTM(&ref, "samples", []speech.Segment{
+			{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+			{SpeechStartAt: 2.88, SpeechEndAt: 3.232},
+			{SpeechStartAt: 4.448, SpeechEndAt: float64(len(pcm)/4) / 16000},
+		}))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "samples.rttm"), ref.Bytes(), 0o644))
+
+		code, stdout, stderr := runCmd(t, nil, "eval", "-model", testModelPath,
+			"-min-silence-duration-ms", "0", "-speech-pad-ms", "0", "-format", "json", dir)
+		require.Equal(t, 0, code, stderr)
+
+		var report evalReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		require.Len(t, report.Files, 1)
+		require.Equal(t, "samples.pcm", report.Files[0].File)
+		require.InDelta(t, 1, report.Scores.F1, 0.01)
+		require.InDelta(t, 0, report.Scores.DetectionErrorRate, 0.02)
+
+		code, stdout, stderr = runCmd(t, nil, "eval", "-model", testModelPath, dir)
+		require.Equal(t, 0, code, stderr)
+		require.Contains(t, stdout, "samples.pcm")
+		require.Contains(t, stdout, "total")
+	})
+
+	t.Run("tune", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "samples.pcm"), pcm, 0o644))
+
+		var ref bytes.Buffer
+		require.NoError(t, format.WriteRTTM(&ref, "samples", []speech.Segment{
+			{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+			{SpeechStartAt: 2.88, SpeechEndAt: 3.232},
+			{SpeechStartAt: 4.448, SpeechEndAt: float64(len(pcm)/4) / 16000},
+		}))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "samples.rttm"), ref.Bytes(), 0o644))
+
+		for _, method := range []string{"grid", "coordinate"} {
+			code, stdout, stderr := runCmd(t, nil, "tune", "-model", testModelPath, "-method", method,
+				"-thresholds", "0.3,0.5,0.7", "-min-silence-durations-ms", "0,500", "-speech-pads-ms", "0,200", dir)
+			require.Equal(t, 0, code, stderr)
+			require.Contains(t, stderr, "f1=")
+
+			var out map[string]any
+			require.NoError(t, json.Unmarshal([]byte(stdout), &out))
+			require.Len(t, out, 3)
+
+			cfg := speech.DetectorConfig{ModelPath: testModelPath}
+			require.NoError(t, json.Unmarshal([]byte(stdout), &cfg))
+			require.Equal(t, testModelPath, cfg.ModelPath)
+			require.Contains(t, []float32{0.3, 0.5, 0.7}, cfg.Threshold)
+			require.Equal(t, 0, cfg.SpeechPadMs)
+		}
+	})
+
+	t.Run("bench", func(t *testing.T) {
+		code, stdout, stderr := runCmd(t, pcm, "bench", "-model", testModelPath, "-runs", "2")
+		require.Equal(t, 0, code, stderr)
+		require.Contains(t, stdout, "real-time factor:")
+		require.Contains(t, stdout, "skipped windows:  0 (0.0%)")
+
+		code, stdout, stderr = runCmd(t, append(pcm, make([]byte, 4*16000*5)...),
+			"bench", "-model", testModelPath, "-runs", "2", "-energy-gate")
+		require.Equal(t, 0, code, stderr)
+		require.NotContains(t, stdout, "skipped windows:  0 ")
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/format"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+var probsFormats = []string{"text", "json", "csv"}
+
+func validateProbsFormat(f string) error {
+	for _, pf := range probsFormats {
+		if pf == f {
+			return nil
+		}
+	}
+	return usageError{fmt.Errorf("invalid format %q", f)}
+}
+
+// segmentsFormatsUsage lists the formats segments can be written in, the
+// plain text one being specific to the command line tool.
+func segmentsFormatsUsage() string {
+	return strings.Join(append([]string{"text", "rttm"}, formatNames()...), ", ")
+}
+
+func validateSegmentsFormat(f string) error {
+	if f == "text" || f == "rttm" {
+		return nil
+	}
+	if _, err := format.ParseFormat(f); err != nil {
+		return usageError{err}
+	}
+	return nil
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// writeSegments writes the segments to w in the given format. The file ID
+// identifies the input in RTTM records.
+func writeSegments(w io.Writer, f string, segments []speech.Segment, fileID string) error {
+	if f == "rttm" {
+		return format.WriteRTTM(w, fileID, segments)
+	}
+
+	if f == "text" {
+		for _, seg := range segments {
+			if _, err := fmt.Fprintf(w, "start=%s end=%s\n", formatSeconds(seg.SpeechStartAt), formatSeconds(seg.SpeechEndAt)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	sf, err := format.ParseFormat(f)
+	if err != nil {
+		return err
+	}
+	return format.Encode(w, sf, segments)
+}
+
+type probJSON struct {
+	Time        float64 `json:"time"`
+	Probability float32 `json:"probability"`
+}
+
+// writeProbs writes the probability of each window to w in the given format,
+// along with the time at which the window starts.
+func writeProbs(w io.Writer, f string, probs []float32, windowDuration float64) error {
+	formatProb := func(p float32) string {
+		return strconv.FormatFloat(float64(p), 'f', 4, 32)
+	}
+
+	switch f {
+	case "text":
+		for i, p := range probs {
+			if _, err := fmt.Fprintf(w, "%s %s\n", formatSeconds(float64(i)*windowDuration), formatProb(p)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		out := make([]probJSON, 0, len(probs))
+		for i, p := range probs {
+			out = append(out, probJSON{Time: float64(i) * windowDuration, Probability: p})
+		}
+		return json.NewEncoder(w).Encode(out)
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"time", "probability"})
+		for i, p := range probs {
+			_ = cw.Write([]string{formatSeconds(float64(i) * windowDuration), formatProb(p)})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("invalid format %q", f)
+	}
+}
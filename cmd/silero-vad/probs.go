@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func runProbs(e *env, args []string) (err error) {
+	fs := newFlagSet(e, "probs", "[input]")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	format := fs.String("format", "text", "output format: text, json or csv")
+	outPath := fs.String("o", "", "output file (defaults to stdout)")
+
+	path, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := validateProbsFormat(*format); err != nil {
+		return err
+	}
+	if err := cfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+
+	in, err := inFlags.read(e, path, cfg.SampleRate)
+	if err != nil {
+		return err
+	}
+
+	sd, err := speech.NewDetector(*cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create detector: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, sd.Destroy())
+	}()
+
+	probs, err := sd.Probabilities(audio.Resample(in.samples, in.sampleRate, cfg.SampleRate))
+	if err != nil {
+		return fmt.Errorf("failed to compute probabilities: %w", err)
+	}
+
+	out, err := openOutput(e, *outPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	return writeProbs(out, *format, probs, float64(sd.WindowSize())/float64(cfg.SampleRate))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/streamer45/silero-vad-go/split"
+)
+
+func runSplit(e *env, args []string) error {
+	fs := newFlagSet(e, "split", "[input]")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	var splitCfg split.Config
+	fs.StringVar(&splitCfg.Dir, "dir", ".", "directory to write the segment files and manifest to")
+	fs.StringVar(&splitCfg.Prefix, "prefix", "segment", "prefix of the segment file names")
+	fs.IntVar(&splitCfg.PaddingMs, "padding-ms", 0, "padding added to each side of the segment files")
+	fs.StringVar(&splitCfg.ManifestName, "manifest", "manifest.jsonl", "name of the JSON lines manifest file")
+	workers := fs.Int("workers", 1, "number of detectors processing the input concurrently")
+
+	path, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := splitCfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+
+	in, segments, err := detect(e, *cfg, inFlags, path, *workers)
+	if err != nil {
+		return err
+	}
+
+	// Segments are cut from the original input to preserve its quality.
+	entries, err := split.WriteSegments(segments, in.samples, in.sampleRate, splitCfg)
+	if err != nil {
+		return fmt.Errorf("failed to split input: %w", err)
+	}
+
+	return split.WriteManifest(e.stdout, entries)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/trim"
+)
+
+func runTrim(e *env, args []string) (err error) {
+	fs := newFlagSet(e, "trim", "[input]")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	var trimCfg trim.Config
+	fs.IntVar(&trimCfg.MaxGapMs, "max-gap-ms", 0, "non-speech gaps longer than this get shortened to -gap-ms")
+	fs.IntVar(&trimCfg.GapMs, "gap-ms", 0, "duration long gaps are shortened to, zero removes them")
+	fs.IntVar(&trimCfg.CrossfadeMs, "crossfade-ms", 0, "duration of the crossfade applied at each join")
+	outPath := fs.String("o", "", "output WAV file (defaults to stdout)")
+	timeMapPath := fs.String("time-map", "", "file to write the JSON time map between output and input to")
+	workers := fs.Int("workers", 1, "number of detectors processing the input concurrently")
+
+	path, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := trimCfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+
+	in, segments, err := detect(e, *cfg, inFlags, path, *workers)
+	if err != nil {
+		return err
+	}
+
+	// Gaps are shortened in the original input to preserve its quality.
+	trimmed, timeMap, err := trim.Apply(segments, in.samples, in.sampleRate, trimCfg)
+	if err != nil {
+		return fmt.Errorf("failed to trim input: %w", err)
+	}
+
+	if *timeMapPath != "" {
+		data, err := json.MarshalIndent(timeMap, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal time map: %w", err)
+		}
+		if err := os.WriteFile(*timeMapPath, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write time map: %w", err)
+		}
+	}
+
+	out, err := openOutput(e, *outPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	if err := audio.WriteWAV(out, trimmed, in.sampleRate); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/tune"
+)
+
+var tuneMethods = map[string]tune.Method{
+	"grid":       tune.GridSearch,
+	"coordinate": tune.CoordinateDescent,
+}
+
+var tuneMetrics = map[string]tune.Metric{
+	"f1":  tune.MetricF1,
+	"der": tune.MetricDetectionErrorRate,
+}
+
+func runTune(e *env, args []string) (err error) {
+	fs := newFlagSet(e, "tune", "<dir>")
+	cfg := addDetectorFlags(fs)
+	inFlags := addInputFlags(fs)
+	tuneCfg := tune.Config{Space: tune.DefaultSpace()}
+	fs.IntVar(&tuneCfg.Eval.FrameMs, "frame-ms", 10, "duration of the scored frames")
+	fs.IntVar(&tuneCfg.Eval.CollarMs, "collar-ms", 0,
+		"duration excluded from scoring on each side of reference boundaries")
+	fs.Var(float32ListValue{&tuneCfg.Space.Thresholds}, "thresholds",
+		"comma separated speech probability thresholds to try")
+	fs.Var(intListValue{&tuneCfg.Space.MinSilenceDurationMs}, "min-silence-durations-ms",
+		"comma separated minimum silence durations to try")
+	fs.Var(intListValue{&tuneCfg.Space.SpeechPadMs}, "speech-pads-ms", "comma separated speech paddings to try")
+	method := fs.String("method", "grid", "search method: grid or coordinate (faster, starting from the detector flags)")
+	metric := fs.String("metric", "f1", "metric to optimize: f1 or der")
+	refFormat := fs.String("ref-format", "rttm",
+		"format of the reference files, named after the audio files: rttm, "+strings.Join(formatNames(), ", "))
+	outPath := fs.String("o", "", "output file for the best configuration (defaults to stdout)")
+
+	dir, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		return usageError{fmt.Errorf("missing dataset directory")}
+	}
+	var ok bool
+	if tuneCfg.Method, ok = tuneMethods[*method]; !ok {
+		return usageError{fmt.Errorf("invalid method %q", *method)}
+	}
+	if tuneCfg.Metric, ok = tuneMetrics[*metric]; !ok {
+		return usageError{fmt.Errorf("invalid metric %q", *metric)}
+	}
+	if err := tuneCfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+	if err := cfg.IsValid(); err != nil {
+		return usageError{err}
+	}
+
+	items, err := findDataset(dir, *refFormat)
+	if err != nil {
+		return err
+	}
+
+	sd, err := speech.NewDetector(*cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create detector: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, sd.Destroy())
+	}()
+
+	// Probabilities don't depend on the tuned settings so they are computed
+	// only once per file.
+	tuneItems := make([]tune.Item, 0, len(items))
+	for _, item := range items {
+		reference, err := readReference(item.refPath, *refFormat, fileID(item.audioPath))
+		if err != nil {
+			return err
+		}
+
+		in, err := inFlags.read(e, item.audioPath, cfg.SampleRate)
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.audioPath, err)
+		}
+
+		if err := sd.Reset(); err != nil {
+			return err
+		}
+		probs, err := sd.Probabilities(audio.Resample(in.samples, in.sampleRate, cfg.SampleRate))
+		if err != nil {
+			return fmt.Errorf("%s: failed to compute probabilities: %w", item.audioPath, err)
+		}
+
+		tuneItems = append(tuneItems, tune.Item{
+			Probs:     probs,
+			Duration:  in.duration(),
+			Reference: reference,
+		})
+	}
+
+	res, err := tune.Tune(*cfg, tuneItems, tuneCfg)
+	if err != nil {
+		return err
+	}
+
+	printTuneSummary(e, res, len(tuneItems))
+
+	out, err := openOutput(e, *outPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tuneOutput{
+		Threshold:            res.Config.Threshold,
+		MinSilenceDurationMs: res.Config.MinSilenceDurationMs,
+		SpeechPadMs:          res.Config.SpeechPadMs,
+	})
+}
+
+// tuneOutput holds the tuned settings, leaving out the ones passed through
+// from flags. Fields are named after the speech.DetectorConfig ones so that
+// the output can be merged into a config.
+type tuneOutput struct {
+	Threshold            float32
+	MinSilenceDurationMs int
+	SpeechPadMs          int
+}
+
+func printTuneSummary(e *env, res tune.Result, files int) {
+	m := newEvalMetrics(res.Eval)
+	fmt.Fprintf(e.stderr, "evaluated %d configurations on %d files\n", res.Evaluated, files)
+	fmt.Fprintf(e.stderr, "best: threshold=%g min-silence-duration-ms=%d speech-pad-ms=%d\n",
+		res.Config.Threshold, res.Config.MinSilenceDurationMs, res.Config.SpeechPadMs)
+	fmt.Fprintf(e.stderr, "precision=%.4f recall=%.4f f1=%.4f false-alarm=%.4f miss=%.4f der=%.4f\n",
+		m.Precision, m.Recall, m.F1, m.FalseAlarmRate, m.MissRate, m.DetectionErrorRate)
+}
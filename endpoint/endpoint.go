@@ -0,0 +1,392 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+type Config struct {
+	// The input audio settings. Probabilities are always enabled.
+	Stream stream.Config
+	// The silence ending an utterance whose last speech was confident.
+	// Defaults to 500ms.
+	ShortTimeout time.Duration
+	// The silence ending an utterance trailing off, i.e. whose last speech
+	// wasn't confident. Defaults to 1.5s.
+	LongTimeout time.Duration
+	// The mean speech probability over the last ConfidenceWindow of speech
+	// above which an utterance is considered confident. Defaults to 0.8.
+	ConfidentProbability float32
+	// The duration of speech, right before silence, whose probabilities are
+	// averaged to tell whether the utterance is confident. Defaults to 300ms.
+	ConfidenceWindow time.Duration
+	// The maximum duration of an utterance, after which it's ended regardless
+	// of silence. Defaults to 15s.
+	MaxUtterance time.Duration
+	// The duration after which, speech not having started, no input is
+	// reported. Defaults to 5s.
+	NoInputTimeout time.Duration
+}
+
+func (c Config) IsValid() error {
+	if err := c.Stream.IsValid(); err != nil {
+		return fmt.Errorf("invalid Stream: %w", err)
+	}
+
+	if c.ShortTimeout < 0 {
+		return fmt.Errorf("invalid ShortTimeout: should be a positive duration")
+	}
+
+	if c.LongTimeout < 0 {
+		return fmt.Errorf("invalid LongTimeout: should be a positive duration")
+	}
+
+	if c.ShortTimeout > 0 && c.LongTimeout > 0 && c.LongTimeout < c.ShortTimeout {
+		return fmt.Errorf("invalid LongTimeout: should be greater than ShortTimeout")
+	}
+
+	if c.ConfidentProbability < 0 || c.ConfidentProbability >= 1 {
+		return fmt.Errorf("invalid ConfidentProbability: should be in range [0, 1)")
+	}
+
+	if c.ConfidenceWindow < 0 {
+		return fmt.Errorf("invalid ConfidenceWindow: should be a positive duration")
+	}
+
+	if c.MaxUtterance < 0 {
+		return fmt.Errorf("invalid MaxUtterance: should be a positive duration")
+	}
+
+	if c.NoInputTimeout < 0 {
+		return fmt.Errorf("invalid NoInputTimeout: should be a positive duration")
+	}
+
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	c.Stream.Probabilities = true
+	if c.ShortTimeout == 0 {
+		c.ShortTimeout = 500 * time.Millisecond
+	}
+	if c.LongTimeout == 0 {
+		c.LongTimeout = max(1500*time.Millisecond, c.ShortTimeout)
+	}
+	if c.ConfidentProbability == 0 {
+		c.ConfidentProbability = 0.8
+	}
+	if c.ConfidenceWindow == 0 {
+		c.ConfidenceWindow = 300 * time.Millisecond
+	}
+	if c.MaxUtterance == 0 {
+		c.MaxUtterance = 15 * time.Second
+	}
+	if c.NoInputTimeout == 0 {
+		c.NoInputTimeout = 5 * time.Second
+	}
+}
+
+type EventType int
+
+const (
+	// EventUtteranceStart is emitted when speech starts.
+	EventUtteranceStart EventType = iota + 1
+	// EventUtteranceEnd is emitted when the utterance is considered over.
+	EventUtteranceEnd
+	// EventNoInput is emitted when speech never started.
+	EventNoInput
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventUtteranceStart:
+		return "utterance_start"
+	case EventUtteranceEnd:
+		return "utterance_end"
+	case EventNoInput:
+		return "no_input"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Reason tells why an utterance ended or no input was reported.
+type Reason int
+
+const (
+	// ReasonSilence is a confident utterance followed by ShortTimeout of silence.
+	ReasonSilence Reason = iota + 1
+	// ReasonTrailingOff is an utterance trailing off followed by LongTimeout
+	// of silence.
+	ReasonTrailingOff
+	// ReasonMaxUtterance is an utterance reaching MaxUtterance.
+	ReasonMaxUtterance
+	// ReasonTimeout is speech not starting within NoInputTimeout.
+	ReasonTimeout
+	// ReasonEndOfStream is the input ending first.
+	ReasonEndOfStream
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonSilence:
+		return "silence"
+	case ReasonTrailingOff:
+		return "trailing_off"
+	case ReasonMaxUtterance:
+		return "max_utterance"
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonEndOfStream:
+		return "end_of_stream"
+	default:
+		return fmt.Sprintf("Reason(%d)", int(r))
+	}
+}
+
+// Event is an endpointing update. Times are in seconds from the beginning of
+// the turn.
+type Event struct {
+	Type EventType
+	// Why the utterance ended or no input was reported.
+	Reason Reason
+	// The start of the utterance, for utterance events.
+	Start float64
+	// The end of the last speech of the utterance, for EventUtteranceEnd.
+	End float64
+	// The time at which the event was decided, i.e. the end of the audio
+	// processed so far.
+	Time float64
+}
+
+// Endpointer detects the end of a user turn for voice assistants. It runs
+// Detector.DetectStream, relying on its start of speech, and times the
+// silences following speech windows to end the utterance sooner when it ended
+// confidently than when it trailed off.
+//
+// A turn is over once EventUtteranceEnd or EventNoInput is emitted, further
+// audio being ignored until Reset. As with stream.Session, the detector isn't
+// owned and shouldn't be used by anything else meanwhile.
+type Endpointer struct {
+	sd      *speech.Detector
+	cfg     Config
+	session *stream.Session
+	// The detector threshold, separating speech from silence windows. It's
+	// refreshed for each write as it may adapt to the noise.
+	threshold float32
+
+	started bool
+	done    bool
+	// The end of the last window processed.
+	now   float64
+	start float64
+	// The end of the last speech window.
+	lastSpeech float64
+	// The probability of the last window, the one triggering speech when it
+	// starts.
+	lastProb float32
+	// The probabilities of the last speech windows, as a ring buffer.
+	recent []float32
+	next   int
+}
+
+func NewEndpointer(sd *speech.Detector, cfg Config) (*Endpointer, error) {
+	if sd == nil {
+		return nil, speech.ErrNilDetector
+	}
+
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.setDefaults()
+
+	e := &Endpointer{
+		sd:  sd,
+		cfg: cfg,
+	}
+	if err := e.newTurn(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Endpointer) newTurn() error {
+	session, err := stream.NewSession(e.sd, e.cfg.Stream)
+	if err != nil {
+		return err
+	}
+
+	windows := max(int(e.cfg.ConfidenceWindow.Seconds()/session.WindowDuration()+0.5), 1)
+	*e = Endpointer{
+		sd:        e.sd,
+		cfg:       e.cfg,
+		session:   session,
+		threshold: e.sd.EffectiveThreshold(),
+		recent:    make([]float32, 0, windows),
+	}
+
+	return nil
+}
+
+// Config returns the endpointer configuration, with defaults applied.
+func (e *Endpointer) Config() Config {
+	return e.cfg
+}
+
+// Done returns whether the turn is over.
+func (e *Endpointer) Done() bool {
+	return e.done
+}
+
+// Reset starts a new turn, resetting the detector. Times start over from zero.
+func (e *Endpointer) Reset() error {
+	if err := e.sd.Reset(); err != nil {
+		return err
+	}
+	return e.newTurn()
+}
+
+// Write decodes data according to the configured encoding and processes the
+// resulting samples.
+func (e *Endpointer) Write(ctx context.Context, data []byte) ([]Event, error) {
+	if e.done {
+		return nil, nil
+	}
+	events, err := e.session.Write(ctx, data)
+	return e.process(events), err
+}
+
+// WriteSamples processes samples at the configured sample rate and returns
+// the resulting events.
+func (e *Endpointer) WriteSamples(ctx context.Context, samples []float32) ([]Event, error) {
+	if e.done {
+		return nil, nil
+	}
+	events, err := e.session.WriteSamples(ctx, samples)
+	return e.process(events), err
+}
+
+// Flush processes the remaining audio and ends the turn, if not over already,
+// with ReasonEndOfStream.
+func (e *Endpointer) Flush(ctx context.Context) ([]Event, error) {
+	if e.done {
+		return nil, nil
+	}
+
+	events, err := e.session.Flush(ctx)
+	out := e.process(events)
+	if err != nil || e.done {
+		return out, err
+	}
+
+	now := e.session.Duration()
+	if e.started {
+		return append(out, e.end(ReasonEndOfStream, now)), nil
+	}
+	e.done = true
+	return append(out, Event{Type: EventNoInput, Reason: ReasonEndOfStream, Time: now}), nil
+}
+
+// process handles the events of the audio just processed by the detector,
+// refreshing the threshold it ended with.
+func (e *Endpointer) process(events []stream.Event) []Event {
+	e.threshold = e.sd.EffectiveThreshold()
+	return e.handle(events)
+}
+
+// handle turns session events into endpointing events.
+func (e *Endpointer) handle(events []stream.Event) []Event {
+	var out []Event
+	for _, ev := range events {
+		if e.done {
+			break
+		}
+
+		switch ev.Type {
+		case stream.EventSpeechStart:
+			if !e.started {
+				e.started = true
+				e.start = ev.Start
+				e.lastSpeech = ev.Start
+				// The probability of the window triggering speech comes
+				// before the start event.
+				e.record(e.lastProb)
+				out = append(out, Event{Type: EventUtteranceStart, Start: ev.Start, Time: e.now})
+			}
+		case stream.EventProbability:
+			if ev, ok := e.window(ev.Time, ev.Probability); ok {
+				out = append(out, ev)
+			}
+		}
+	}
+	return out
+}
+
+// window processes the probability of the window ending at t.
+func (e *Endpointer) window(t float64, prob float32) (Event, bool) {
+	e.now = t
+	e.lastProb = prob
+	if !e.started {
+		if t >= e.cfg.NoInputTimeout.Seconds() {
+			e.done = true
+			return Event{Type: EventNoInput, Reason: ReasonTimeout, Time: t}, true
+		}
+		return Event{}, false
+	}
+
+	if prob >= e.threshold {
+		e.lastSpeech = t
+		e.record(prob)
+	}
+
+	if t-e.start >= e.cfg.MaxUtterance.Seconds() {
+		return e.end(ReasonMaxUtterance, t), true
+	}
+
+	silence := t - e.lastSpeech
+	if e.confident() {
+		if silence >= e.cfg.ShortTimeout.Seconds() {
+			return e.end(ReasonSilence, t), true
+		}
+	} else if silence >= e.cfg.LongTimeout.Seconds() {
+		return e.end(ReasonTrailingOff, t), true
+	}
+
+	return Event{}, false
+}
+
+// record adds the probability of a speech window to recent.
+func (e *Endpointer) record(prob float32) {
+	if len(e.recent) < cap(e.recent) {
+		e.recent = append(e.recent, prob)
+		return
+	}
+	e.recent[e.next] = prob
+	e.next = (e.next + 1) % len(e.recent)
+}
+
+// confident returns whether the last speech windows were confident.
+func (e *Endpointer) confident() bool {
+	if len(e.recent) == 0 {
+		return false
+	}
+	var sum float32
+	for _, p := range e.recent {
+		sum += p
+	}
+	return sum/float32(len(e.recent)) >= e.cfg.ConfidentProbability
+}
+
+func (e *Endpointer) end(reason Reason, t float64) Event {
+	e.done = true
+	end := e.lastSpeech
+	if reason == ReasonMaxUtterance {
+		end = t
+	}
+	return Event{Type: EventUtteranceEnd, Reason: reason, Start: e.start, End: end, Time: t}
+}
@@ -0,0 +1,213 @@
+package endpoint
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{
+		Stream:               stream.Config{SampleRate: 8000},
+		ShortTimeout:         time.Second,
+		LongTimeout:          2 * time.Second,
+		ConfidentProbability: 0.9,
+		ConfidenceWindow:     time.Second,
+		MaxUtterance:         time.Minute,
+		NoInputTimeout:       time.Minute,
+	}.IsValid())
+
+	tcs := []struct {
+		cfg Config
+		err string
+	}{
+		{Config{Stream: stream.Config{SampleRate: -1}}, "invalid Stream: invalid SampleRate: should be a positive number"},
+		{Config{ShortTimeout: -1}, "invalid ShortTimeout: should be a positive duration"},
+		{Config{LongTimeout: -1}, "invalid LongTimeout: should be a positive duration"},
+		{Config{ShortTimeout: 2 * time.Second, LongTimeout: time.Second}, "invalid LongTimeout: should be greater than ShortTimeout"},
+		{Config{ConfidentProbability: -0.1}, "invalid ConfidentProbability: should be in range [0, 1)"},
+		{Config{ConfidentProbability: 1}, "invalid ConfidentProbability: should be in range [0, 1)"},
+		{Config{ConfidenceWindow: -1}, "invalid ConfidenceWindow: should be a positive duration"},
+		{Config{MaxUtterance: -1}, "invalid MaxUtterance: should be a positive duration"},
+		{Config{NoInputTimeout: -1}, "invalid NoInputTimeout: should be a positive duration"},
+	}
+	for _, tc := range tcs {
+		require.EqualError(t, tc.cfg.IsValid(), tc.err)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	require.Equal(t, "utterance_start", EventUtteranceStart.String())
+	require.Equal(t, "utterance_end", EventUtteranceEnd.String())
+	require.Equal(t, "no_input", EventNoInput.String())
+	require.Equal(t, "EventType(0)", EventType(0).String())
+
+	require.Equal(t, "silence", ReasonSilence.String())
+	require.Equal(t, "trailing_off", ReasonTrailingOff.String())
+	require.Equal(t, "max_utterance", ReasonMaxUtterance.String())
+	require.Equal(t, "timeout", ReasonTimeout.String())
+	require.Equal(t, "end_of_stream", ReasonEndOfStream.String())
+	require.Equal(t, "Reason(0)", Reason(0).String())
+}
+
+func TestNewEndpointer(t *testing.T) {
+	_, err := NewEndpointer(nil, Config{})
+	require.ErrorIs(t, err, speech.ErrNilDetector)
+}
+
+func TestEndpointerHandle(t *testing.T) {
+	const window = 0.032
+
+	// windows returns n probability events of value p starting after from
+	// windows.
+	windows := func(from, n int, p float32) []stream.Event {
+		events := make([]stream.Event, n)
+		for i := range events {
+			events[i] = stream.Event{Type: stream.EventProbability, Time: float64(from+i+1) * window, Probability: p}
+		}
+		return events
+	}
+	// utterance returns silence, then speech of probability p with a start
+	// event after its first window, then silence.
+	utterance := func(p float32) []stream.Event {
+		events := windows(0, 10, 0.05)
+		events = append(events, windows(10, 1, p)...)
+		events = append(events, stream.Event{Type: stream.EventSpeechStart, Start: 10 * window})
+		events = append(events, windows(11, 29, p)...)
+		return append(events, windows(40, 100, 0.05)...)
+	}
+
+	tcs := []struct {
+		name     string
+		cfg      Config
+		events   []stream.Event
+		expected []Event
+	}{
+		{
+			name:   "no input",
+			events: windows(0, 200, 0.1),
+			expected: []Event{
+				{Type: EventNoInput, Reason: ReasonTimeout, Time: 157 * window},
+			},
+		},
+		{
+			name:   "confident",
+			events: utterance(0.95),
+			expected: []Event{
+				{Type: EventUtteranceStart, Start: 10 * window, Time: 11 * window},
+				{Type: EventUtteranceEnd, Reason: ReasonSilence, Start: 10 * window, End: 40 * window, Time: 56 * window},
+			},
+		},
+		{
+			name:   "trailing off",
+			events: utterance(0.6),
+			expected: []Event{
+				{Type: EventUtteranceStart, Start: 10 * window, Time: 11 * window},
+				{Type: EventUtteranceEnd, Reason: ReasonTrailingOff, Start: 10 * window, End: 40 * window, Time: 87 * window},
+			},
+		},
+		{
+			// The window triggering speech counts toward confidence.
+			name: "short utterance",
+			events: append(append(append(append(windows(0, 10, 0.05), windows(10, 1, 0.99)...),
+				stream.Event{Type: stream.EventSpeechStart, Start: 10 * window}), windows(11, 1, 0.65)...),
+				windows(12, 100, 0.05)...),
+			expected: []Event{
+				{Type: EventUtteranceStart, Start: 10 * window, Time: 11 * window},
+				{Type: EventUtteranceEnd, Reason: ReasonSilence, Start: 10 * window, End: 12 * window, Time: 28 * window},
+			},
+		},
+		{
+			name:   "max utterance",
+			cfg:    Config{MaxUtterance: 500 * time.Millisecond},
+			events: utterance(0.95),
+			expected: []Event{
+				{Type: EventUtteranceStart, Start: 10 * window, Time: 11 * window},
+				{Type: EventUtteranceEnd, Reason: ReasonMaxUtterance, Start: 10 * window, End: 26 * window, Time: 26 * window},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.setDefaults()
+			e := &Endpointer{
+				cfg:       cfg,
+				threshold: 0.5,
+				recent:    make([]float32, 0, int(cfg.ConfidenceWindow.Seconds()/window+0.5)),
+			}
+
+			events := e.handle(tc.events)
+			require.Len(t, events, len(tc.expected))
+			for i, ev := range events {
+				require.Equal(t, tc.expected[i].Type, ev.Type)
+				require.Equal(t, tc.expected[i].Reason, ev.Reason)
+				require.InDelta(t, tc.expected[i].Start, ev.Start, 1e-9)
+				require.InDelta(t, tc.expected[i].End, ev.End, 1e-9)
+				require.InDelta(t, tc.expected[i].Time, ev.Time, 1e-9)
+			}
+			require.True(t, e.Done())
+			require.Empty(t, e.handle(windows(300, 10, 0.9)))
+		})
+	}
+}
+
+func TestEndpointer(t *testing.T) {
+	sd, err := speech.NewDetector(speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+
+	e, err := NewEndpointer(sd, Config{})
+	require.NoError(t, err)
+	require.True(t, e.Config().Stream.Probabilities)
+
+	ctx := context.Background()
+	var events []Event
+	for i := 0; i < len(samples) && !e.Done(); i += 1600 {
+		evs, err := e.WriteSamples(ctx, samples[i:min(i+1600, len(samples))])
+		require.NoError(t, err)
+		events = append(events, evs...)
+	}
+	evs, err := e.Flush(ctx)
+	require.NoError(t, err)
+	events = append(events, evs...)
+
+	require.Len(t, events, 2)
+	require.Equal(t, EventUtteranceStart, events[0].Type)
+	require.Equal(t, EventUtteranceEnd, events[1].Type)
+	require.Equal(t, events[0].Start, events[1].Start)
+	require.Greater(t, events[1].End, events[1].Start)
+	require.True(t, e.Done())
+
+	evs, err = e.WriteSamples(ctx, samples)
+	require.NoError(t, err)
+	require.Empty(t, evs)
+
+	// A new turn of silence only.
+	require.NoError(t, e.Reset())
+	require.False(t, e.Done())
+	evs, err = e.WriteSamples(ctx, make([]float32, 16000))
+	require.NoError(t, err)
+	require.Empty(t, evs)
+	evs, err = e.Flush(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Event{{Type: EventNoInput, Reason: ReasonEndOfStream, Time: 1}}, evs)
+}
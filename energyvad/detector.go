@@ -0,0 +1,284 @@
+package energyvad
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+// minLevelDb is the level floor in dBFS, avoiding infinities on digital silence.
+const minLevelDb = -100
+
+var (
+	// ErrNotEnoughSamples is returned when the input is shorter than a window.
+	ErrNotEnoughSamples = vad.ErrNotEnoughSamples
+	// ErrInvalidSamplesLength is returned when inferring on a buffer that
+	// doesn't match the window size.
+	ErrInvalidSamplesLength = vad.ErrInvalidSamplesLength
+)
+
+// Config configures the detector. The SampleRate, Threshold,
+// MinSilenceDurationMs and SpeechPadMs fields have the same meaning as in
+// speech.DetectorConfig.
+type Config struct {
+	// The sampling rate of the input audio samples. Supported values are 8000 and 16000.
+	SampleRate int
+	// The probability threshold above which we detect speech. A good default is 0.5.
+	Threshold float32
+	// The duration of silence to wait for each speech segment before separating it.
+	MinSilenceDurationMs int
+	// The padding to add to speech segments to avoid aggressive cutting.
+	SpeechPadMs int
+
+	// The level above the noise floor, in dB, at which a window has a 0.5
+	// speech probability. Defaults to 12.
+	SpeechMarginDb float64
+	// The level in dBFS below which a window is never speech. Defaults to -50.
+	MinLevelDb float64
+	// The range of zero-crossing rates, as fractions of consecutive samples
+	// changing sign, typical of speech. Windows outside of it, such as hum or
+	// hiss, have their probability reduced. Default to 0.01 and 0.4.
+	MinZeroCrossingRate float64
+	MaxZeroCrossingRate float64
+	// The time constant, in milliseconds, of the noise floor rising toward
+	// the level of each window. It falls immediately to quieter levels, so
+	// that it tracks the minimum level, which pauses in speech bring back
+	// down. Defaults to 2000.
+	NoiseAdaptationMs int
+}
+
+func (c Config) IsValid() error {
+	if c.SampleRate != 8000 && c.SampleRate != 16000 {
+		return fmt.Errorf("invalid SampleRate: valid values are 8000 and 16000")
+	}
+
+	if c.Threshold <= 0 || c.Threshold >= 1 {
+		return fmt.Errorf("invalid Threshold: should be in range (0, 1)")
+	}
+
+	if c.MinSilenceDurationMs < 0 {
+		return fmt.Errorf("invalid MinSilenceDurationMs: should be a positive number")
+	}
+
+	if c.SpeechPadMs < 0 {
+		return fmt.Errorf("invalid SpeechPadMs: should be a positive number")
+	}
+
+	if c.SpeechMarginDb < 0 {
+		return fmt.Errorf("invalid SpeechMarginDb: should be a positive number")
+	}
+
+	if c.MinLevelDb > 0 {
+		return fmt.Errorf("invalid MinLevelDb: should be a negative number")
+	}
+
+	if c.MinZeroCrossingRate < 0 || c.MinZeroCrossingRate >= 1 {
+		return fmt.Errorf("invalid MinZeroCrossingRate: should be in range [0, 1)")
+	}
+
+	if c.MaxZeroCrossingRate < 0 || c.MaxZeroCrossingRate > 1 {
+		return fmt.Errorf("invalid MaxZeroCrossingRate: should be in range [0, 1]")
+	}
+
+	if c.MaxZeroCrossingRate != 0 && c.MaxZeroCrossingRate <= c.MinZeroCrossingRate {
+		return fmt.Errorf("invalid MaxZeroCrossingRate: should be greater than MinZeroCrossingRate")
+	}
+
+	if c.NoiseAdaptationMs < 0 {
+		return fmt.Errorf("invalid NoiseAdaptationMs: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	if c.SpeechMarginDb == 0 {
+		c.SpeechMarginDb = 12
+	}
+	if c.MinLevelDb == 0 {
+		c.MinLevelDb = -50
+	}
+	if c.MinZeroCrossingRate == 0 {
+		c.MinZeroCrossingRate = 0.01
+	}
+	if c.MaxZeroCrossingRate == 0 {
+		c.MaxZeroCrossingRate = 0.4
+	}
+	if c.NoiseAdaptationMs == 0 {
+		c.NoiseAdaptationMs = 2000
+	}
+}
+
+// Detector is a lightweight, pure-Go speech detector based on the energy and
+// zero-crossing rate of each window, in the spirit of classic VADs. It's less
+// accurate than the Silero model but has no dependency on ONNX Runtime, and
+// implements the same API so that both can be swapped behind vad.Detector.
+//
+// Each window gets a pseudo speech probability from its level above a running
+// noise floor, which then goes through the same segmentation as
+// speech.Detector. A Detector is not safe for concurrent use.
+type Detector struct {
+	cfg        Config
+	windowSize int
+	seg        vad.Segmenter
+	streamBuf  []float32
+	lastProb   float32
+
+	// The noise floor in dBFS, the level it doesn't fall below, and the rate
+	// at which it rises per window.
+	floorDb    float64
+	minFloorDb float64
+	rise       float64
+}
+
+var _ vad.Detector = (*Detector)(nil)
+
+func NewDetector(cfg Config) (*Detector, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.setDefaults()
+
+	windowSize := vad.WindowSizeForSampleRate(cfg.SampleRate)
+	windowMs := float64(windowSize) * 1000 / float64(cfg.SampleRate)
+
+	d := &Detector{
+		cfg:        cfg,
+		windowSize: windowSize,
+		seg: vad.NewSegmenter(vad.SegmenterConfig{
+			SampleRate:           cfg.SampleRate,
+			WindowSize:           windowSize,
+			MinSilenceDurationMs: cfg.MinSilenceDurationMs,
+			SpeechPadMs:          cfg.SpeechPadMs,
+		}),
+		streamBuf: make([]float32, 0, windowSize),
+		// Starting below MinLevelDb by the margin means any window loud enough
+		// to be speech is considered so until the floor adapts.
+		minFloorDb: cfg.MinLevelDb - cfg.SpeechMarginDb,
+		rise:       min(windowMs/float64(cfg.NoiseAdaptationMs), 1),
+	}
+	d.floorDb = d.minFloorDb
+
+	return d, nil
+}
+
+// Config returns the detector configuration, with defaults applied.
+func (d *Detector) Config() Config {
+	return d.cfg
+}
+
+// WindowSize returns the number of samples of each window.
+func (d *Detector) WindowSize() int {
+	return d.windowSize
+}
+
+// LastProbability returns the speech probability of the last processed window.
+func (d *Detector) LastProbability() float32 {
+	return d.lastProb
+}
+
+// Infer returns the speech probability of a window, advancing the noise floor
+// but not the segmentation state.
+func (d *Detector) Infer(samples []float32) (float32, error) {
+	if len(samples) != d.windowSize {
+		return 0, fmt.Errorf("%w: expected %d, got %d", ErrInvalidSamplesLength, d.windowSize, len(samples))
+	}
+
+	return d.probability(samples), nil
+}
+
+func (d *Detector) Detect(pcm []float32) ([]vad.Segment, error) {
+	if len(pcm) < d.windowSize {
+		return nil, ErrNotEnoughSamples
+	}
+
+	slog.Debug("starting speech detection", slog.Int("samplesLen", len(pcm)))
+
+	var segments []vad.Segment
+	for i := 0; i+d.windowSize <= len(pcm); i += d.windowSize {
+		event, err := d.processWindow(pcm[i : i+d.windowSize])
+		if err != nil {
+			return nil, err
+		}
+		segments = vad.AppendSegments(segments, event)
+	}
+
+	slog.Debug("speech detection done", slog.Int("segmentsLen", len(segments)))
+
+	return segments, nil
+}
+
+// DetectStream processes streaming audio chunks and emits segment updates.
+// It returns a segment when speech starts (SpeechEndAt == 0) and when it ends.
+// Call Reset before switching between Detect and DetectStream.
+func (d *Detector) DetectStream(pcm []float32) ([]vad.Segment, error) {
+	var segments []vad.Segment
+
+	for len(pcm) > 0 {
+		n := min(d.windowSize-len(d.streamBuf), len(pcm))
+		d.streamBuf = append(d.streamBuf, pcm[:n]...)
+		pcm = pcm[n:]
+		if len(d.streamBuf) < d.windowSize {
+			break
+		}
+
+		event, err := d.processWindow(d.streamBuf)
+		if err != nil {
+			return nil, err
+		}
+		segments = vad.AppendUpdates(segments, event)
+		d.streamBuf = d.streamBuf[:0]
+	}
+
+	return segments, nil
+}
+
+// Reset clears the detection state, including the noise floor, so that a new
+// stream can be processed.
+func (d *Detector) Reset() error {
+	d.seg.Reset()
+	d.streamBuf = d.streamBuf[:0]
+	d.lastProb = 0
+	d.floorDb = d.minFloorDb
+
+	return nil
+}
+
+// Destroy is a no-op, as the detector holds no resources outside of the Go
+// heap. It exists to implement vad.Detector.
+func (d *Detector) Destroy() error {
+	return nil
+}
+
+func (d *Detector) processWindow(window []float32) (vad.Event, error) {
+	d.lastProb = d.probability(window)
+	return d.seg.Advance(d.lastProb, d.cfg.Threshold)
+}
+
+// probability scores window against the noise floor, then updates the floor
+// with it.
+func (d *Detector) probability(window []float32) float32 {
+	levelDb := math.Max(audio.ToDecibels(audio.RMS(window)), minLevelDb)
+
+	var prob float64
+	if levelDb >= d.cfg.MinLevelDb {
+		// A logistic curve reaching 0.5 at the margin and 0.95 about 9dB above.
+		prob = 1 / (1 + math.Exp(-(levelDb-d.floorDb-d.cfg.SpeechMarginDb)/3))
+
+		zcr := audio.ZeroCrossingRate(window)
+		if zcr < d.cfg.MinZeroCrossingRate || zcr > d.cfg.MaxZeroCrossingRate {
+			prob /= 4
+		}
+	}
+
+	if levelDb < d.floorDb {
+		d.floorDb = max(levelDb, d.minFloorDb)
+	} else {
+		d.floorDb += (levelDb - d.floorDb) * d.rise
+	}
+
+	return float32(prob)
+}
@@ -0,0 +1,263 @@
+package energyvad
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+// voice returns n samples of a harmonic signal with a voice-like spectrum.
+func voice(sampleRate, n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		t := float64(i) / float64(sampleRate)
+		samples[i] = float32(0.1*math.Sin(2*math.Pi*150*t) +
+			0.1*math.Sin(2*math.Pi*450*t) +
+			0.05*math.Sin(2*math.Pi*1200*t))
+	}
+	return samples
+}
+
+func noise(amplitude float64, n int) []float32 {
+	rnd := rand.New(rand.NewSource(1))
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(amplitude * (2*rnd.Float64() - 1))
+	}
+	return samples
+}
+
+func concat(parts ...[]float32) []float32 {
+	var samples []float32
+	for _, part := range parts {
+		samples = append(samples, part...)
+	}
+	return samples
+}
+
+func scale(samples []float32, gain float32) []float32 {
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return samples
+}
+
+func requireSegments(t *testing.T, expected, actual []vad.Segment) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		// Boundaries are accurate to a window.
+		require.InDelta(t, expected[i].SpeechStartAt, actual[i].SpeechStartAt, 0.033, "segment %d", i)
+		require.InDelta(t, expected[i].SpeechEndAt, actual[i].SpeechEndAt, 0.033, "segment %d", i)
+	}
+}
+
+func TestConfigIsValid(t *testing.T) {
+	tcs := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "valid",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5},
+		},
+		{
+			name: "invalid SampleRate",
+			cfg:  Config{SampleRate: 44100, Threshold: 0.5},
+			err:  "invalid SampleRate: valid values are 8000 and 16000",
+		},
+		{
+			name: "invalid Threshold",
+			cfg:  Config{SampleRate: 16000},
+			err:  "invalid Threshold: should be in range (0, 1)",
+		},
+		{
+			name: "invalid MinSilenceDurationMs",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, MinSilenceDurationMs: -1},
+			err:  "invalid MinSilenceDurationMs: should be a positive number",
+		},
+		{
+			name: "invalid SpeechPadMs",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, SpeechPadMs: -1},
+			err:  "invalid SpeechPadMs: should be a positive number",
+		},
+		{
+			name: "invalid SpeechMarginDb",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, SpeechMarginDb: -1},
+			err:  "invalid SpeechMarginDb: should be a positive number",
+		},
+		{
+			name: "invalid MinLevelDb",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, MinLevelDb: 10},
+			err:  "invalid MinLevelDb: should be a negative number",
+		},
+		{
+			name: "invalid MinZeroCrossingRate",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, MinZeroCrossingRate: 1},
+			err:  "invalid MinZeroCrossingRate: should be in range [0, 1)",
+		},
+		{
+			name: "invalid MaxZeroCrossingRate",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, MaxZeroCrossingRate: 1.5},
+			err:  "invalid MaxZeroCrossingRate: should be in range [0, 1]",
+		},
+		{
+			name: "invalid zero-crossing range",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, MinZeroCrossingRate: 0.3, MaxZeroCrossingRate: 0.2},
+			err:  "invalid MaxZeroCrossingRate: should be greater than MinZeroCrossingRate",
+		},
+		{
+			name: "invalid NoiseAdaptationMs",
+			cfg:  Config{SampleRate: 16000, Threshold: 0.5, NoiseAdaptationMs: -1},
+			err:  "invalid NoiseAdaptationMs: should be a positive number",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.IsValid()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	_, err := NewDetector(Config{SampleRate: 16000})
+	require.EqualError(t, err, "invalid config: invalid Threshold: should be in range (0, 1)")
+
+	d, err := NewDetector(Config{SampleRate: 8000, Threshold: 0.5})
+	require.NoError(t, err)
+	require.Equal(t, 256, d.WindowSize())
+	require.Equal(t, Config{
+		SampleRate:          8000,
+		Threshold:           0.5,
+		SpeechMarginDb:      12,
+		MinLevelDb:          -50,
+		MinZeroCrossingRate: 0.01,
+		MaxZeroCrossingRate: 0.4,
+		NoiseAdaptationMs:   2000,
+	}, d.Config())
+	require.NoError(t, d.Destroy())
+}
+
+func TestDetect(t *testing.T) {
+	const sampleRate = 16000
+
+	cfg := Config{
+		SampleRate:           sampleRate,
+		Threshold:            0.5,
+		MinSilenceDurationMs: 100,
+	}
+
+	silence := func(seconds float64) []float32 {
+		return make([]float32, int(seconds*sampleRate))
+	}
+	speech := func(seconds float64) []float32 {
+		return voice(sampleRate, int(seconds*sampleRate))
+	}
+
+	tcs := []struct {
+		name     string
+		pcm      []float32
+		expected []vad.Segment
+	}{
+		{
+			name: "silence",
+			pcm:  silence(2),
+		},
+		{
+			name: "speech",
+			pcm:  concat(silence(0.5), speech(1), silence(1), speech(0.5)),
+			expected: []vad.Segment{
+				{SpeechStartAt: 0.48, SpeechEndAt: 1.536},
+				{SpeechStartAt: 2.496},
+			},
+		},
+		{
+			name: "quiet speech",
+			pcm:  concat(silence(0.5), scale(speech(1), 0.001), silence(0.5)),
+		},
+		{
+			name: "hiss",
+			pcm:  noise(0.2, 2*sampleRate),
+		},
+		{
+			// A stationary sound ends up being part of the noise floor.
+			name: "stationary",
+			pcm:  concat(silence(0.5), speech(10)),
+			expected: []vad.Segment{
+				{SpeechStartAt: 0.48, SpeechEndAt: 3.36},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := NewDetector(cfg)
+			require.NoError(t, err)
+
+			segments, err := d.Detect(tc.pcm)
+			require.NoError(t, err)
+			requireSegments(t, tc.expected, segments)
+
+			// Streaming detection in uneven chunks reports the same segments.
+			require.NoError(t, d.Reset())
+			var updates []vad.Segment
+			for i := 0; i < len(tc.pcm); i += 1000 {
+				chunk, err := d.DetectStream(tc.pcm[i:min(i+1000, len(tc.pcm))])
+				require.NoError(t, err)
+				updates = append(updates, chunk...)
+			}
+			var streamed []vad.Segment
+			for _, seg := range updates {
+				if seg.SpeechEndAt != 0 {
+					streamed[len(streamed)-1] = seg
+					continue
+				}
+				streamed = append(streamed, seg)
+			}
+			require.Equal(t, segments, streamed)
+		})
+	}
+
+	t.Run("not enough samples", func(t *testing.T) {
+		d, err := NewDetector(cfg)
+		require.NoError(t, err)
+
+		_, err = d.Detect(make([]float32, 100))
+		require.ErrorIs(t, err, ErrNotEnoughSamples)
+		require.ErrorIs(t, err, vad.ErrNotEnoughSamples)
+	})
+}
+
+func TestInfer(t *testing.T) {
+	d, err := NewDetector(Config{SampleRate: 16000, Threshold: 0.5})
+	require.NoError(t, err)
+
+	_, err = d.Infer(make([]float32, 100))
+	require.EqualError(t, err, "invalid samples length: expected 512, got 100")
+	require.ErrorIs(t, err, ErrInvalidSamplesLength)
+	require.ErrorIs(t, err, vad.ErrInvalidSamplesLength)
+
+	prob, err := d.Infer(make([]float32, 512))
+	require.NoError(t, err)
+	require.Zero(t, prob)
+
+	prob, err = d.Infer(voice(16000, 512))
+	require.NoError(t, err)
+	require.Greater(t, prob, float32(0.9))
+
+	// White noise crosses zero too often to be speech.
+	prob, err = d.Infer(noise(0.2, 512))
+	require.NoError(t, err)
+	require.Less(t, prob, float32(0.5))
+}
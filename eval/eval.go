@@ -0,0 +1,181 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+type Config struct {
+	// The duration of the frames speech is scored on. Defaults to 10ms.
+	FrameMs int
+	// The duration excluded from scoring on each side of every reference
+	// segment boundary, to forgive small misalignments of the labels.
+	CollarMs int
+}
+
+func (c Config) IsValid() error {
+	if c.FrameMs < 0 {
+		return fmt.Errorf("invalid FrameMs: should be a positive number")
+	}
+
+	if c.CollarMs < 0 {
+		return fmt.Errorf("invalid CollarMs: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c Config) frameDuration() float64 {
+	if c.FrameMs == 0 {
+		return 0.01
+	}
+	return float64(c.FrameMs) / 1000
+}
+
+// Result holds the scored durations, in seconds, of a hypothesis against a
+// reference. Results from different inputs can be combined through Add.
+type Result struct {
+	// Speech in both the reference and the hypothesis.
+	TruePositive float64 `json:"true_positive"`
+	// Speech in the hypothesis only (false alarm).
+	FalsePositive float64 `json:"false_positive"`
+	// Speech in the reference only (miss).
+	FalseNegative float64 `json:"false_negative"`
+	// Non-speech in both the reference and the hypothesis.
+	TrueNegative float64 `json:"true_negative"`
+	// Excluded from scoring because of the collar.
+	Excluded float64 `json:"excluded"`
+}
+
+// Add returns the sum of both results.
+func (r Result) Add(other Result) Result {
+	return Result{
+		TruePositive:  r.TruePositive + other.TruePositive,
+		FalsePositive: r.FalsePositive + other.FalsePositive,
+		FalseNegative: r.FalseNegative + other.FalseNegative,
+		TrueNegative:  r.TrueNegative + other.TrueNegative,
+		Excluded:      r.Excluded + other.Excluded,
+	}
+}
+
+func ratio(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// Precision is the fraction of hypothesis speech which is reference speech.
+func (r Result) Precision() float64 {
+	return ratio(r.TruePositive, r.TruePositive+r.FalsePositive)
+}
+
+// Recall is the fraction of reference speech found by the hypothesis.
+func (r Result) Recall() float64 {
+	return ratio(r.TruePositive, r.TruePositive+r.FalseNegative)
+}
+
+// F1 is the harmonic mean of precision and recall.
+func (r Result) F1() float64 {
+	return ratio(2*r.TruePositive, 2*r.TruePositive+r.FalsePositive+r.FalseNegative)
+}
+
+// FalseAlarmRate is the fraction of reference non-speech detected as speech.
+func (r Result) FalseAlarmRate() float64 {
+	return ratio(r.FalsePositive, r.FalsePositive+r.TrueNegative)
+}
+
+// MissRate is the fraction of reference speech not detected.
+func (r Result) MissRate() float64 {
+	return ratio(r.FalseNegative, r.FalseNegative+r.TruePositive)
+}
+
+// DetectionErrorRate is the duration of false alarms and misses relative to
+// the duration of reference speech. It can exceed 1.
+func (r Result) DetectionErrorRate() float64 {
+	return ratio(r.FalsePositive+r.FalseNegative, r.TruePositive+r.FalseNegative)
+}
+
+// Evaluate scores the hypothesis segments against the reference ones over an
+// input of the given duration in seconds. Each frame is labeled as speech if
+// its center falls within a segment. Segments can overlap and a trailing
+// hypothesis segment still open at the end of the input extends up to it,
+// while reference segments are taken literally.
+func Evaluate(reference, hypothesis []vad.Segment, duration float64, cfg Config) (Result, error) {
+	if err := cfg.IsValid(); err != nil {
+		return Result{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if duration < 0 {
+		return Result{}, fmt.Errorf("invalid duration: should be a positive number")
+	}
+
+	frameDuration := cfg.frameDuration()
+	numFrames := int(math.Round(duration / frameDuration))
+
+	ref := frameLabels(reference, numFrames, frameDuration, false)
+	hyp := frameLabels(hypothesis, numFrames, frameDuration, true)
+	excluded := collarFrames(reference, numFrames, frameDuration, float64(cfg.CollarMs)/1000)
+
+	var res Result
+	for i := 0; i < numFrames; i++ {
+		switch {
+		case excluded[i]:
+			res.Excluded += frameDuration
+		case ref[i] && hyp[i]:
+			res.TruePositive += frameDuration
+		case hyp[i]:
+			res.FalsePositive += frameDuration
+		case ref[i]:
+			res.FalseNegative += frameDuration
+		default:
+			res.TrueNegative += frameDuration
+		}
+	}
+
+	return res, nil
+}
+
+// frameRange returns the range of frames, [first, last), whose centers fall
+// within [start, end).
+func frameRange(start, end float64, numFrames int, frameDuration float64) (int, int) {
+	first := max(int(math.Ceil(start/frameDuration-0.5)), 0)
+	last := min(int(math.Ceil(end/frameDuration-0.5)), numFrames)
+	return first, last
+}
+
+// frameLabels returns whether each frame is speech according to segments.
+// When open is set, segments ending at zero are open and extend up to the end
+// of the input, as returned by detection, otherwise they're taken literally.
+func frameLabels(segments []vad.Segment, numFrames int, frameDuration float64, open bool) []bool {
+	labels := make([]bool, numFrames)
+	for _, seg := range segments {
+		end := seg.SpeechEndAt
+		if open && end == 0 {
+			end = float64(numFrames) * frameDuration
+		}
+		first, last := frameRange(seg.SpeechStartAt, end, numFrames, frameDuration)
+		for i := first; i < last; i++ {
+			labels[i] = true
+		}
+	}
+	return labels
+}
+
+func collarFrames(segments []vad.Segment, numFrames int, frameDuration, collar float64) []bool {
+	excluded := make([]bool, numFrames)
+	if collar == 0 {
+		return excluded
+	}
+	for _, seg := range segments {
+		for _, boundary := range []float64{seg.SpeechStartAt, seg.SpeechEndAt} {
+			first, last := frameRange(boundary-collar, boundary+collar, numFrames, frameDuration)
+			for i := first; i < last; i++ {
+				excluded[i] = true
+			}
+		}
+	}
+	return excluded
+}
@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{FrameMs: 20, CollarMs: 250}.IsValid())
+	require.EqualError(t, Config{FrameMs: -1}.IsValid(), "invalid FrameMs: should be a positive number")
+	require.EqualError(t, Config{CollarMs: -1}.IsValid(), "invalid CollarMs: should be a positive number")
+}
+
+func requireResult(t *testing.T, expected, actual Result) {
+	t.Helper()
+	require.InDelta(t, expected.TruePositive, actual.TruePositive, 1e-9)
+	require.InDelta(t, expected.FalsePositive, actual.FalsePositive, 1e-9)
+	require.InDelta(t, expected.FalseNegative, actual.FalseNegative, 1e-9)
+	require.InDelta(t, expected.TrueNegative, actual.TrueNegative, 1e-9)
+	require.InDelta(t, expected.Excluded, actual.Excluded, 1e-9)
+}
+
+func TestEvaluate(t *testing.T) {
+	reference := []vad.Segment{
+		{SpeechStartAt: 1, SpeechEndAt: 3},
+		{SpeechStartAt: 5, SpeechEndAt: 6},
+	}
+
+	t.Run("perfect", func(t *testing.T) {
+		res, err := Evaluate(reference, reference, 10, Config{})
+		require.NoError(t, err)
+		requireResult(t, Result{TruePositive: 3, TrueNegative: 7}, res)
+		require.Equal(t, 1.0, res.Precision())
+		require.Equal(t, 1.0, res.Recall())
+		require.Equal(t, 1.0, res.F1())
+		require.Zero(t, res.FalseAlarmRate())
+		require.Zero(t, res.MissRate())
+		require.Zero(t, res.DetectionErrorRate())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		hypothesis := []vad.Segment{
+			{SpeechStartAt: 1.5, SpeechEndAt: 3.5},
+			// Open segments extend up to the end of the input.
+			{SpeechStartAt: 9},
+		}
+		res, err := Evaluate(reference, hypothesis, 10, Config{})
+		require.NoError(t, err)
+		requireResult(t, Result{TruePositive: 1.5, FalsePositive: 1.5, FalseNegative: 1.5, TrueNegative: 5.5}, res)
+		require.InDelta(t, 0.5, res.Precision(), 1e-9)
+		require.InDelta(t, 0.5, res.Recall(), 1e-9)
+		require.InDelta(t, 0.5, res.F1(), 1e-9)
+		require.InDelta(t, 1.5/7, res.FalseAlarmRate(), 1e-9)
+		require.InDelta(t, 0.5, res.MissRate(), 1e-9)
+		require.InDelta(t, 1, res.DetectionErrorRate(), 1e-9)
+	})
+
+	t.Run("literal reference", func(t *testing.T) {
+		// Reference segments ending at zero aren't open, unlike hypothesis ones.
+		reference := []vad.Segment{
+			{SpeechStartAt: 0, SpeechEndAt: 0},
+			{SpeechStartAt: 1, SpeechEndAt: 3},
+		}
+		res, err := Evaluate(reference, reference, 10, Config{})
+		require.NoError(t, err)
+		requireResult(t, Result{TruePositive: 2, FalsePositive: 8}, res)
+
+		res, err = Evaluate(reference, reference[1:], 10, Config{})
+		require.NoError(t, err)
+		requireResult(t, Result{TruePositive: 2, TrueNegative: 8}, res)
+	})
+
+	t.Run("collar", func(t *testing.T) {
+		hypothesis := []vad.Segment{
+			{SpeechStartAt: 1.2, SpeechEndAt: 2.9},
+			{SpeechStartAt: 5, SpeechEndAt: 6},
+		}
+		res, err := Evaluate(reference, hypothesis, 10, Config{CollarMs: 250})
+		require.NoError(t, err)
+		// Each of the 4 boundaries excludes 500ms around it.
+		requireResult(t, Result{TruePositive: 2, TrueNegative: 6, Excluded: 2}, res)
+	})
+
+	t.Run("overlapping reference", func(t *testing.T) {
+		overlapping := []vad.Segment{
+			{SpeechStartAt: 1, SpeechEndAt: 2.5},
+			{SpeechStartAt: 2, SpeechEndAt: 3},
+		}
+		res, err := Evaluate(overlapping, reference[:1], 4, Config{FrameMs: 20})
+		require.NoError(t, err)
+		requireResult(t, Result{TruePositive: 2, TrueNegative: 2}, res)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		res, err := Evaluate(nil, nil, 0, Config{})
+		require.NoError(t, err)
+		require.Zero(t, res)
+		require.Zero(t, res.F1())
+		require.Zero(t, res.DetectionErrorRate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Evaluate(reference, reference, -1, Config{})
+		require.EqualError(t, err, "invalid duration: should be a positive number")
+
+		_, err = Evaluate(reference, reference, 10, Config{FrameMs: -1})
+		require.EqualError(t, err, "invalid config: invalid FrameMs: should be a positive number")
+	})
+}
+
+func TestResultAdd(t *testing.T) {
+	a := Result{TruePositive: 1, FalsePositive: 2, FalseNegative: 3, TrueNegative: 4, Excluded: 5}
+	b := Result{TruePositive: 5, FalsePositive: 4, FalseNegative: 3, TrueNegative: 2, Excluded: 1}
+	require.Equal(t, Result{TruePositive: 6, FalsePositive: 6, FalseNegative: 6, TrueNegative: 6, Excluded: 6}, a.Add(b))
+}
@@ -0,0 +1,51 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+func encodeAudacity(w io.Writer, segments []vad.Segment) error {
+	bw := bufio.NewWriter(w)
+	for _, seg := range segments {
+		fmt.Fprintf(bw, "%.6f\t%.6f\t%s\n", seg.SpeechStartAt, seg.SpeechEndAt, speechLabel)
+	}
+	return bw.Flush()
+}
+
+func decodeAudacity(r io.Reader) ([]vad.Segment, error) {
+	var segments []vad.Segment
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		// Spectral selection lines, starting with a backslash, add a
+		// frequency range to the preceding label.
+		if fields[0] == `\` {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected start and end fields", line)
+		}
+
+		seg, err := parseSegment(fields[0], fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		segments = append(segments, seg)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	return segments, nil
+}
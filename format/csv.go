@@ -0,0 +1,56 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+func encodeCSV(w io.Writer, segments []vad.Segment) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"start", "end"})
+	for _, seg := range segments {
+		_ = cw.Write([]string{formatSeconds(seg.SpeechStartAt), formatSeconds(seg.SpeechEndAt)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func decodeCSV(r io.Reader) ([]vad.Segment, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	var segments []vad.Segment
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected start and end columns", i+1)
+		}
+		if i == 0 && record[0] == "start" {
+			continue
+		}
+
+		seg, err := parseSegment(record[0], record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		segments = append(segments, seg)
+	}
+
+	return segments, nil
+}
+
+func parseSegment(start, end string) (vad.Segment, error) {
+	startAt, err := parseSeconds(start)
+	if err != nil {
+		return vad.Segment{}, err
+	}
+	endAt, err := parseSeconds(end)
+	if err != nil {
+		return vad.Segment{}, err
+	}
+	return vad.Segment{SpeechStartAt: startAt, SpeechEndAt: endAt}, nil
+}
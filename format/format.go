@@ -0,0 +1,129 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+// The label given to speech segments in formats requiring one.
+const speechLabel = "speech"
+
+// Format identifies a segments encoding.
+type Format string
+
+const (
+	// Audacity is the Audacity label track format.
+	Audacity Format = "audacity"
+	// WebVTT is the WebVTT subtitle format.
+	WebVTT Format = "vtt"
+	// SRT is the SubRip subtitle format.
+	SRT Format = "srt"
+	// TextGrid is the Praat TextGrid format, in its long text variant.
+	TextGrid Format = "textgrid"
+	// CSV is comma separated start and end times with a header row.
+	CSV Format = "csv"
+	// JSON is an array of objects with start and end times.
+	JSON Format = "json"
+)
+
+// Formats lists all the supported formats.
+var Formats = []Format{Audacity, WebVTT, SRT, TextGrid, CSV, JSON}
+
+// ParseFormat parses a format from its name (e.g. "srt").
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format %q", s)
+}
+
+// Encode writes the segments to w in the given format. Segments should be
+// closed, with open segments ended first (e.g. at the input duration).
+func Encode(w io.Writer, f Format, segments []vad.Segment) error {
+	for i, seg := range segments {
+		if seg.SpeechStartAt < 0 || seg.SpeechEndAt < seg.SpeechStartAt {
+			return fmt.Errorf("invalid segment %d: should be closed and not end before it starts", i)
+		}
+	}
+
+	switch f {
+	case Audacity:
+		return encodeAudacity(w, segments)
+	case WebVTT:
+		return encodeSubtitles(w, segments, true)
+	case SRT:
+		return encodeSubtitles(w, segments, false)
+	case TextGrid:
+		return encodeTextGrid(w, segments)
+	case CSV:
+		return encodeCSV(w, segments)
+	case JSON:
+		return encodeJSON(w, segments)
+	default:
+		return fmt.Errorf("invalid format %q", f)
+	}
+}
+
+// Decode reads segments from r in the given format.
+func Decode(r io.Reader, f Format) ([]vad.Segment, error) {
+	switch f {
+	case Audacity:
+		return decodeAudacity(r)
+	case WebVTT:
+		return decodeSubtitles(r, true)
+	case SRT:
+		return decodeSubtitles(r, false)
+	case TextGrid:
+		return decodeTextGrid(r)
+	case CSV:
+		return decodeCSV(r)
+	case JSON:
+		return decodeJSON(r)
+	default:
+		return nil, fmt.Errorf("invalid format %q", f)
+	}
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func parseSeconds(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return v, nil
+}
+
+type segmentJSON struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+func encodeJSON(w io.Writer, segments []vad.Segment) error {
+	out := make([]segmentJSON, 0, len(segments))
+	for _, seg := range segments {
+		out = append(out, segmentJSON{Start: seg.SpeechStartAt, End: seg.SpeechEndAt})
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+func decodeJSON(r io.Reader) ([]vad.Segment, error) {
+	var in []segmentJSON
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	segments := make([]vad.Segment, 0, len(in))
+	for _, seg := range in {
+		segments = append(segments, vad.Segment{SpeechStartAt: seg.Start, SpeechEndAt: seg.End})
+	}
+	return segments, nil
+}
@@ -0,0 +1,288 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+var testSegments = []vad.Segment{
+	{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+	{SpeechStartAt: 2.88, SpeechEndAt: 3.232},
+	{SpeechStartAt: 4.448, SpeechEndAt: 5.5},
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		parsed, err := ParseFormat(string(f))
+		require.NoError(t, err)
+		require.Equal(t, f, parsed)
+	}
+
+	_, err := ParseFormat("xml")
+	require.EqualError(t, err, `invalid format "xml"`)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, f := range Formats {
+		t.Run(string(f), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, f, testSegments))
+
+			segments, err := Decode(&buf, f)
+			require.NoError(t, err)
+			require.Equal(t, testSegments, segments)
+		})
+
+		t.Run(string(f)+" empty", func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, f, nil))
+
+			segments, err := Decode(&buf, f)
+			require.NoError(t, err)
+			require.Empty(t, segments)
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tcs := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: Audacity,
+			expected: "1.056000\t1.632000\tspeech\n" +
+				"2.880000\t3.232000\tspeech\n" +
+				"4.448000\t5.500000\tspeech\n",
+		},
+		{
+			format: WebVTT,
+			expected: "WEBVTT\n\n" +
+				"1\n00:00:01.056 --> 00:00:01.632\nspeech\n\n" +
+				"2\n00:00:02.880 --> 00:00:03.232\nspeech\n\n" +
+				"3\n00:00:04.448 --> 00:00:05.500\nspeech\n\n",
+		},
+		{
+			format: SRT,
+			expected: "1\n00:00:01,056 --> 00:00:01,632\nspeech\n\n" +
+				"2\n00:00:02,880 --> 00:00:03,232\nspeech\n\n" +
+				"3\n00:00:04,448 --> 00:00:05,500\nspeech\n\n",
+		},
+		{
+			format:   CSV,
+			expected: "start,end\n1.056,1.632\n2.88,3.232\n4.448,5.5\n",
+		},
+		{
+			format:   JSON,
+			expected: `[{"start":1.056,"end":1.632},{"start":2.88,"end":3.232},{"start":4.448,"end":5.5}]` + "\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, tc.format, testSegments))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("textgrid", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, TextGrid, testSegments[:2]))
+		require.Equal(t, `File type = "ooTextFile"
+Object class = "TextGrid"
+
+xmin = 0
+xmax = 3.232
+tiers? <exists>
+size = 1
+item []:
+    item [1]:
+        class = "IntervalTier"
+        name = "speech"
+        xmin = 0
+        xmax = 3.232
+        intervals: size = 4
+        intervals [1]:
+            xmin = 0
+            xmax = 1.056
+            text = ""
+        intervals [2]:
+            xmin = 1.056
+            xmax = 1.632
+            text = "speech"
+        intervals [3]:
+            xmin = 1.632
+            xmax = 2.88
+            text = ""
+        intervals [4]:
+            xmin = 2.88
+            xmax = 3.232
+            text = "speech"
+`, buf.String())
+	})
+
+	t.Run("long timestamps", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, SRT, []vad.Segment{{SpeechStartAt: 3723.4567, SpeechEndAt: 3724}}))
+		require.Equal(t, "1\n01:02:03,457 --> 01:02:04,000\nspeech\n\n", buf.String())
+	})
+
+	t.Run("open segment", func(t *testing.T) {
+		err := Encode(&bytes.Buffer{}, CSV, []vad.Segment{{SpeechStartAt: 1}})
+		require.EqualError(t, err, "invalid segment 0: should be closed and not end before it starts")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		err := Encode(&bytes.Buffer{}, "xml", testSegments)
+		require.EqualError(t, err, `invalid format "xml"`)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("audacity with spectral selection", func(t *testing.T) {
+		segments, err := Decode(strings.NewReader("1.5\t2.5\tfoo\n\\\t100.0\t2000.0\n3\t4\n"), Audacity)
+		require.NoError(t, err)
+		require.Equal(t, []vad.Segment{
+			{SpeechStartAt: 1.5, SpeechEndAt: 2.5},
+			{SpeechStartAt: 3, SpeechEndAt: 4},
+		}, segments)
+	})
+
+	t.Run("webvtt with settings and notes", func(t *testing.T) {
+		input := "\ufeffWEBVTT - Some title\n\nNOTE a comment\n\n" +
+			"intro\n01:02.500 --> 01:03.000 align:start\nhello\n\n" +
+			"00:01:04.000 --> 00:01:05.250\nworld\n"
+		segments, err := Decode(strings.NewReader(input), WebVTT)
+		require.NoError(t, err)
+		require.Equal(t, []vad.Segment{
+			{SpeechStartAt: 62.5, SpeechEndAt: 63},
+			{SpeechStartAt: 64, SpeechEndAt: 65.25},
+		}, segments)
+	})
+
+	t.Run("textgrid with multiple tiers", func(t *testing.T) {
+		input := `File type = "ooTextFile"
+Object class = "TextGrid"
+
+xmin = 0
+xmax = 3
+tiers? <exists>
+size = 2
+item []:
+    item [1]:
+        class = "TextTier"
+        name = "points"
+        xmin = 0
+        xmax = 3
+        points: size = 1
+        points [1]:
+            number = 1
+            mark = "x"
+    item [2]:
+        class = "IntervalTier"
+        name = "words"
+        xmin = 0
+        xmax = 3
+        intervals: size = 3
+        intervals [1]:
+            xmin = 0
+            xmax = 1
+            text = ""
+        intervals [2]:
+            xmin = 1
+            xmax = 2.5
+            text = "hello"
+        intervals [3]:
+            xmin = 2.5
+            xmax = 3
+            text = " "
+`
+		segments, err := Decode(strings.NewReader(input), TextGrid)
+		require.NoError(t, err)
+		require.Equal(t, []vad.Segment{{SpeechStartAt: 1, SpeechEndAt: 2.5}}, segments)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tcs := []struct {
+			name   string
+			format Format
+			input  string
+			err    string
+		}{
+			{name: "csv missing column", format: CSV, input: "1\n", err: "line 1: expected start and end columns"},
+			{name: "csv invalid time", format: CSV, input: "start,end\n1,abc\n", err: `line 2: invalid time "abc"`},
+			{name: "audacity missing field", format: Audacity, input: "1.5\n", err: "line 1: expected start and end fields"},
+			{name: "vtt missing header", format: WebVTT, input: "1\n00:00.000 --> 00:01.000\n", err: "missing WEBVTT header"},
+			{name: "srt invalid timestamp", format: SRT, input: "1\n00:00:1x,000 --> 00:00:02,000\n", err: `line 2: invalid timestamp "00:00:1x,000"`},
+			{name: "srt missing end", format: SRT, input: "1\n00:00:01,000 -->\n", err: "line 2: missing end timestamp"},
+			{name: "textgrid missing tiers", format: TextGrid, input: "File type = \"ooTextFile\"\n", err: "missing tiers"},
+			{name: "invalid format", format: "xml", err: `invalid format "xml"`},
+		}
+
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := Decode(strings.NewReader(tc.input), tc.format)
+				require.EqualError(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestRTTM(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteRTTM(&buf, "rec1", testSegments))
+		require.Equal(t, "SPEAKER rec1 1 1.056 0.576 <NA> <NA> speech <NA> <NA>\n"+
+			"SPEAKER rec1 1 2.880 0.352 <NA> <NA> speech <NA> <NA>\n"+
+			"SPEAKER rec1 1 4.448 1.052 <NA> <NA> speech <NA> <NA>\n", buf.String())
+
+		files, err := ReadRTTM(&buf)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		require.Len(t, files["rec1"], len(testSegments))
+		for i, seg := range files["rec1"] {
+			require.InDelta(t, testSegments[i].SpeechStartAt, seg.SpeechStartAt, 1e-9)
+			require.InDelta(t, testSegments[i].SpeechEndAt, seg.SpeechEndAt, 1e-9)
+		}
+	})
+
+	t.Run("multiple files and speakers", func(t *testing.T) {
+		input := ";; comment\n" +
+			"SPKR-INFO rec1 1 <NA> <NA> <NA> unknown alice <NA>\n" +
+			"SPEAKER rec1 1 5.0 1.5 <NA> <NA> bob <NA> <NA>\n" +
+			"SPEAKER rec1 1 1.0 2.0 <NA> <NA> alice <NA> <NA>\n" +
+			"SPEAKER rec2 1 0.5 0.25 <NA> <NA> alice <NA> <NA>\n"
+
+		files, err := ReadRTTM(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Equal(t, map[string][]vad.Segment{
+			"rec1": {
+				{SpeechStartAt: 1, SpeechEndAt: 3},
+				{SpeechStartAt: 5, SpeechEndAt: 6.5},
+			},
+			"rec2": {
+				{SpeechStartAt: 0.5, SpeechEndAt: 0.75},
+			},
+		}, files)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ReadRTTM(strings.NewReader("SPEAKER rec1 1 1.0\n"))
+		require.EqualError(t, err, "line 1: expected at least 5 fields, got 4")
+
+		_, err = ReadRTTM(strings.NewReader("SPEAKER rec1 1 1.0 x <NA> <NA> a <NA> <NA>\n"))
+		require.EqualError(t, err, `line 1: invalid time "x"`)
+
+		_, err = ReadRTTM(strings.NewReader("SPEAKER rec1 1 1.0 -1 <NA> <NA> a <NA> <NA>\n"))
+		require.EqualError(t, err, "line 1: negative time")
+
+		err = WriteRTTM(&bytes.Buffer{}, "my file", testSegments)
+		require.EqualError(t, err, `invalid file ID "my file": should be non-empty and not contain whitespace`)
+	})
+}
@@ -0,0 +1,76 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+// WriteRTTM writes the segments to w as RTTM SPEAKER records for the given
+// file ID, all attributed to the same "speech" speaker on channel 1.
+func WriteRTTM(w io.Writer, fileID string, segments []vad.Segment) error {
+	if fileID == "" || strings.ContainsAny(fileID, " \t\n") {
+		return fmt.Errorf("invalid file ID %q: should be non-empty and not contain whitespace", fileID)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, seg := range segments {
+		if seg.SpeechStartAt < 0 || seg.SpeechEndAt < seg.SpeechStartAt {
+			return fmt.Errorf("invalid segment %d: should be closed and not end before it starts", i)
+		}
+		fmt.Fprintf(bw, "SPEAKER %s 1 %.3f %.3f <NA> <NA> %s <NA> <NA>\n",
+			fileID, seg.SpeechStartAt, seg.SpeechEndAt-seg.SpeechStartAt, speechLabel)
+	}
+	return bw.Flush()
+}
+
+// ReadRTTM reads the SPEAKER records from r and returns their segments
+// grouped by file ID and sorted by start time. Segments of different speakers
+// are kept as is, so they can overlap.
+func ReadRTTM(r io.Reader) (map[string][]vad.Segment, error) {
+	files := map[string][]vad.Segment{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || fields[0] != "SPEAKER" {
+			continue
+		}
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("line %d: expected at least 5 fields, got %d", line, len(fields))
+		}
+
+		start, err := parseSeconds(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		duration, err := parseSeconds(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if start < 0 || duration < 0 {
+			return nil, fmt.Errorf("line %d: negative time", line)
+		}
+
+		files[fields[1]] = append(files[fields[1]], vad.Segment{
+			SpeechStartAt: start,
+			SpeechEndAt:   start + duration,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read RTTM: %w", err)
+	}
+
+	for _, segments := range files {
+		sort.SliceStable(segments, func(i, j int) bool {
+			return segments[i].SpeechStartAt < segments[j].SpeechStartAt
+		})
+	}
+
+	return files, nil
+}
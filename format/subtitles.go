@@ -0,0 +1,105 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+// formatTimestamp formats a time in seconds as hh:mm:ss.mmm, using a comma
+// as decimal separator for SRT.
+func formatTimestamp(v float64, vtt bool) string {
+	ms := int64(math.Round(v * 1000))
+	sep := ","
+	if vtt {
+		sep = "."
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// parseTimestamp parses a [hh:]mm:ss.mmm timestamp, also accepting a comma as
+// decimal separator.
+func parseTimestamp(s string) (float64, error) {
+	parts := strings.Split(strings.Replace(s, ",", ".", 1), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	var v float64
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 || (i < len(parts)-1 && strings.Contains(part, ".")) {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		v = v*60 + n
+	}
+
+	return v, nil
+}
+
+func encodeSubtitles(w io.Writer, segments []vad.Segment, vtt bool) error {
+	bw := bufio.NewWriter(w)
+	if vtt {
+		fmt.Fprint(bw, "WEBVTT\n\n")
+	}
+	for i, seg := range segments {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatTimestamp(seg.SpeechStartAt, vtt), formatTimestamp(seg.SpeechEndAt, vtt), speechLabel)
+	}
+	return bw.Flush()
+}
+
+func decodeSubtitles(r io.Reader, vtt bool) ([]vad.Segment, error) {
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+	if vtt {
+		if !scanner.Scan() || !strings.HasPrefix(strings.TrimPrefix(scanner.Text(), "\ufeff"), "WEBVTT") {
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read subtitles: %w", err)
+			}
+			return nil, fmt.Errorf("missing WEBVTT header")
+		}
+		line++
+	}
+
+	var segments []vad.Segment
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		// Cues are the only blocks containing timings, other lines are either
+		// identifiers, payload or metadata blocks which we don't need.
+		if !strings.Contains(text, "-->") {
+			continue
+		}
+
+		start, rest, _ := strings.Cut(text, "-->")
+		// WebVTT allows cue settings after the end timestamp.
+		endFields := strings.Fields(rest)
+		if len(endFields) == 0 {
+			return nil, fmt.Errorf("line %d: missing end timestamp", line)
+		}
+
+		startAt, err := parseTimestamp(strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		endAt, err := parseTimestamp(endFields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		segments = append(segments, vad.Segment{SpeechStartAt: startAt, SpeechEndAt: endAt})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subtitles: %w", err)
+	}
+
+	return segments, nil
+}
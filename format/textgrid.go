@@ -0,0 +1,105 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+func encodeTextGrid(w io.Writer, segments []vad.Segment) error {
+	var xmax float64
+	if n := len(segments); n > 0 {
+		xmax = segments[n-1].SpeechEndAt
+	}
+
+	// Interval tiers need to cover the whole time range, so gaps between
+	// segments are filled with empty intervals.
+	type interval struct {
+		xmin, xmax float64
+		text       string
+	}
+	var intervals []interval
+	var last float64
+	for _, seg := range segments {
+		if seg.SpeechStartAt > last {
+			intervals = append(intervals, interval{xmin: last, xmax: seg.SpeechStartAt})
+		}
+		intervals = append(intervals, interval{xmin: max(seg.SpeechStartAt, last), xmax: seg.SpeechEndAt, text: speechLabel})
+		last = seg.SpeechEndAt
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "File type = \"ooTextFile\"\nObject class = \"TextGrid\"\n\n")
+	fmt.Fprintf(bw, "xmin = 0\nxmax = %s\ntiers? <exists>\nsize = 1\nitem []:\n", formatSeconds(xmax))
+	fmt.Fprintf(bw, "    item [1]:\n")
+	fmt.Fprintf(bw, "        class = \"IntervalTier\"\n")
+	fmt.Fprintf(bw, "        name = \"%s\"\n", speechLabel)
+	fmt.Fprintf(bw, "        xmin = 0\n        xmax = %s\n", formatSeconds(xmax))
+	fmt.Fprintf(bw, "        intervals: size = %d\n", len(intervals))
+	for i, iv := range intervals {
+		fmt.Fprintf(bw, "        intervals [%d]:\n", i+1)
+		fmt.Fprintf(bw, "            xmin = %s\n", formatSeconds(iv.xmin))
+		fmt.Fprintf(bw, "            xmax = %s\n", formatSeconds(iv.xmax))
+		fmt.Fprintf(bw, "            text = \"%s\"\n", iv.text)
+	}
+
+	return bw.Flush()
+}
+
+// decodeTextGrid reads the non-empty intervals of the first interval tier of
+// a long text format TextGrid.
+func decodeTextGrid(r io.Reader) ([]vad.Segment, error) {
+	scanner := bufio.NewScanner(r)
+
+	var segments []vad.Segment
+	var inTier, inInterval bool
+	var tiers int
+	var xmin, xmax string
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(text, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(text, "item [") && text != "item []:":
+			if tiers > 0 && inTier {
+				// Only the first interval tier is read.
+				return segments, scanner.Err()
+			}
+			tiers++
+			inTier, inInterval = false, false
+		case ok && key == "class":
+			inTier = value == `"IntervalTier"`
+		case inTier && strings.HasPrefix(text, "intervals ["):
+			inInterval = true
+			xmin, xmax = "", ""
+		case inInterval && ok && key == "xmin":
+			xmin = value
+		case inInterval && ok && key == "xmax":
+			xmax = value
+		case inInterval && ok && key == "text":
+			inInterval = false
+			if label := strings.Trim(value, `"`); strings.TrimSpace(label) == "" {
+				continue
+			}
+			seg, err := parseSegment(xmin, xmax)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			segments = append(segments, seg)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read TextGrid: %w", err)
+	}
+
+	if tiers == 0 {
+		return nil, fmt.Errorf("missing tiers")
+	}
+
+	return segments, nil
+}
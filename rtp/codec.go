@@ -0,0 +1,132 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Codec identifies the encoding of RTP audio payloads.
+type Codec int
+
+const (
+	// CodecPCMU is G.711 μ-law.
+	CodecPCMU Codec = iota + 1
+	// CodecPCMA is G.711 A-law.
+	CodecPCMA
+	// CodecL16 is 16-bit signed integer big-endian PCM.
+	CodecL16
+)
+
+func (c Codec) String() string {
+	switch c {
+	case CodecPCMU:
+		return "PCMU"
+	case CodecPCMA:
+		return "PCMA"
+	case CodecL16:
+		return "L16"
+	default:
+		return fmt.Sprintf("Codec(%d)", int(c))
+	}
+}
+
+// PayloadFormat describes the audio carried by a payload type.
+type PayloadFormat struct {
+	Codec Codec
+	// The RTP clock rate, which is also the sample rate for the supported codecs.
+	SampleRate int
+	// The number of interleaved channels, which get downmixed. Defaults to 1.
+	Channels int
+}
+
+func (f PayloadFormat) IsValid() error {
+	if f.Codec < CodecPCMU || f.Codec > CodecL16 {
+		return fmt.Errorf("invalid Codec: unknown codec")
+	}
+
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("invalid SampleRate: should be a positive number")
+	}
+
+	if f.Channels < 0 {
+		return fmt.Errorf("invalid Channels: should be a positive number")
+	}
+
+	return nil
+}
+
+func (f PayloadFormat) channels() int {
+	return max(f.Channels, 1)
+}
+
+// DefaultPayloadTypes returns the static payload types of the supported
+// codecs, as assigned by RFC 3551.
+func DefaultPayloadTypes() map[uint8]PayloadFormat {
+	return map[uint8]PayloadFormat{
+		0:  {Codec: CodecPCMU, SampleRate: 8000, Channels: 1},
+		8:  {Codec: CodecPCMA, SampleRate: 8000, Channels: 1},
+		10: {Codec: CodecL16, SampleRate: 44100, Channels: 2},
+		11: {Codec: CodecL16, SampleRate: 44100, Channels: 1},
+	}
+}
+
+// DecodePayload appends the samples encoded in payload to dst, downmixed to
+// mono, and returns the extended slice.
+func DecodePayload(dst []float32, payload []byte, format PayloadFormat) []float32 {
+	channels := format.channels()
+
+	var sampleSize int
+	var decode func(data []byte) float32
+	switch format.Codec {
+	case CodecPCMU:
+		sampleSize, decode = 1, func(data []byte) float32 { return float32(ulawToLinear(data[0])) / 32768 }
+	case CodecPCMA:
+		sampleSize, decode = 1, func(data []byte) float32 { return float32(alawToLinear(data[0])) / 32768 }
+	case CodecL16:
+		sampleSize, decode = 2, func(data []byte) float32 { return float32(int16(binary.BigEndian.Uint16(data))) / 32768 }
+	default:
+		return dst
+	}
+
+	frameSize := sampleSize * channels
+	for i := 0; i+frameSize <= len(payload); i += frameSize {
+		var sum float32
+		for c := 0; c < channels; c++ {
+			sum += decode(payload[i+c*sampleSize:])
+		}
+		dst = append(dst, sum/float32(channels))
+	}
+
+	return dst
+}
+
+// ulawToLinear decodes a G.711 μ-law sample to 16-bit linear PCM.
+func ulawToLinear(u byte) int16 {
+	u = ^u
+	exponent := (u >> 4) & 0x07
+	mantissa := int16(u & 0x0F)
+	sample := ((mantissa << 3) + 0x84) << exponent
+	sample -= 0x84
+	if u&0x80 != 0 {
+		return -sample
+	}
+	return sample
+}
+
+// alawToLinear decodes a G.711 A-law sample to 16-bit linear PCM.
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	exponent := (a >> 4) & 0x07
+	mantissa := int16(a & 0x0F)
+	var sample int16
+	if exponent == 0 {
+		sample = (mantissa << 4) + 8
+	} else {
+		sample = ((mantissa << 4) + 0x108) << (exponent - 1)
+	}
+	// Unlike μ-law, a set sign bit means a positive sample.
+	if a&0x80 != 0 {
+		return sample
+	}
+	return -sample
+}
@@ -0,0 +1,84 @@
+package rtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	require.Equal(t, "PCMU", CodecPCMU.String())
+	require.Equal(t, "PCMA", CodecPCMA.String())
+	require.Equal(t, "L16", CodecL16.String())
+	require.Equal(t, "Codec(0)", Codec(0).String())
+}
+
+func TestPayloadFormatIsValid(t *testing.T) {
+	require.NoError(t, PayloadFormat{Codec: CodecPCMU, SampleRate: 8000}.IsValid())
+	require.EqualError(t, PayloadFormat{SampleRate: 8000}.IsValid(), "invalid Codec: unknown codec")
+	require.EqualError(t, PayloadFormat{Codec: CodecL16}.IsValid(), "invalid SampleRate: should be a positive number")
+	require.EqualError(t, PayloadFormat{Codec: CodecL16, SampleRate: 8000, Channels: -1}.IsValid(),
+		"invalid Channels: should be a positive number")
+
+	for pt, format := range DefaultPayloadTypes() {
+		require.NoError(t, format.IsValid(), pt)
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	t.Run("ulaw", func(t *testing.T) {
+		for u, expected := range map[byte]int16{0xFF: 0, 0x7F: 0, 0x00: -32124, 0x80: 32124, 0xF0: 120, 0x70: -120} {
+			require.Equal(t, expected, ulawToLinear(u), u)
+		}
+	})
+
+	t.Run("alaw", func(t *testing.T) {
+		for a, expected := range map[byte]int16{0xD5: 8, 0x55: -8, 0xAA: 32256, 0x2A: -32256} {
+			require.Equal(t, expected, alawToLinear(a), a)
+		}
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		tcs := []struct {
+			name     string
+			format   PayloadFormat
+			payload  []byte
+			expected []float32
+		}{
+			{
+				name:     "PCMU",
+				format:   PayloadFormat{Codec: CodecPCMU, SampleRate: 8000},
+				payload:  []byte{0xFF, 0x80},
+				expected: []float32{0, 32124.0 / 32768},
+			},
+			{
+				name:     "PCMA",
+				format:   PayloadFormat{Codec: CodecPCMA, SampleRate: 8000},
+				payload:  []byte{0xD5, 0x2A},
+				expected: []float32{8.0 / 32768, -32256.0 / 32768},
+			},
+			{
+				name:     "L16",
+				format:   PayloadFormat{Codec: CodecL16, SampleRate: 16000},
+				payload:  []byte{0x40, 0x00, 0x80, 0x00, 0x01},
+				expected: []float32{0.5, -1},
+			},
+			{
+				name:     "L16 stereo",
+				format:   PayloadFormat{Codec: CodecL16, SampleRate: 44100, Channels: 2},
+				payload:  []byte{0x40, 0x00, 0x00, 0x00, 0xC0, 0x00, 0xE0, 0x00},
+				expected: []float32{0.25, -0.375},
+			},
+			{
+				name:    "unknown",
+				format:  PayloadFormat{},
+				payload: []byte{0x40, 0x00},
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				require.Equal(t, tc.expected, DecodePayload(nil, tc.payload, tc.format))
+			})
+		}
+	})
+}
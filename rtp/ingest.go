@@ -0,0 +1,408 @@
+package rtp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+// maxPacketSize is the largest UDP payload.
+const maxPacketSize = 65535
+
+type Config struct {
+	// The payload formats by payload type. Packets of other payload types are
+	// ignored. Defaults to DefaultPayloadTypes.
+	PayloadTypes map[uint8]PayloadFormat
+	// The number of out of order packets to hold while waiting for a missing
+	// one before considering it lost. Defaults to 5.
+	ReorderPackets int
+	// The longest timestamp gap filled with silence. Larger jumps restart the
+	// stream timeline, ending any open segment. Defaults to 10s.
+	MaxGap time.Duration
+	// The duration without packets after which a stream is flushed and its
+	// detector returned to the pool. Defaults to 10s.
+	IdleTimeout time.Duration
+	// The maximum duration to wait for a detector when a new SSRC shows up.
+	// Packets of SSRCs not getting one are ignored until they go idle.
+	// Defaults to 1s.
+	AcquireTimeout time.Duration
+	// The number of events buffered before processing blocks. Defaults to 64.
+	EventBuffer int
+	// Whether to emit the speech probability of each window.
+	Probabilities bool
+}
+
+func (c Config) IsValid() error {
+	for pt, format := range c.PayloadTypes {
+		if pt > 127 {
+			return fmt.Errorf("invalid PayloadTypes: payload type %d should be in range [0, 127]", pt)
+		}
+		if err := format.IsValid(); err != nil {
+			return fmt.Errorf("invalid PayloadTypes: payload type %d: %w", pt, err)
+		}
+	}
+
+	if c.ReorderPackets < 0 {
+		return fmt.Errorf("invalid ReorderPackets: should be a positive number")
+	}
+
+	if c.MaxGap < 0 {
+		return fmt.Errorf("invalid MaxGap: should be a positive duration")
+	}
+
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("invalid IdleTimeout: should be a positive duration")
+	}
+
+	if c.AcquireTimeout < 0 {
+		return fmt.Errorf("invalid AcquireTimeout: should be a positive duration")
+	}
+
+	if c.EventBuffer < 0 {
+		return fmt.Errorf("invalid EventBuffer: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	if c.PayloadTypes == nil {
+		c.PayloadTypes = DefaultPayloadTypes()
+	}
+	if c.ReorderPackets == 0 {
+		c.ReorderPackets = 5
+	}
+	if c.MaxGap == 0 {
+		c.MaxGap = 10 * time.Second
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = 10 * time.Second
+	}
+	if c.AcquireTimeout == 0 {
+		c.AcquireTimeout = time.Second
+	}
+	if c.EventBuffer == 0 {
+		c.EventBuffer = 64
+	}
+}
+
+// Event is a speech update of a single stream.
+type Event struct {
+	// The SSRC of the stream.
+	SSRC uint32
+	// The event, with times in seconds from the first packet of the stream
+	// timeline.
+	stream.Event
+	// The RTP timestamps matching Start, End and Time.
+	StartTimestamp uint32
+	EndTimestamp   uint32
+	Timestamp      uint32
+}
+
+// Ingester runs streaming detection over RTP audio received on UDP, with one
+// detector per SSRC. Packets are put back in order, lost ones and timestamp
+// gaps (e.g. discontinuous transmission) being filled with silence so that
+// event times keep matching RTP timestamps.
+//
+// Packets are processed sequentially as they are read, so events should be
+// consumed promptly to avoid stalling reception.
+type Ingester struct {
+	cfg    Config
+	pool   *speech.Pool
+	events chan Event
+
+	streams map[uint32]*ssrcStream
+	// Scratch buffers reused across packets.
+	buf     []byte
+	samples []float32
+}
+
+func NewIngester(pool *speech.Pool, cfg Config) (*Ingester, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("invalid nil pool")
+	}
+
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.setDefaults()
+
+	return &Ingester{
+		cfg:     cfg,
+		pool:    pool,
+		events:  make(chan Event, cfg.EventBuffer),
+		streams: make(map[uint32]*ssrcStream),
+	}, nil
+}
+
+// Events returns the channel events are delivered on. It gets closed once
+// Serve returns.
+func (in *Ingester) Events() <-chan Event {
+	return in.events
+}
+
+// Serve reads packets from conn until ctx is done, at which point conn gets
+// closed and the open streams flushed. It returns nil in such case, or the
+// error that stopped reception otherwise. Serve should be called only once.
+func (in *Ingester) Serve(ctx context.Context, conn net.PacketConn) error {
+	defer close(in.events)
+	defer in.closeAll()
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	in.buf = make([]byte, maxPacketSize)
+	// Reads time out regularly so that idle streams get expired without
+	// needing another goroutine.
+	tick := in.cfg.IdleTimeout / 2
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(tick)); err != nil {
+			return err
+		}
+
+		n, _, err := conn.ReadFrom(in.buf)
+		now := time.Now()
+		var netErr net.Error
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.As(err, &netErr) && netErr.Timeout():
+			in.expire(ctx, now)
+			continue
+		case err != nil:
+			return err
+		}
+
+		in.handlePacket(ctx, in.buf[:n], now)
+		in.expire(ctx, now)
+	}
+}
+
+// handlePacket processes a single packet received at now.
+func (in *Ingester) handlePacket(ctx context.Context, data []byte, now time.Time) {
+	p, err := ParsePacket(data)
+	if err != nil {
+		slog.Debug("ignoring invalid RTP packet", slog.String("err", err.Error()))
+		return
+	}
+
+	st, ok := in.streams[p.SSRC]
+	if !ok {
+		st = in.newStream(ctx, p.SSRC)
+		in.streams[p.SSRC] = st
+	}
+	st.lastSeen = now
+	if st.sd == nil {
+		return
+	}
+
+	// The payload points into the read buffer, which the next packet
+	// overwrites while this one may be held for reordering.
+	p.Payload = bytes.Clone(p.Payload)
+	for _, q := range st.reorder.push(p) {
+		in.process(ctx, st, q)
+	}
+}
+
+func (in *Ingester) newStream(ctx context.Context, ssrc uint32) *ssrcStream {
+	st := &ssrcStream{
+		ssrc:    ssrc,
+		reorder: newReorderBuffer(in.cfg.ReorderPackets),
+	}
+
+	getCtx, cancel := context.WithTimeout(ctx, in.cfg.AcquireTimeout)
+	defer cancel()
+	sd, err := in.pool.Get(getCtx)
+	if err != nil {
+		slog.Warn("failed to get detector, ignoring stream",
+			slog.Uint64("ssrc", uint64(ssrc)), slog.String("err", err.Error()))
+		return st
+	}
+	st.sd = sd
+
+	return st
+}
+
+// process feeds the audio of p, delivered in order, to the stream detector.
+func (in *Ingester) process(ctx context.Context, st *ssrcStream, p Packet) {
+	format, ok := in.cfg.PayloadTypes[p.PayloadType]
+	if !ok {
+		return
+	}
+
+	if st.session == nil || format != st.format {
+		in.restart(ctx, st, p.Timestamp, format)
+	}
+
+	samples := DecodePayload(in.samples[:0], p.Payload, format)
+	in.samples = samples
+	// The timestamp expected for the packet following this one.
+	next := p.Timestamp + uint32(len(samples))
+
+	maxGap := int64(in.cfg.MaxGap.Seconds() * float64(format.SampleRate))
+	gap := int64(int32(p.Timestamp - st.expected))
+	switch {
+	case gap > maxGap || -gap > maxGap:
+		in.restart(ctx, st, p.Timestamp, format)
+	case gap > 0:
+		in.write(ctx, st, make([]float32, gap))
+	case gap < 0:
+		// Overlapping with audio already fed.
+		samples = samples[min(int(-gap), len(samples)):]
+		if int32(next-st.expected) < 0 {
+			next = st.expected
+		}
+	}
+
+	in.write(ctx, st, samples)
+	st.expected = next
+}
+
+// restart starts a new timeline at the RTP timestamp ts, flushing the
+// previous one if any.
+func (in *Ingester) restart(ctx context.Context, st *ssrcStream, ts uint32, format PayloadFormat) {
+	if st.session != nil {
+		in.flush(st)
+		if err := st.sd.Reset(); err != nil {
+			in.fail(st, err)
+			return
+		}
+	}
+
+	session, err := stream.NewSession(st.sd, stream.Config{
+		SampleRate:    format.SampleRate,
+		Probabilities: in.cfg.Probabilities,
+	})
+	if err != nil {
+		in.fail(st, err)
+		return
+	}
+
+	st.session = session
+	st.format = format
+	st.base = ts
+	st.expected = ts
+}
+
+func (in *Ingester) write(ctx context.Context, st *ssrcStream, samples []float32) {
+	if st.session == nil || len(samples) == 0 {
+		return
+	}
+
+	events, err := st.session.WriteSamples(ctx, samples)
+	if err != nil {
+		in.fail(st, err)
+		return
+	}
+	in.emit(st, events)
+}
+
+// flush ends the stream timeline, emitting the end of any open segment.
+func (in *Ingester) flush(st *ssrcStream) {
+	if st.session == nil {
+		return
+	}
+
+	// Flushing happens on shutdown too, so it shouldn't depend on the serving
+	// context.
+	events, err := st.session.Flush(context.Background())
+	if err != nil {
+		in.fail(st, err)
+		return
+	}
+	in.emit(st, events)
+}
+
+// fail drops the stream session after a detection failure. The stream
+// starts over with its next packet.
+func (in *Ingester) fail(st *ssrcStream, err error) {
+	if !errors.Is(err, context.Canceled) {
+		slog.Error("stream detection failed",
+			slog.Uint64("ssrc", uint64(st.ssrc)), slog.String("err", err.Error()))
+	}
+	st.session = nil
+	if err := st.sd.Reset(); err != nil {
+		slog.Error("failed to reset detector", slog.String("err", err.Error()))
+	}
+}
+
+func (in *Ingester) emit(st *ssrcStream, events []stream.Event) {
+	for _, ev := range events {
+		in.events <- Event{
+			SSRC:           st.ssrc,
+			Event:          ev,
+			StartTimestamp: st.timestamp(ev.Start),
+			EndTimestamp:   st.timestamp(ev.End),
+			Timestamp:      st.timestamp(ev.Time),
+		}
+	}
+}
+
+// expire closes the streams not having received packets for IdleTimeout.
+func (in *Ingester) expire(ctx context.Context, now time.Time) {
+	for ssrc, st := range in.streams {
+		if now.Sub(st.lastSeen) < in.cfg.IdleTimeout {
+			continue
+		}
+		in.closeStream(ctx, st)
+		delete(in.streams, ssrc)
+	}
+}
+
+func (in *Ingester) closeAll() {
+	for ssrc, st := range in.streams {
+		in.closeStream(context.Background(), st)
+		delete(in.streams, ssrc)
+	}
+}
+
+// closeStream processes the packets still held for reordering, flushes the
+// stream and returns its detector to the pool.
+func (in *Ingester) closeStream(ctx context.Context, st *ssrcStream) {
+	if st.sd == nil {
+		return
+	}
+
+	for _, p := range st.reorder.flush() {
+		in.process(ctx, st, p)
+	}
+	in.flush(st)
+
+	slog.Debug("closing stream", slog.Uint64("ssrc", uint64(st.ssrc)),
+		slog.Int("lost", st.reorder.lost), slog.Int("dropped", st.reorder.dropped))
+
+	if err := in.pool.Put(st.sd); err != nil {
+		slog.Error("failed to return detector", slog.String("err", err.Error()))
+	}
+	st.sd = nil
+}
+
+// ssrcStream is the detection state of a single SSRC.
+type ssrcStream struct {
+	ssrc     uint32
+	sd       *speech.Detector
+	session  *stream.Session
+	reorder  *reorderBuffer
+	lastSeen time.Time
+
+	format PayloadFormat
+	// The RTP timestamp of the start of the session timeline.
+	base uint32
+	// The RTP timestamp of the next sample to feed.
+	expected uint32
+}
+
+// timestamp returns the RTP timestamp matching the session time t in seconds.
+func (st *ssrcStream) timestamp(t float64) uint32 {
+	return st.base + uint32(int64(math.Round(t*float64(st.format.SampleRate))))
+}
@@ -0,0 +1,290 @@
+package rtp
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+func TestIngesterConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{
+		PayloadTypes:   map[uint8]PayloadFormat{96: {Codec: CodecL16, SampleRate: 16000}},
+		ReorderPackets: 10,
+		MaxGap:         time.Second,
+		IdleTimeout:    time.Second,
+		AcquireTimeout: time.Second,
+		EventBuffer:    10,
+		Probabilities:  true,
+	}.IsValid())
+
+	tcs := []struct {
+		cfg Config
+		err string
+	}{
+		{Config{PayloadTypes: map[uint8]PayloadFormat{128: {Codec: CodecPCMU, SampleRate: 8000}}},
+			"invalid PayloadTypes: payload type 128 should be in range [0, 127]"},
+		{Config{PayloadTypes: map[uint8]PayloadFormat{96: {Codec: CodecPCMU}}},
+			"invalid PayloadTypes: payload type 96: invalid SampleRate: should be a positive number"},
+		{Config{ReorderPackets: -1}, "invalid ReorderPackets: should be a positive number"},
+		{Config{MaxGap: -1}, "invalid MaxGap: should be a positive duration"},
+		{Config{IdleTimeout: -1}, "invalid IdleTimeout: should be a positive duration"},
+		{Config{AcquireTimeout: -1}, "invalid AcquireTimeout: should be a positive duration"},
+		{Config{EventBuffer: -1}, "invalid EventBuffer: should be a positive number"},
+	}
+	for _, tc := range tcs {
+		require.EqualError(t, tc.cfg.IsValid(), tc.err)
+	}
+}
+
+func TestNewIngester(t *testing.T) {
+	_, err := NewIngester(nil, Config{})
+	require.EqualError(t, err, "invalid nil pool")
+}
+
+// packetize splits samples into L16 packets of 20ms at 16kHz.
+func packetize(ssrc uint32, seq uint16, ts uint32, samples []float32) []Packet {
+	const packetSamples = 320
+	var packets []Packet
+	for i := 0; i < len(samples); i += packetSamples {
+		chunk := samples[i:min(i+packetSamples, len(samples))]
+		payload := make([]byte, 0, 2*len(chunk))
+		for _, s := range chunk {
+			payload = binary.BigEndian.AppendUint16(payload, uint16(int16(max(min(s, 1-1.0/32768), -1)*32768)))
+		}
+		packets = append(packets, Packet{
+			Header: Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: seq,
+				Timestamp:      ts,
+				SSRC:           ssrc,
+			},
+			Payload: payload,
+		})
+		seq++
+		ts += uint32(len(chunk))
+	}
+	return packets
+}
+
+func TestIngester(t *testing.T) {
+	detCfg := speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+	pool, err := speech.NewPool(speech.PoolConfig{DetectorConfig: detCfg, MaxSize: 2})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, pool.Close())
+	}()
+
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+	// Matching the L16 quantization of the packets.
+	for i, s := range samples {
+		samples[i] = float32(int16(max(min(s, 1-1.0/32768), -1)*32768)) / 32768
+	}
+
+	ingCfg := Config{
+		PayloadTypes:   map[uint8]PayloadFormat{96: {Codec: CodecL16, SampleRate: 16000}},
+		ReorderPackets: 3,
+	}
+
+	detect := func(t *testing.T, samples []float32) []speech.Segment {
+		t.Helper()
+		sd, err := speech.NewDetector(detCfg)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, sd.Destroy())
+		}()
+		segments, err := sd.Detect(samples)
+		require.NoError(t, err)
+		require.NotEmpty(t, segments)
+		if segments[len(segments)-1].SpeechEndAt == 0 {
+			segments[len(segments)-1].SpeechEndAt = float64(len(samples)) / 16000
+		}
+		return segments
+	}
+
+	// collect gathers events until the channel gets closed.
+	collect := func(events <-chan Event) <-chan []Event {
+		done := make(chan []Event, 1)
+		go func() {
+			var all []Event
+			for ev := range events {
+				all = append(all, ev)
+			}
+			done <- all
+		}()
+		return done
+	}
+
+	// toSegments returns the segments of the end events of each SSRC, checking
+	// their RTP timestamps.
+	toSegments := func(t *testing.T, events []Event, bases map[uint32]uint32) map[uint32][]speech.Segment {
+		t.Helper()
+		segments := make(map[uint32][]speech.Segment)
+		for _, ev := range events {
+			if ev.Type != stream.EventSpeechEnd {
+				continue
+			}
+			base := bases[ev.SSRC]
+			require.Equal(t, base+uint32(ev.Start*16000+0.5), ev.StartTimestamp)
+			require.Equal(t, base+uint32(ev.End*16000+0.5), ev.EndTimestamp)
+			segments[ev.SSRC] = append(segments[ev.SSRC], speech.Segment{SpeechStartAt: ev.Start, SpeechEndAt: ev.End})
+		}
+		return segments
+	}
+
+	t.Run("reordering and loss", func(t *testing.T) {
+		in, err := NewIngester(pool, ingCfg)
+		require.NoError(t, err)
+
+		// The first stream gets adjacent packets swapped while the second one,
+		// starting close to the sequence and timestamp wrap around, loses one.
+		first := packetize(1, 100, 1000, samples)
+		for i := 1; i+1 < len(first); i += 4 {
+			first[i], first[i+1] = first[i+1], first[i]
+		}
+		second := packetize(2, 65500, 0xFFFFFF00, samples)
+		lost := len(second) / 2
+		second = append(second[:lost], second[lost+1:]...)
+		lostSamples := make([]float32, len(samples))
+		copy(lostSamples, samples)
+		clear(lostSamples[lost*320 : (lost+1)*320])
+
+		done := collect(in.Events())
+		ctx := context.Background()
+		now := time.Now()
+		for i := 0; i < max(len(first), len(second)); i++ {
+			if i < len(first) {
+				in.handlePacket(ctx, first[i].Marshal(), now)
+			}
+			if i < len(second) {
+				in.handlePacket(ctx, second[i].Marshal(), now)
+			}
+		}
+		in.closeAll()
+		close(in.events)
+		segments := toSegments(t, <-done, map[uint32]uint32{1: 1000, 2: 0xFFFFFF00})
+
+		require.Equal(t, detect(t, samples), segments[1])
+		require.Equal(t, detect(t, lostSamples), segments[2])
+	})
+
+	t.Run("timestamp gap", func(t *testing.T) {
+		in, err := NewIngester(pool, ingCfg)
+		require.NoError(t, err)
+
+		// Discontinuous transmission, with contiguous sequence numbers but a
+		// timestamp jump over a second of silence.
+		half := len(samples) / 640 * 320
+		packets := packetize(3, 0, 0, samples[:half])
+		packets = append(packets, packetize(3, uint16(len(packets)), uint32(half+16000), samples[half:])...)
+		gapSamples := append(append(append([]float32{}, samples[:half]...), make([]float32, 16000)...), samples[half:]...)
+
+		done := collect(in.Events())
+		now := time.Now()
+		for _, p := range packets {
+			in.handlePacket(context.Background(), p.Marshal(), now)
+		}
+		in.closeAll()
+		close(in.events)
+		segments := toSegments(t, <-done, map[uint32]uint32{3: 0})
+
+		require.Equal(t, detect(t, gapSamples), segments[3])
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		in, err := NewIngester(pool, Config{
+			PayloadTypes: ingCfg.PayloadTypes,
+			IdleTimeout:  100 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- in.Serve(ctx, conn)
+		}()
+
+		client, err := net.Dial("udp", conn.LocalAddr().String())
+		require.NoError(t, err)
+		defer client.Close()
+		for _, p := range packetize(4, 0, 0, samples) {
+			_, err := client.Write(p.Marshal())
+			require.NoError(t, err)
+			time.Sleep(time.Millisecond)
+		}
+
+		// Giving the stream time to go idle and get flushed.
+		time.Sleep(300 * time.Millisecond)
+		cancel()
+		require.NoError(t, <-serveErr)
+
+		var ends int
+		for ev := range in.Events() {
+			require.Equal(t, uint32(4), ev.SSRC)
+			if ev.Type == stream.EventSpeechEnd {
+				ends++
+			}
+		}
+		require.Equal(t, len(detect(t, samples)), ends)
+	})
+
+	t.Run("serve reordered", func(t *testing.T) {
+		in, err := NewIngester(pool, Config{
+			PayloadTypes:   ingCfg.PayloadTypes,
+			ReorderPackets: 3,
+			IdleTimeout:    100 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := collect(in.Events())
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- in.Serve(ctx, conn)
+		}()
+
+		// Packets held for reordering shouldn't get overwritten by the
+		// following reads.
+		packets := packetize(5, 0, 0, samples)
+		for i := 1; i+1 < len(packets); i += 4 {
+			packets[i], packets[i+1] = packets[i+1], packets[i]
+		}
+		client, err := net.Dial("udp", conn.LocalAddr().String())
+		require.NoError(t, err)
+		defer client.Close()
+		for _, p := range packets {
+			_, err := client.Write(p.Marshal())
+			require.NoError(t, err)
+			time.Sleep(time.Millisecond)
+		}
+
+		time.Sleep(300 * time.Millisecond)
+		cancel()
+		require.NoError(t, <-serveErr)
+
+		segments := toSegments(t, <-done, map[uint32]uint32{5: 0})
+		require.Equal(t, detect(t, samples), segments[5])
+	})
+}
@@ -0,0 +1,107 @@
+package rtp
+
+const (
+	// Sequence number jumps beyond these bounds are considered a restart of
+	// the source rather than misordering or loss, as suggested by RFC 3550.
+	maxDropout  = 3000
+	maxMisorder = 100
+)
+
+// reorderBuffer puts packets of a single SSRC back in sequence number order,
+// holding up to depth out of order packets while waiting for a missing one
+// before considering it lost.
+type reorderBuffer struct {
+	depth   int
+	started bool
+	// The next expected sequence number.
+	next    uint16
+	packets map[uint16]Packet
+
+	// The number of packets considered lost, and dropped for arriving late or
+	// being duplicates.
+	lost    int
+	dropped int
+}
+
+func newReorderBuffer(depth int) *reorderBuffer {
+	return &reorderBuffer{
+		depth:   depth,
+		packets: make(map[uint16]Packet, depth+1),
+	}
+}
+
+// push adds p to the buffer and returns the packets now ready, in order.
+func (b *reorderBuffer) push(p Packet) []Packet {
+	if !b.started {
+		b.started = true
+		b.next = p.SequenceNumber
+	}
+
+	var out []Packet
+	delta := int(int16(p.SequenceNumber - b.next))
+	switch {
+	case delta < -maxMisorder || delta > maxDropout:
+		// The source most likely restarted, delivering what's buffered before
+		// resynchronizing on the new sequence.
+		out = b.flush()
+		b.next = p.SequenceNumber
+	case delta < 0:
+		b.dropped++
+		return nil
+	}
+
+	if _, ok := b.packets[p.SequenceNumber]; ok {
+		b.dropped++
+		return out
+	}
+	b.packets[p.SequenceNumber] = p
+
+	out = b.drain(out)
+	for len(b.packets) > b.depth {
+		// Giving up on the missing packets, skipping to the earliest buffered one.
+		skip := b.earliest()
+		b.lost += int(skip - b.next)
+		b.next = skip
+		out = b.drain(out)
+	}
+
+	return out
+}
+
+// flush returns all buffered packets in order, considering missing ones lost.
+func (b *reorderBuffer) flush() []Packet {
+	var out []Packet
+	for len(b.packets) > 0 {
+		skip := b.earliest()
+		b.lost += int(skip - b.next)
+		b.next = skip
+		out = b.drain(out)
+	}
+	return out
+}
+
+// drain appends the consecutive packets starting at the next expected one.
+func (b *reorderBuffer) drain(out []Packet) []Packet {
+	for {
+		p, ok := b.packets[b.next]
+		if !ok {
+			return out
+		}
+		delete(b.packets, b.next)
+		out = append(out, p)
+		b.next++
+	}
+}
+
+// earliest returns the buffered sequence number closest to the next expected one.
+func (b *reorderBuffer) earliest() uint16 {
+	var seq uint16
+	first := true
+	for s := range b.packets {
+		if first || s-b.next < seq-b.next {
+			seq = s
+			first = false
+		}
+	}
+	return seq
+}
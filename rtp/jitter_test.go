@@ -0,0 +1,90 @@
+package rtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorderBuffer(t *testing.T) {
+	tcs := []struct {
+		name    string
+		depth   int
+		seqs    []uint16
+		out     []uint16
+		flushed []uint16
+		lost    int
+		dropped int
+	}{
+		{
+			name:  "in order",
+			depth: 2,
+			seqs:  []uint16{10, 11, 12},
+			out:   []uint16{10, 11, 12},
+		},
+		{
+			name:  "reordered",
+			depth: 2,
+			seqs:  []uint16{10, 12, 11, 13},
+			out:   []uint16{10, 11, 12, 13},
+		},
+		{
+			name:    "duplicates and late",
+			depth:   2,
+			seqs:    []uint16{10, 11, 11, 13, 13, 10},
+			out:     []uint16{10, 11},
+			flushed: []uint16{13},
+			lost:    1,
+			dropped: 3,
+		},
+		{
+			name:  "lost",
+			depth: 2,
+			seqs:  []uint16{10, 12, 13, 14, 15},
+			out:   []uint16{10, 12, 13, 14, 15},
+			lost:  1,
+		},
+		{
+			name:  "wrap around",
+			depth: 2,
+			seqs:  []uint16{65534, 0, 65535, 1},
+			out:   []uint16{65534, 65535, 0, 1},
+		},
+		{
+			name:    "restart",
+			depth:   2,
+			seqs:    []uint16{10, 12, 5000, 5001},
+			out:     []uint16{10, 12, 5000, 5001},
+			lost:    1,
+			dropped: 0,
+		},
+		{
+			name:    "flush",
+			depth:   4,
+			seqs:    []uint16{10, 13, 12},
+			out:     []uint16{10},
+			flushed: []uint16{12, 13},
+			lost:    1,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newReorderBuffer(tc.depth)
+			var out []uint16
+			for _, seq := range tc.seqs {
+				for _, p := range b.push(Packet{Header: Header{SequenceNumber: seq}}) {
+					out = append(out, p.SequenceNumber)
+				}
+			}
+			require.Equal(t, tc.out, out)
+
+			var flushed []uint16
+			for _, p := range b.flush() {
+				flushed = append(flushed, p.SequenceNumber)
+			}
+			require.Equal(t, tc.flushed, flushed)
+			require.Equal(t, tc.lost, b.lost)
+			require.Equal(t, tc.dropped, b.dropped)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	rtpVersion    = 2
+	rtpHeaderSize = 12
+)
+
+// Header is a parsed RTP fixed header, as defined in RFC 3550.
+type Header struct {
+	Version        uint8
+	Padding        bool
+	Extension      bool
+	Marker         bool
+	PayloadType    uint8
+	SequenceNumber uint16
+	Timestamp      uint32
+	SSRC           uint32
+	CSRC           []uint32
+}
+
+// Packet is a parsed RTP packet.
+type Packet struct {
+	Header
+	// The payload, excluding any header extension and padding. It references
+	// the data the packet was parsed from.
+	Payload []byte
+}
+
+// ParsePacket parses an RTP packet from data. Header extensions are skipped.
+func ParsePacket(data []byte) (Packet, error) {
+	if len(data) < rtpHeaderSize {
+		return Packet{}, fmt.Errorf("packet too short: %d bytes", len(data))
+	}
+
+	var p Packet
+	p.Version = data[0] >> 6
+	if p.Version != rtpVersion {
+		return Packet{}, fmt.Errorf("unsupported RTP version %d", p.Version)
+	}
+	p.Padding = data[0]&0x20 != 0
+	p.Extension = data[0]&0x10 != 0
+	csrcCount := int(data[0] & 0x0F)
+	p.Marker = data[1]&0x80 != 0
+	p.PayloadType = data[1] & 0x7F
+	p.SequenceNumber = binary.BigEndian.Uint16(data[2:4])
+	p.Timestamp = binary.BigEndian.Uint32(data[4:8])
+	p.SSRC = binary.BigEndian.Uint32(data[8:12])
+
+	offset := rtpHeaderSize
+	if len(data) < offset+4*csrcCount {
+		return Packet{}, fmt.Errorf("packet too short for %d CSRCs", csrcCount)
+	}
+	for i := 0; i < csrcCount; i++ {
+		p.CSRC = append(p.CSRC, binary.BigEndian.Uint32(data[offset:]))
+		offset += 4
+	}
+
+	if p.Extension {
+		if len(data) < offset+4 {
+			return Packet{}, fmt.Errorf("packet too short for header extension")
+		}
+		// The extension length is in 32-bit words, excluding its own header.
+		extLen := 4 + 4*int(binary.BigEndian.Uint16(data[offset+2:]))
+		if len(data) < offset+extLen {
+			return Packet{}, fmt.Errorf("packet too short for header extension of %d bytes", extLen)
+		}
+		offset += extLen
+	}
+
+	end := len(data)
+	if p.Padding {
+		// The last byte holds the padding length, itself included.
+		padding := int(data[end-1])
+		if padding == 0 || end-padding < offset {
+			return Packet{}, fmt.Errorf("invalid padding length %d", padding)
+		}
+		end -= padding
+	}
+	p.Payload = data[offset:end]
+
+	return p, nil
+}
+
+// Marshal encodes the packet, without header extension nor padding.
+func (p Packet) Marshal() []byte {
+	data := make([]byte, rtpHeaderSize, rtpHeaderSize+4*len(p.CSRC)+len(p.Payload))
+	data[0] = rtpVersion<<6 | byte(len(p.CSRC)&0x0F)
+	data[1] = p.PayloadType & 0x7F
+	if p.Marker {
+		data[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(data[2:4], p.SequenceNumber)
+	binary.BigEndian.PutUint32(data[4:8], p.Timestamp)
+	binary.BigEndian.PutUint32(data[8:12], p.SSRC)
+	for _, csrc := range p.CSRC {
+		data = binary.BigEndian.AppendUint32(data, csrc)
+	}
+	return append(data, p.Payload...)
+}
@@ -0,0 +1,87 @@
+package rtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePacket(t *testing.T) {
+	p := Packet{
+		Header: Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    8,
+			SequenceNumber: 65535,
+			Timestamp:      0xDEADBEEF,
+			SSRC:           0x01020304,
+			CSRC:           []uint32{5, 6},
+		},
+		Payload: []byte{1, 2, 3},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		parsed, err := ParsePacket(p.Marshal())
+		require.NoError(t, err)
+		require.Equal(t, p, parsed)
+	})
+
+	t.Run("extension and padding", func(t *testing.T) {
+		data := []byte{
+			0xB0, 0x00, 0x00, 0x01, // V=2, P, X, PT=0, seq=1
+			0x00, 0x00, 0x00, 0xA0, // timestamp
+			0x00, 0x00, 0x00, 0x2A, // SSRC
+			0xBE, 0xDE, 0x00, 0x01, // extension header, one word
+			0x10, 0x20, 0x00, 0x00, // extension data
+			0xFF, 0x7F, // payload
+			0x00, 0x00, 0x03, // padding
+		}
+		parsed, err := ParsePacket(data)
+		require.NoError(t, err)
+		require.True(t, parsed.Padding)
+		require.True(t, parsed.Extension)
+		require.Equal(t, uint8(0), parsed.PayloadType)
+		require.Equal(t, uint16(1), parsed.SequenceNumber)
+		require.Equal(t, uint32(160), parsed.Timestamp)
+		require.Equal(t, uint32(42), parsed.SSRC)
+		require.Equal(t, []byte{0xFF, 0x7F}, parsed.Payload)
+	})
+
+	tcs := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{
+			name: "too short",
+			data: []byte{0x80, 0x00},
+			err:  "packet too short: 2 bytes",
+		},
+		{
+			name: "bad version",
+			data: []byte{0x40, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			err:  "unsupported RTP version 1",
+		},
+		{
+			name: "missing CSRC",
+			data: []byte{0x82, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			err:  "packet too short for 2 CSRCs",
+		},
+		{
+			name: "missing extension",
+			data: []byte{0x90, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xBE, 0xDE, 0, 2, 0, 0, 0, 0},
+			err:  "packet too short for header extension of 12 bytes",
+		},
+		{
+			name: "bad padding",
+			data: []byte{0xA0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5},
+			err:  "invalid padding length 5",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParsePacket(tc.data)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package speaker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+// ErrClosed is returned when updating a closed Tracker.
+var ErrClosed = errors.New("tracker is closed")
+
+type Config struct {
+	// The minimum duration a stream should be speaking for before it can
+	// become the dominant speaker. Defaults to 300ms.
+	MinSpeakingTime time.Duration
+	// The duration the dominant speaker is kept for once it stops speaking,
+	// so that short pauses don't cause switches. Defaults to 1s.
+	HoldTime time.Duration
+	// The margin by which the activity of a stream should exceed the one of
+	// the dominant speaker to take over while the latter is speaking.
+	// Defaults to 0.2.
+	SwitchMargin float32
+	// The time constant of the exponential moving average smoothing speech
+	// probabilities into activity levels. Defaults to 500ms.
+	Smoothing time.Duration
+	// The number of notifications buffered. The oldest ones get dropped when
+	// the buffer is full. Defaults to 16.
+	ChangeBuffer int
+}
+
+func (c Config) IsValid() error {
+	if c.MinSpeakingTime < 0 {
+		return fmt.Errorf("invalid MinSpeakingTime: should be a positive duration")
+	}
+
+	if c.HoldTime < 0 {
+		return fmt.Errorf("invalid HoldTime: should be a positive duration")
+	}
+
+	if c.SwitchMargin < 0 || c.SwitchMargin >= 1 {
+		return fmt.Errorf("invalid SwitchMargin: should be in range [0, 1)")
+	}
+
+	if c.Smoothing < 0 {
+		return fmt.Errorf("invalid Smoothing: should be a positive duration")
+	}
+
+	if c.ChangeBuffer < 0 {
+		return fmt.Errorf("invalid ChangeBuffer: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	if c.MinSpeakingTime == 0 {
+		c.MinSpeakingTime = 300 * time.Millisecond
+	}
+	if c.HoldTime == 0 {
+		c.HoldTime = time.Second
+	}
+	if c.SwitchMargin == 0 {
+		c.SwitchMargin = 0.2
+	}
+	if c.Smoothing == 0 {
+		c.Smoothing = 500 * time.Millisecond
+	}
+	if c.ChangeBuffer == 0 {
+		c.ChangeBuffer = 16
+	}
+}
+
+// Change notifies that the dominant speaker changed.
+type Change struct {
+	// The ID of the new dominant speaker, empty if it was removed without
+	// another stream taking over.
+	Speaker string
+	// The ID of the previous dominant speaker, empty if there was none.
+	Previous string
+	// The time of the update triggering the change.
+	At time.Time
+}
+
+// Tracker decides the dominant speaker across many streams, each running its
+// own detector. It's fed speech events, and optionally probabilities, of
+// every stream along with their arrival time, decisions being taken as
+// updates come in.
+//
+// A stream becomes eligible once speaking for MinSpeakingTime, and the
+// eligible stream with the highest activity becomes dominant when there is
+// none or the dominant one stopped speaking for HoldTime. While the dominant
+// speaker is speaking, another stream only takes over if its activity exceeds
+// the dominant one by SwitchMargin. Activity is the smoothed speech
+// probability, or 1 while speaking and 0 otherwise for streams not reporting
+// probabilities. The dominant speaker is kept during silence until another
+// stream qualifies or it gets removed.
+type Tracker struct {
+	cfg     Config
+	changes chan Change
+
+	mu       sync.Mutex
+	streams  map[string]*streamState
+	dominant string
+	closed   bool
+}
+
+type streamState struct {
+	speaking      bool
+	speakingSince time.Time
+	silentSince   time.Time
+
+	hasProbs     bool
+	activity     float64
+	lastProbTime time.Time
+}
+
+func NewTracker(cfg Config) (*Tracker, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.setDefaults()
+
+	return &Tracker{
+		cfg:     cfg,
+		changes: make(chan Change, cfg.ChangeBuffer),
+		streams: make(map[string]*streamState),
+	}, nil
+}
+
+// Changes returns the channel dominant speaker changes are delivered on. It
+// gets closed by Close.
+func (t *Tracker) Changes() <-chan Change {
+	return t.changes
+}
+
+// Dominant returns the ID of the dominant speaker, empty if there is none.
+func (t *Tracker) Dominant() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dominant
+}
+
+// SetSpeaking updates whether the stream with the given ID is speaking.
+// Unknown streams get added.
+func (t *Tracker) SetSpeaking(id string, speaking bool, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+
+	st := t.stream(id)
+	if speaking != st.speaking {
+		st.speaking = speaking
+		if speaking {
+			st.speakingSince = at
+		} else {
+			st.silentSince = at
+		}
+	}
+	t.update(at)
+
+	return nil
+}
+
+// SetProbability updates the speech probability of the stream with the given
+// ID, smoothing it into its activity level. Unknown streams get added.
+func (t *Tracker) SetProbability(id string, prob float32, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+
+	st := t.stream(id)
+	if !st.hasProbs {
+		st.hasProbs = true
+		st.activity = float64(prob)
+	} else {
+		dt := at.Sub(st.lastProbTime).Seconds()
+		alpha := 1 - math.Exp(-max(dt, 0)/t.cfg.Smoothing.Seconds())
+		st.activity += alpha * (float64(prob) - st.activity)
+	}
+	st.lastProbTime = at
+	t.update(at)
+
+	return nil
+}
+
+// HandleEvent updates the stream with the given ID from a stream event.
+func (t *Tracker) HandleEvent(id string, ev stream.Event, at time.Time) error {
+	switch ev.Type {
+	case stream.EventSpeechStart:
+		return t.SetSpeaking(id, true, at)
+	case stream.EventSpeechEnd:
+		return t.SetSpeaking(id, false, at)
+	case stream.EventProbability:
+		return t.SetProbability(id, ev.Probability, at)
+	default:
+		return nil
+	}
+}
+
+// Remove stops tracking the stream with the given ID. If it was the dominant
+// speaker, another eligible stream takes over if any, otherwise the dominant
+// speaker is cleared, the change reporting the removed stream as Previous.
+func (t *Tracker) Remove(id string, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+
+	// The dominant speaker is only cleared once update had a chance to report
+	// it as the previous one.
+	delete(t.streams, id)
+	t.update(at)
+	if t.dominant == id {
+		t.notify(Change{Previous: id, At: at})
+		t.dominant = ""
+	}
+
+	return nil
+}
+
+// Close stops tracking and closes the changes channel.
+func (t *Tracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+	close(t.changes)
+
+	return nil
+}
+
+func (t *Tracker) stream(id string) *streamState {
+	st, ok := t.streams[id]
+	if !ok {
+		st = &streamState{}
+		t.streams[id] = st
+	}
+	return st
+}
+
+func (st *streamState) level() float64 {
+	if st.hasProbs {
+		return st.activity
+	}
+	if st.speaking {
+		return 1
+	}
+	return 0
+}
+
+// update decides the dominant speaker at the given time.
+func (t *Tracker) update(at time.Time) {
+	var candidate string
+	var best *streamState
+	for id, st := range t.streams {
+		if id == t.dominant || !st.speaking || at.Sub(st.speakingSince) < t.cfg.MinSpeakingTime {
+			continue
+		}
+		// Ties are broken by ID to keep decisions deterministic.
+		if best == nil || st.level() > best.level() || (st.level() == best.level() && id < candidate) {
+			candidate, best = id, st
+		}
+	}
+	if best == nil {
+		return
+	}
+
+	if dom, ok := t.streams[t.dominant]; ok {
+		if dom.speaking && best.level() <= dom.level()+float64(t.cfg.SwitchMargin) {
+			return
+		}
+		if !dom.speaking && at.Sub(dom.silentSince) < t.cfg.HoldTime {
+			return
+		}
+	}
+
+	t.notify(Change{Speaker: candidate, Previous: t.dominant, At: at})
+	t.dominant = candidate
+}
+
+// notify delivers c, dropping the oldest notification if the buffer is full.
+func (t *Tracker) notify(c Change) {
+	for {
+		select {
+		case t.changes <- c:
+			return
+		default:
+		}
+		select {
+		case <-t.changes:
+		default:
+		}
+	}
+}
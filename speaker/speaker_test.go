@@ -0,0 +1,216 @@
+package speaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{
+		MinSpeakingTime: time.Second,
+		HoldTime:        time.Second,
+		SwitchMargin:    0.5,
+		Smoothing:       time.Second,
+		ChangeBuffer:    1,
+	}.IsValid())
+
+	tcs := []struct {
+		cfg Config
+		err string
+	}{
+		{Config{MinSpeakingTime: -1}, "invalid MinSpeakingTime: should be a positive duration"},
+		{Config{HoldTime: -1}, "invalid HoldTime: should be a positive duration"},
+		{Config{SwitchMargin: -0.1}, "invalid SwitchMargin: should be in range [0, 1)"},
+		{Config{SwitchMargin: 1}, "invalid SwitchMargin: should be in range [0, 1)"},
+		{Config{Smoothing: -1}, "invalid Smoothing: should be a positive duration"},
+		{Config{ChangeBuffer: -1}, "invalid ChangeBuffer: should be a positive number"},
+	}
+	for _, tc := range tcs {
+		require.EqualError(t, tc.cfg.IsValid(), tc.err)
+	}
+
+	_, err := NewTracker(Config{HoldTime: -1})
+	require.EqualError(t, err, "invalid config: invalid HoldTime: should be a positive duration")
+}
+
+func TestTracker(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ms := func(n int) time.Time {
+		return t0.Add(time.Duration(n) * time.Millisecond)
+	}
+
+	// update is a speaking change (prob < 0), probability or removal of a stream.
+	type update struct {
+		at     int
+		id     string
+		prob   float32
+		on     bool
+		remove bool
+	}
+	speak := func(at int, id string, on bool) update {
+		return update{at: at, id: id, prob: -1, on: on}
+	}
+	prob := func(at int, id string, p float32) update {
+		return update{at: at, id: id, prob: p}
+	}
+
+	tcs := []struct {
+		name     string
+		updates  []update
+		expected []Change
+	}{
+		{
+			name: "min speaking time",
+			updates: []update{
+				speak(0, "a", true),
+				speak(200, "a", false),
+				speak(300, "b", true),
+				speak(500, "b", true),
+				speak(600, "b", true),
+			},
+			expected: []Change{{Speaker: "b", At: ms(600)}},
+		},
+		{
+			name: "hold time",
+			updates: []update{
+				speak(0, "a", true),
+				speak(300, "a", true),
+				speak(1000, "a", false),
+				speak(1100, "b", true),
+				speak(1500, "b", true),
+				speak(1900, "b", true),
+				speak(2000, "b", true),
+			},
+			expected: []Change{
+				{Speaker: "a", At: ms(300)},
+				{Speaker: "b", Previous: "a", At: ms(2000)},
+			},
+		},
+		{
+			name: "no switch while speaking without margin",
+			updates: []update{
+				speak(0, "a", true),
+				speak(300, "a", true),
+				speak(400, "b", true),
+				speak(800, "b", true),
+				speak(2000, "b", true),
+			},
+			expected: []Change{{Speaker: "a", At: ms(300)}},
+		},
+		{
+			name: "switch by margin",
+			updates: []update{
+				speak(0, "a", true),
+				prob(0, "a", 0.6),
+				speak(300, "a", true),
+				speak(400, "b", true),
+				prob(400, "b", 0.7),
+				prob(800, "b", 0.7),
+				prob(900, "b", 0.95),
+				prob(900, "a", 0.6),
+				prob(5000, "b", 0.95),
+			},
+			expected: []Change{
+				{Speaker: "a", At: ms(300)},
+				{Speaker: "b", Previous: "a", At: ms(5000)},
+			},
+		},
+		{
+			name: "highest activity wins",
+			updates: []update{
+				speak(0, "a", true),
+				prob(0, "a", 0.6),
+				speak(0, "b", true),
+				prob(0, "b", 0.9),
+				prob(300, "a", 0.6),
+			},
+			expected: []Change{{Speaker: "b", At: ms(300)}},
+		},
+		{
+			name: "removal",
+			updates: []update{
+				speak(0, "a", true),
+				speak(0, "b", true),
+				speak(300, "a", true),
+				{at: 400, id: "a", remove: true},
+			},
+			expected: []Change{
+				{Speaker: "a", At: ms(300)},
+				{Speaker: "b", Previous: "a", At: ms(400)},
+			},
+		},
+		{
+			name: "removal without takeover",
+			updates: []update{
+				speak(0, "a", true),
+				speak(300, "a", true),
+				{at: 400, id: "a", remove: true},
+				speak(500, "b", true),
+				speak(800, "b", true),
+			},
+			expected: []Change{
+				{Speaker: "a", At: ms(300)},
+				{Previous: "a", At: ms(400)},
+				{Speaker: "b", At: ms(800)},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, err := NewTracker(Config{})
+			require.NoError(t, err)
+
+			for _, u := range tc.updates {
+				switch {
+				case u.remove:
+					require.NoError(t, tr.Remove(u.id, ms(u.at)))
+				case u.prob < 0:
+					require.NoError(t, tr.SetSpeaking(u.id, u.on, ms(u.at)))
+				default:
+					require.NoError(t, tr.SetProbability(u.id, u.prob, ms(u.at)))
+				}
+			}
+			require.NoError(t, tr.Close())
+
+			var changes []Change
+			for c := range tr.Changes() {
+				changes = append(changes, c)
+			}
+			require.Equal(t, tc.expected, changes)
+			if len(changes) > 0 {
+				require.Equal(t, changes[len(changes)-1].Speaker, tr.Dominant())
+			}
+		})
+	}
+}
+
+func TestTrackerEvents(t *testing.T) {
+	tr, err := NewTracker(Config{MinSpeakingTime: time.Millisecond, ChangeBuffer: 1})
+	require.NoError(t, err)
+
+	t0 := time.Now()
+	require.NoError(t, tr.HandleEvent("a", stream.Event{Type: stream.EventSpeechStart}, t0))
+	require.NoError(t, tr.HandleEvent("a", stream.Event{Type: stream.EventProbability, Probability: 0.9}, t0.Add(time.Second)))
+	require.Equal(t, "a", tr.Dominant())
+	require.NoError(t, tr.HandleEvent("a", stream.Event{Type: stream.EventSpeechEnd}, t0.Add(2*time.Second)))
+
+	// Switching twice with a single slot buffer, keeping the latest change.
+	require.NoError(t, tr.HandleEvent("b", stream.Event{Type: stream.EventSpeechStart}, t0.Add(3*time.Second)))
+	require.NoError(t, tr.HandleEvent("b", stream.Event{Type: stream.EventSpeechStart}, t0.Add(4*time.Second)))
+	require.NoError(t, tr.Remove("b", t0.Add(5*time.Second)))
+	require.NoError(t, tr.HandleEvent("a", stream.Event{Type: stream.EventSpeechStart}, t0.Add(6*time.Second)))
+	require.NoError(t, tr.HandleEvent("a", stream.Event{Type: stream.EventSpeechStart}, t0.Add(7*time.Second)))
+
+	require.Equal(t, Change{Speaker: "a", At: t0.Add(7 * time.Second)}, <-tr.Changes())
+
+	require.NoError(t, tr.Close())
+	require.ErrorIs(t, tr.Close(), ErrClosed)
+	require.ErrorIs(t, tr.SetSpeaking("a", true, t0), ErrClosed)
+	require.ErrorIs(t, tr.SetProbability("a", 0.5, t0), ErrClosed)
+	require.ErrorIs(t, tr.Remove("a", t0), ErrClosed)
+}
@@ -0,0 +1,160 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/streamer45/silero-vad-go/audio"
+)
+
+// AdaptiveThresholdConfig configures the adaptation of the speech threshold
+// to the background noise. Non-speech windows not louder than the running
+// noise floor by more than EnergyMarginDb update a running estimate of the
+// noise probabilities, and the effective threshold follows their mean plus
+// two standard deviations plus Margin, within [MinThreshold, MaxThreshold].
+type AdaptiveThresholdConfig struct {
+	// Whether to adapt the threshold. The configured Threshold is used as
+	// starting point.
+	Enabled bool
+	// The lower bound of the effective threshold. Defaults to 0.3.
+	MinThreshold float32
+	// The upper bound of the effective threshold. Defaults to 0.8.
+	MaxThreshold float32
+	// The margin above noise probabilities. Defaults to 0.25.
+	Margin float32
+	// The time constant of the running estimates, in milliseconds. Defaults
+	// to 5000.
+	AdaptationMs int
+	// The level above the noise floor, in dB, beyond which windows aren't
+	// considered noise, being likely speech missed by the threshold. Defaults
+	// to 6.
+	EnergyMarginDb float64
+}
+
+func (c AdaptiveThresholdConfig) IsValid() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	c.setDefaults()
+
+	if c.MinThreshold <= 0 || c.MinThreshold >= 1 {
+		return fmt.Errorf("invalid MinThreshold: should be in range (0, 1)")
+	}
+
+	if c.MaxThreshold <= 0 || c.MaxThreshold >= 1 {
+		return fmt.Errorf("invalid MaxThreshold: should be in range (0, 1)")
+	}
+
+	if c.MinThreshold > c.MaxThreshold {
+		return fmt.Errorf("invalid MaxThreshold: should be greater than MinThreshold")
+	}
+
+	if c.Margin < 0 || c.Margin >= 1 {
+		return fmt.Errorf("invalid Margin: should be in range [0, 1)")
+	}
+
+	if c.AdaptationMs < 0 {
+		return fmt.Errorf("invalid AdaptationMs: should be a positive number")
+	}
+
+	if c.EnergyMarginDb < 0 {
+		return fmt.Errorf("invalid EnergyMarginDb: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c *AdaptiveThresholdConfig) setDefaults() {
+	if c.MinThreshold == 0 {
+		c.MinThreshold = 0.3
+	}
+	if c.MaxThreshold == 0 {
+		c.MaxThreshold = 0.8
+	}
+	if c.Margin == 0 {
+		c.Margin = 0.25
+	}
+	if c.AdaptationMs == 0 {
+		c.AdaptationMs = 5000
+	}
+	if c.EnergyMarginDb == 0 {
+		c.EnergyMarginDb = 6
+	}
+}
+
+// The level assigned to digital silence, in dB.
+const minLevelDb = -100
+
+// noiseEstimator tracks non-speech probabilities and levels to adapt the
+// speech threshold.
+type noiseEstimator struct {
+	cfg AdaptiveThresholdConfig
+	// The weight of each window in the running estimates.
+	alpha float64
+
+	// The running mean and variance of noise probabilities.
+	mean     float64
+	variance float64
+	// The running noise floor in dB, valid once hasFloor is set.
+	floorDb  float64
+	hasFloor bool
+
+	threshold float32
+}
+
+func newNoiseEstimator(cfg AdaptiveThresholdConfig, threshold float32, windowMs float64) *noiseEstimator {
+	cfg.setDefaults()
+	e := &noiseEstimator{
+		cfg:   cfg,
+		alpha: 1 - math.Exp(-windowMs/float64(cfg.AdaptationMs)),
+	}
+	e.reset(threshold)
+	return e
+}
+
+// initialThreshold returns the effective threshold adaptation starts from.
+func (e *noiseEstimator) initialThreshold(threshold float32) float32 {
+	return min(max(threshold, e.cfg.MinThreshold), e.cfg.MaxThreshold)
+}
+
+// reset starts over from threshold.
+func (e *noiseEstimator) reset(threshold float32) {
+	e.threshold = e.initialThreshold(threshold)
+	// Starting from the noise estimate matching the threshold.
+	e.mean = float64(threshold - e.cfg.Margin)
+	e.variance = 0
+	e.floorDb = 0
+	e.hasFloor = false
+}
+
+// update accounts for a window of the given speech probability and RMS level,
+// which is noise if speech isn't triggered nor detected in it.
+func (e *noiseEstimator) update(prob float32, rms float64, speech bool) {
+	if speech {
+		return
+	}
+
+	levelDb := math.Max(audio.ToDecibels(rms), minLevelDb)
+	switch {
+	case !e.hasFloor:
+		e.floorDb = levelDb
+		e.hasFloor = true
+	case levelDb < e.floorDb:
+		// Following drops quickly, the floor being the quietest noise.
+		e.floorDb += math.Min(10*e.alpha, 1) * (levelDb - e.floorDb)
+	default:
+		e.floorDb += e.alpha * (levelDb - e.floorDb)
+	}
+
+	if levelDb > e.floorDb+e.cfg.EnergyMarginDb {
+		return
+	}
+
+	delta := float64(prob) - e.mean
+	e.mean += e.alpha * delta
+	e.variance = (1 - e.alpha) * (e.variance + e.alpha*delta*delta)
+
+	threshold := float32(e.mean+2*math.Sqrt(e.variance)) + e.cfg.Margin
+	e.threshold = min(max(threshold, e.cfg.MinThreshold), e.cfg.MaxThreshold)
+}
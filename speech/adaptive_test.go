@@ -0,0 +1,186 @@
+package speech
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+func TestAdaptiveThresholdConfigIsValid(t *testing.T) {
+	require.NoError(t, AdaptiveThresholdConfig{}.IsValid())
+	// Disabled adaptation isn't validated.
+	require.NoError(t, AdaptiveThresholdConfig{MinThreshold: 2}.IsValid())
+	require.NoError(t, AdaptiveThresholdConfig{Enabled: true}.IsValid())
+	require.NoError(t, AdaptiveThresholdConfig{
+		Enabled:        true,
+		MinThreshold:   0.2,
+		MaxThreshold:   0.9,
+		Margin:         0.1,
+		AdaptationMs:   1000,
+		EnergyMarginDb: 3,
+	}.IsValid())
+
+	tcs := []struct {
+		cfg AdaptiveThresholdConfig
+		err string
+	}{
+		{AdaptiveThresholdConfig{Enabled: true, MinThreshold: 1}, "invalid MinThreshold: should be in range (0, 1)"},
+		{AdaptiveThresholdConfig{Enabled: true, MaxThreshold: -0.1}, "invalid MaxThreshold: should be in range (0, 1)"},
+		{AdaptiveThresholdConfig{Enabled: true, MinThreshold: 0.6, MaxThreshold: 0.5}, "invalid MaxThreshold: should be greater than MinThreshold"},
+		{AdaptiveThresholdConfig{Enabled: true, Margin: -0.1}, "invalid Margin: should be in range [0, 1)"},
+		{AdaptiveThresholdConfig{Enabled: true, AdaptationMs: -1}, "invalid AdaptationMs: should be a positive number"},
+		{AdaptiveThresholdConfig{Enabled: true, EnergyMarginDb: -1}, "invalid EnergyMarginDb: should be a positive number"},
+	}
+	for _, tc := range tcs {
+		require.EqualError(t, tc.cfg.IsValid(), tc.err)
+	}
+}
+
+func TestNoiseEstimator(t *testing.T) {
+	newEstimator := func() *noiseEstimator {
+		return newNoiseEstimator(AdaptiveThresholdConfig{Enabled: true, AdaptationMs: 1000}, 0.5, 32)
+	}
+
+	t.Run("quiet", func(t *testing.T) {
+		e := newEstimator()
+		require.Equal(t, float32(0.5), e.threshold)
+		for i := 0; i < 500; i++ {
+			e.update(0.02, 0.001, false)
+		}
+		require.Equal(t, float32(0.3), e.threshold)
+		require.InDelta(t, -60, e.floorDb, 1e-6)
+	})
+
+	t.Run("noisy", func(t *testing.T) {
+		e := newEstimator()
+		for i := 0; i < 500; i++ {
+			e.update(0.3+0.1*float32(i%2), 0.1, false)
+		}
+		// Mean 0.35 and standard deviation 0.05 plus the margin.
+		require.InDelta(t, 0.7, e.threshold, 0.01)
+
+		for i := 0; i < 500; i++ {
+			e.update(0.6, 0.1, false)
+		}
+		require.Equal(t, float32(0.8), e.threshold)
+	})
+
+	t.Run("speech and loud windows ignored", func(t *testing.T) {
+		e := newEstimator()
+		for i := 0; i < 500; i++ {
+			e.update(0.02, 0.001, false)
+		}
+		threshold := e.threshold
+		for i := 0; i < 10; i++ {
+			e.update(0.9, 0.3, true)
+			e.update(0.4, 0.3, false)
+		}
+		require.Equal(t, threshold, e.threshold)
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		e := newEstimator()
+		e.update(0.01, 0, false)
+		require.Equal(t, float64(minLevelDb), e.floorDb)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		e := newEstimator()
+		for i := 0; i < 500; i++ {
+			e.update(0.02, 0.001, false)
+		}
+		e.reset(0.6)
+		require.Equal(t, float32(0.6), e.threshold)
+		require.False(t, e.hasFloor)
+
+		// Starting points are clamped to the bounds.
+		e.reset(0.9)
+		require.Equal(t, float32(0.8), e.threshold)
+	})
+}
+
+func TestAdaptiveThreshold(t *testing.T) {
+	sd, err := NewDetector(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+		AdaptiveThreshold: AdaptiveThresholdConfig{
+			Enabled:      true,
+			AdaptationMs: 500,
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+	require.Equal(t, float32(0.5), sd.EffectiveThreshold())
+
+	// Silence lowers the threshold to its lower bound.
+	_, err = sd.DetectStream(make([]float32, 16000*5))
+	require.NoError(t, err)
+	require.Equal(t, float32(0.3), sd.EffectiveThreshold())
+	require.Equal(t, float32(0.5), sd.Config().Threshold)
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	segments, err := sd.DetectStream(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+	threshold := sd.EffectiveThreshold()
+	require.GreaterOrEqual(t, threshold, float32(0.3))
+	require.LessOrEqual(t, threshold, float32(0.8))
+
+	require.NoError(t, sd.Reset())
+	require.Equal(t, float32(0.5), sd.EffectiveThreshold())
+
+	sd.SetThreshold(0.6)
+	require.Equal(t, float32(0.6), sd.EffectiveThreshold())
+
+	var nilDetector *Detector
+	require.Zero(t, nilDetector.EffectiveThreshold())
+}
+
+func TestSetThreshold(t *testing.T) {
+	cfg := DetectorConfig{SampleRate: 16000, Threshold: 0.5}
+	sd := &Detector{
+		cfg:   cfg,
+		seg:   vad.NewSegmenter(cfg.segmenterConfig(512)),
+		noise: newNoiseEstimator(AdaptiveThresholdConfig{Enabled: true, AdaptationMs: 1000}, 0.5, 32),
+	}
+	sd.baseThreshold.Store(0.5)
+	window := make([]float32, 512)
+
+	// Adaptation starts over from the clamped threshold.
+	sd.SetThreshold(0.9)
+	require.Equal(t, float32(0.9), sd.Config().Threshold)
+	require.Equal(t, float32(0.8), sd.EffectiveThreshold())
+	_, err := sd.advance(0.2, window)
+	require.NoError(t, err)
+	require.Equal(t, float32(0.2), sd.LastProbability())
+	require.LessOrEqual(t, sd.EffectiveThreshold(), float32(0.8))
+
+	// Getters and SetThreshold can be called while detection runs.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			sd.SetThreshold(0.4 + 0.1*float32(i%2))
+			_ = sd.Config()
+			_ = sd.EffectiveThreshold()
+			_ = sd.LastProbability()
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		_, err := sd.advance(0.1, window)
+		require.NoError(t, err)
+	}
+	wg.Wait()
+
+	sd.SetThreshold(0.6)
+	require.NoError(t, sd.Reset())
+	require.Equal(t, float32(0.6), sd.EffectiveThreshold())
+	require.Zero(t, sd.LastProbability())
+}
@@ -6,12 +6,22 @@ package speech
 import "C"
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"strings"
+	"sync/atomic"
 	"unsafe"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/vad"
 )
 
 const (
+	// The size of the buffer holding the recurrent state tensors. It fits
+	// both the v5 state (2x1x128) and the v4 h and c tensors (2x1x64 each).
 	stateLen   = 2 * 1 * 128
 	contextLen = 64
 )
@@ -43,6 +53,33 @@ const (
 	LogLevelFatal
 )
 
+func (l LogLevel) String() string {
+	switch l {
+	case LevelVerbose:
+		return "verbose"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	case LogLevelFatal:
+		return "fatal"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// ParseLogLevel parses a log level from its string representation (e.g. "warn").
+func ParseLogLevel(s string) (LogLevel, error) {
+	for l := LevelVerbose; l <= LogLevelFatal; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid log level %q", s)
+}
+
 type DetectorConfig struct {
 	// The path to the ONNX Silero VAD model file to load.
 	ModelPath string
@@ -56,6 +93,13 @@ type DetectorConfig struct {
 	SpeechPadMs int
 	// The loglevel for the onnx environment, by default it is set to LogLevelWarn.
 	LogLevel LogLevel
+	// The version of the Silero VAD model. By default (ModelVersionAuto) it is
+	// detected from the model metadata and signature.
+	ModelVersion ModelVersion
+	// The optional adaptation of Threshold to the background noise.
+	AdaptiveThreshold AdaptiveThresholdConfig
+	// The optional skipping of inference on windows of near silence.
+	EnergyGate EnergyGateConfig
 }
 
 func (c DetectorConfig) IsValid() error {
@@ -63,6 +107,37 @@ func (c DetectorConfig) IsValid() error {
 		return fmt.Errorf("invalid ModelPath: should not be empty")
 	}
 
+	if err := c.validateSegmentation(); err != nil {
+		return err
+	}
+
+	if c.ModelVersion < ModelVersionAuto || c.ModelVersion > ModelVersionV6 {
+		return fmt.Errorf("invalid ModelVersion: unknown version")
+	}
+
+	if err := c.AdaptiveThreshold.IsValid(); err != nil {
+		return fmt.Errorf("invalid AdaptiveThreshold: %w", err)
+	}
+
+	if err := c.EnergyGate.IsValid(); err != nil {
+		return fmt.Errorf("invalid EnergyGate: %w", err)
+	}
+
+	return nil
+}
+
+// segmenterConfig returns the segmentation settings for windows of windowSize.
+func (c DetectorConfig) segmenterConfig(windowSize int) vad.SegmenterConfig {
+	return vad.SegmenterConfig{
+		SampleRate:           c.SampleRate,
+		WindowSize:           windowSize,
+		MinSilenceDurationMs: c.MinSilenceDurationMs,
+		SpeechPadMs:          c.SpeechPadMs,
+	}
+}
+
+// validateSegmentation validates the fields affecting segmentation only.
+func (c DetectorConfig) validateSegmentation() error {
 	if c.SampleRate != 8000 && c.SampleRate != 16000 {
 		return fmt.Errorf("invalid SampleRate: valid values are 8000 and 16000")
 	}
@@ -82,131 +157,223 @@ func (c DetectorConfig) IsValid() error {
 	return nil
 }
 
+const (
+	detectorIdle uint32 = iota
+	detectorBusy
+	detectorDestroyed
+)
+
 type Detector struct {
+	// status guards against concurrent use and use after destroy.
+	status atomic.Uint32
+
 	api         *C.OrtApi
 	env         *C.OrtEnv
 	sessionOpts *C.OrtSessionOptions
 	session     *C.OrtSession
 	memoryInfo  *C.OrtMemoryInfo
+	runOpts     *C.OrtRunOptions
+	binding     *C.OrtIoBinding
+	values      []*C.OrtValue
+	buffers     []unsafe.Pointer
 	cStrings    map[string]*C.char
 
-	cfg DetectorConfig
+	cfg       DetectorConfig
+	modelInfo ModelInfo
+	variant   modelVariant
+
+	// Model inputs and outputs, allocated in C memory and bound to the
+	// session once.
+	state    []float32
+	stateOut []float32
+	prob     *float32
+	rate     *C.int64_t
+
+	windowSize int
+	inputBuf   []float32
+
+	seg       vad.Segmenter
+	streamBuf []float32
+
+	// The threshold as configured or changed by SetThreshold, the effective
+	// one and the probability of the last window, which can be read
+	// concurrently with detection.
+	baseThreshold      atomicFloat32
+	effectiveThreshold atomicFloat32
+	lastProb           atomicFloat32
+	// Whether SetThreshold was called since the last window, for detection
+	// to start threshold adaptation over.
+	thresholdChanged atomic.Bool
+
+	// The noise estimator adapting the threshold, nil unless enabled.
+	noise *noiseEstimator
+	// The energy gate skipping inference, nil unless enabled.
+	gate *energyGate
+
+	// Counters reported by Stats, which can be read concurrently.
+	windows        atomic.Uint64
+	skippedWindows atomic.Uint64
+
+	// The detectors used by DetectParallel, created on first use and
+	// destroyed along with this one.
+	workers []*Detector
+}
 
-	state [stateLen]float32
+// acquire marks the detector as busy. The caller must call release once done.
+func (sd *Detector) acquire() error {
+	if sd.status.CompareAndSwap(detectorIdle, detectorBusy) {
+		return nil
+	}
+	if sd.status.Load() == detectorDestroyed {
+		return ErrDestroyed
+	}
+	return ErrConcurrentUse
+}
 
-	windowSize    int
-	inputBuf      []float32
-	pcmInputDims  [2]C.int64_t
-	stateDims     [3]C.int64_t
-	rateInputDims [1]C.int64_t
-	rateValue     C.int64_t
+func (sd *Detector) release() {
+	sd.status.Store(detectorIdle)
+}
 
-	pendingStart      float64
-	pendingStartValid bool
-	streamBuf         []float32
+// atomicFloat32 is a float32 which can be read and written concurrently.
+type atomicFloat32 struct {
+	bits atomic.Uint32
+}
 
-	currSample int
-	triggered  bool
-	tempEnd    int
+func (f *atomicFloat32) Load() float32 {
+	return math.Float32frombits(f.bits.Load())
 }
 
-func windowSizeForSampleRate(sampleRate int) int {
-	if sampleRate == 8000 {
-		return 256
-	}
-	return 512
+func (f *atomicFloat32) Store(value float32) {
+	f.bits.Store(math.Float32bits(value))
 }
 
 func NewDetector(cfg DetectorConfig) (*Detector, error) {
 	if err := cfg.IsValid(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
-	sd := Detector{
+	sd := &Detector{
 		cfg:      cfg,
 		cStrings: map[string]*C.char{},
 	}
-	sd.windowSize = windowSizeForSampleRate(cfg.SampleRate)
-	sd.inputBuf = make([]float32, contextLen+sd.windowSize)
-	sd.pcmInputDims = [2]C.int64_t{1, C.int64_t(len(sd.inputBuf))}
-	sd.stateDims = [3]C.int64_t{2, 1, 128}
-	sd.rateInputDims = [1]C.int64_t{1}
-	sd.rateValue = C.int64_t(cfg.SampleRate)
+	sd.windowSize = vad.WindowSizeForSampleRate(cfg.SampleRate)
 	sd.streamBuf = make([]float32, 0, sd.windowSize)
+	sd.seg = vad.NewSegmenter(cfg.segmenterConfig(sd.windowSize))
+	if cfg.AdaptiveThreshold.Enabled {
+		windowMs := float64(sd.windowSize) * 1000 / float64(cfg.SampleRate)
+		sd.noise = newNoiseEstimator(cfg.AdaptiveThreshold, cfg.Threshold, windowMs)
+	}
+	if cfg.EnergyGate.Enabled {
+		sd.gate = newEnergyGate(cfg.EnergyGate, sd.windowSize, cfg.SampleRate)
+	}
+	sd.baseThreshold.Store(cfg.Threshold)
+	sd.effectiveThreshold.Store(sd.threshold())
 
 	sd.api = C.OrtGetApi()
 	if sd.api == nil {
-		return nil, fmt.Errorf("failed to get API")
+		return nil, fmt.Errorf("%w: failed to get API", ErrRuntime)
+	}
+
+	if err := sd.setup(); err != nil {
+		_ = sd.Destroy()
+		return nil, err
 	}
 
+	return sd, nil
+}
+
+func (sd *Detector) setup() error {
 	sd.cStrings["loggerName"] = C.CString("vad")
-	status := C.OrtApiCreateEnv(sd.api, cfg.LogLevel.OrtLoggingLevel(), sd.cStrings["loggerName"], &sd.env)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to create env: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiCreateEnv(sd.api, sd.cfg.LogLevel.OrtLoggingLevel(), sd.cStrings["loggerName"], &sd.env); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("create env", status))
 	}
 
-	status = C.OrtApiCreateSessionOptions(sd.api, &sd.sessionOpts)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to create session options: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiCreateSessionOptions(sd.api, &sd.sessionOpts); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("create session options", status))
 	}
 
-	status = C.OrtApiSetIntraOpNumThreads(sd.api, sd.sessionOpts, 1)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to set intra threads: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiSetIntraOpNumThreads(sd.api, sd.sessionOpts, 1); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("set intra threads", status))
 	}
 
-	status = C.OrtApiSetInterOpNumThreads(sd.api, sd.sessionOpts, 1)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to set inter threads: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiSetInterOpNumThreads(sd.api, sd.sessionOpts, 1); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("set inter threads", status))
 	}
 
-	status = C.OrtApiSetSessionGraphOptimizationLevel(sd.api, sd.sessionOpts, C.ORT_ENABLE_ALL)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to set session graph optimization level: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiSetSessionGraphOptimizationLevel(sd.api, sd.sessionOpts, C.ORT_ENABLE_ALL); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("set session graph optimization level", status))
 	}
 
 	sd.cStrings["modelPath"] = C.CString(sd.cfg.ModelPath)
-	status = C.OrtApiCreateSession(sd.api, sd.env, sd.cStrings["modelPath"], sd.sessionOpts, &sd.session)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to create session: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiCreateSession(sd.api, sd.env, sd.cStrings["modelPath"], sd.sessionOpts, &sd.session); status != nil {
+		return fmt.Errorf("%w: %w", ErrModelLoad, sd.ortError("create session", status))
+	}
+
+	if status := C.OrtApiCreateCpuMemoryInfo(sd.api, C.OrtArenaAllocator, C.OrtMemTypeDefault, &sd.memoryInfo); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("create memory info", status))
 	}
 
-	status = C.OrtApiCreateCpuMemoryInfo(sd.api, C.OrtArenaAllocator, C.OrtMemTypeDefault, &sd.memoryInfo)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to create memory info: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	if status := C.OrtApiCreateRunOptions(sd.api, &sd.runOpts); status != nil {
+		return fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("create run options", status))
 	}
 
-	sd.cStrings["input"] = C.CString("input")
-	sd.cStrings["sr"] = C.CString("sr")
-	sd.cStrings["state"] = C.CString("state")
-	sd.cStrings["stateN"] = C.CString("stateN")
-	sd.cStrings["output"] = C.CString("output")
+	info, err := sd.loadModelInfo()
+	if err != nil {
+		return fmt.Errorf("%w: failed to load model info: %w", ErrModelLoad, err)
+	}
+	sd.modelInfo = info
+
+	if sd.cfg.ModelVersion == ModelVersionAuto {
+		sd.variant, err = detectModelVariant(info)
+	} else {
+		sd.variant, _ = variantForVersion(sd.cfg.ModelVersion)
+		err = sd.variant.signature.validate(info)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrModelLoad, ErrUnsupportedModel, err)
+	}
 
-	return &sd, nil
+	for _, name := range append(sd.variant.inputNames(), sd.variant.outputNames()...) {
+		sd.cStrings[name] = C.CString(name)
+	}
+
+	if err := sd.bindTensors(); err != nil {
+		return fmt.Errorf("%w: failed to bind tensors: %w", ErrRuntime, err)
+	}
+
+	return nil
 }
 
 // Segment contains timing information of a speech segment.
-type Segment struct {
-	// The relative timestamp in seconds of when a speech segment begins.
-	SpeechStartAt float64
-	// The relative timestamp in seconds of when a speech segment ends.
-	SpeechEndAt float64
-}
+type Segment = vad.Segment
+
+// Detector implements the common detector API, so that it can be swapped
+// with pure-Go fallbacks such as energyvad.
+var _ vad.Detector = (*Detector)(nil)
 
 func (sd *Detector) Detect(pcm []float32) ([]Segment, error) {
+	return sd.DetectContext(context.Background(), pcm)
+}
+
+// DetectContext is like Detect but stops processing as soon as ctx is done,
+// aborting any in-flight inference, in which case ctx.Err() is returned.
+// The detector should be Reset before being used again after a cancellation.
+func (sd *Detector) DetectContext(ctx context.Context, pcm []float32) ([]Segment, error) {
 	if sd == nil {
-		return nil, fmt.Errorf("invalid nil detector")
+		return nil, ErrNilDetector
 	}
 
+	if err := sd.acquire(); err != nil {
+		return nil, err
+	}
+	defer sd.release()
+
+	return sd.detect(ctx, pcm)
+}
+
+func (sd *Detector) detect(ctx context.Context, pcm []float32) ([]Segment, error) {
 	if sd.windowSize == 0 {
-		sd.windowSize = windowSizeForSampleRate(sd.cfg.SampleRate)
+		sd.windowSize = vad.WindowSizeForSampleRate(sd.cfg.SampleRate)
 	}
 	windowSize := sd.windowSize
 	if sd.streamBuf == nil {
@@ -214,41 +381,26 @@ func (sd *Detector) Detect(pcm []float32) ([]Segment, error) {
 	}
 
 	if len(pcm) < windowSize {
-		return nil, fmt.Errorf("not enough samples")
+		return nil, ErrNotEnoughSamples
 	}
 
 	slog.Debug("starting speech detection", slog.Int("samplesLen", len(pcm)))
 
-	minSilenceSamples := sd.cfg.MinSilenceDurationMs * sd.cfg.SampleRate / 1000
-	speechPadSamples := sd.cfg.SpeechPadMs * sd.cfg.SampleRate / 1000
-
 	var segments []Segment
 	for i := 0; i+windowSize <= len(pcm); i += windowSize {
-		event, err := sd.processWindow(pcm[i:i+windowSize], minSilenceSamples, speechPadSamples)
-		if err != nil {
+		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
-		if event.hasStart {
-			slog.Debug("speech start", slog.Float64("startAt", event.startAt))
-			segments = append(segments, Segment{
-				SpeechStartAt: event.startAt,
-			})
-		}
-
-		if event.hasEnd {
-			slog.Debug("speech end", slog.Float64("endAt", event.endAt))
-			if len(segments) > 0 &&
-				segments[len(segments)-1].SpeechEndAt == 0 &&
-				segments[len(segments)-1].SpeechStartAt == event.endStartAt {
-				segments[len(segments)-1].SpeechEndAt = event.endAt
-			} else {
-				segments = append(segments, Segment{
-					SpeechStartAt: event.endStartAt,
-					SpeechEndAt:   event.endAt,
-				})
+		event, err := sd.processWindow(ctx, pcm[i:i+windowSize])
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
 			}
+			return nil, err
 		}
+
+		segments = appendEventSegments(segments, event)
 	}
 
 	slog.Debug("speech detection done", slog.Int("segmentsLen", len(segments)))
@@ -260,22 +412,31 @@ func (sd *Detector) Detect(pcm []float32) ([]Segment, error) {
 // It returns a segment when speech starts (SpeechEndAt == 0) and when it ends.
 // Call Reset before switching between Detect and DetectStream.
 func (sd *Detector) DetectStream(pcm []float32) ([]Segment, error) {
+	return sd.DetectStreamContext(context.Background(), pcm)
+}
+
+// DetectStreamContext is like DetectStream but stops processing as soon as ctx
+// is done, aborting any in-flight inference, in which case ctx.Err() is returned.
+// The detector should be Reset before being used again after a cancellation.
+func (sd *Detector) DetectStreamContext(ctx context.Context, pcm []float32) ([]Segment, error) {
 	if sd == nil {
-		return nil, fmt.Errorf("invalid nil detector")
+		return nil, ErrNilDetector
 	}
 
+	if err := sd.acquire(); err != nil {
+		return nil, err
+	}
+	defer sd.release()
+
 	if len(pcm) == 0 {
 		return nil, nil
 	}
 
 	if sd.windowSize == 0 {
-		sd.windowSize = windowSizeForSampleRate(sd.cfg.SampleRate)
+		sd.windowSize = vad.WindowSizeForSampleRate(sd.cfg.SampleRate)
 	}
 	windowSize := sd.windowSize
 
-	minSilenceSamples := sd.cfg.MinSilenceDurationMs * sd.cfg.SampleRate / 1000
-	speechPadSamples := sd.cfg.SpeechPadMs * sd.cfg.SampleRate / 1000
-
 	var segments []Segment
 	index := 0
 
@@ -287,42 +448,32 @@ func (sd *Detector) DetectStream(pcm []float32) ([]Segment, error) {
 		}
 		sd.streamBuf = append(sd.streamBuf, pcm[:needed]...)
 
-		event, err := sd.processWindow(sd.streamBuf, minSilenceSamples, speechPadSamples)
+		event, err := sd.processWindow(ctx, sd.streamBuf)
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			return nil, err
 		}
-		if event.hasStart {
-			segments = append(segments, Segment{
-				SpeechStartAt: event.startAt,
-			})
-		}
-		if event.hasEnd {
-			segments = append(segments, Segment{
-				SpeechStartAt: event.endStartAt,
-				SpeechEndAt:   event.endAt,
-			})
-		}
+		segments = vad.AppendUpdates(segments, event)
 
 		sd.streamBuf = sd.streamBuf[:0]
 		index = needed
 	}
 
 	for index+windowSize <= len(pcm) {
-		event, err := sd.processWindow(pcm[index:index+windowSize], minSilenceSamples, speechPadSamples)
-		if err != nil {
+		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		if event.hasStart {
-			segments = append(segments, Segment{
-				SpeechStartAt: event.startAt,
-			})
-		}
-		if event.hasEnd {
-			segments = append(segments, Segment{
-				SpeechStartAt: event.endStartAt,
-				SpeechEndAt:   event.endAt,
-			})
+
+		event, err := sd.processWindow(ctx, pcm[index:index+windowSize])
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, err
 		}
+		segments = vad.AppendUpdates(segments, event)
 		index += windowSize
 	}
 
@@ -333,101 +484,196 @@ func (sd *Detector) DetectStream(pcm []float32) ([]Segment, error) {
 	return segments, nil
 }
 
-type speechEvent struct {
-	hasStart   bool
-	startAt    float64
-	hasEnd     bool
-	endAt      float64
-	endStartAt float64
-}
-
-func (sd *Detector) processWindow(window []float32, minSilenceSamples, speechPadSamples int) (speechEvent, error) {
-	speechProb, err := sd.Infer(window)
-	if err != nil {
-		return speechEvent{}, fmt.Errorf("infer failed: %w", err)
+// appendEventSegments merges a speech event into the list of segments
+// returned by batch detection.
+func appendEventSegments(segments []Segment, event vad.Event) []Segment {
+	if event.HasStart {
+		slog.Debug("speech start", slog.Float64("startAt", event.StartAt))
+	}
+	if event.HasEnd {
+		slog.Debug("speech end", slog.Float64("endAt", event.EndAt))
 	}
 
-	sd.currSample += sd.windowSize
-
-	return sd.advanceSpeech(speechProb, minSilenceSamples, speechPadSamples)
+	return vad.AppendSegments(segments, event)
 }
 
-func (sd *Detector) advanceSpeech(speechProb float32, minSilenceSamples, speechPadSamples int) (speechEvent, error) {
-	var event speechEvent
-
-	if speechProb >= sd.cfg.Threshold && sd.tempEnd != 0 {
-		sd.tempEnd = 0
+// SegmentProbabilities runs segmentation over per-window speech probabilities,
+// as returned by Probabilities, producing the segments Detect would return on
+// the corresponding audio. Only the SampleRate, Threshold, MinSilenceDurationMs
+// and SpeechPadMs fields of cfg are used, so different settings can be
+// evaluated without running the model again.
+func SegmentProbabilities(probs []float32, cfg DetectorConfig) ([]Segment, error) {
+	if err := cfg.validateSegmentation(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
-	if speechProb >= sd.cfg.Threshold && !sd.triggered {
-		sd.triggered = true
-		speechStartAt := float64(sd.currSample-sd.windowSize-speechPadSamples) / float64(sd.cfg.SampleRate)
+	seg := vad.NewSegmenter(cfg.segmenterConfig(vad.WindowSizeForSampleRate(cfg.SampleRate)))
 
-		// We clamp at zero since due to padding the starting position could be negative.
-		if speechStartAt < 0 {
-			speechStartAt = 0
+	var segments []Segment
+	for _, prob := range probs {
+		event, err := seg.Advance(prob, cfg.Threshold)
+		if err != nil {
+			return nil, err
 		}
+		segments = appendEventSegments(segments, event)
+	}
 
-		sd.pendingStart = speechStartAt
-		sd.pendingStartValid = true
+	return segments, nil
+}
 
-		event.hasStart = true
-		event.startAt = speechStartAt
+func (sd *Detector) processWindow(ctx context.Context, window []float32) (vad.Event, error) {
+	speechProb, err := sd.windowProbability(ctx, window)
+	if err != nil {
+		return vad.Event{}, fmt.Errorf("infer failed: %w", err)
 	}
 
-	if speechProb < (sd.cfg.Threshold-0.15) && sd.triggered {
-		if sd.tempEnd == 0 {
-			sd.tempEnd = sd.currSample
-		}
+	return sd.advance(speechProb, window)
+}
 
-		// Not enough silence yet to split, we continue.
-		if sd.currSample-sd.tempEnd < minSilenceSamples {
-			return event, nil
-		}
+// advance runs segmentation on the speech probability of window and updates
+// the noise estimate with it.
+func (sd *Detector) advance(speechProb float32, window []float32) (vad.Event, error) {
+	if sd.thresholdChanged.Swap(false) && sd.noise != nil {
+		sd.noise.reset(sd.baseThreshold.Load())
+	}
+	sd.lastProb.Store(speechProb)
 
-		speechEndAt := float64(sd.tempEnd+speechPadSamples) / float64(sd.cfg.SampleRate)
-		sd.tempEnd = 0
-		sd.triggered = false
+	event, err := sd.seg.Advance(speechProb, sd.threshold())
+	if sd.noise != nil {
+		sd.noise.update(speechProb, audio.RMS(window), sd.seg.Triggered())
+	}
+	sd.effectiveThreshold.Store(sd.threshold())
 
-		if !sd.pendingStartValid {
-			return event, fmt.Errorf("unexpected speech end")
-		}
+	return event, err
+}
 
-		event.hasEnd = true
-		event.endAt = speechEndAt
-		event.endStartAt = sd.pendingStart
-		sd.pendingStartValid = false
+// threshold returns the effective speech threshold. Unlike
+// EffectiveThreshold, it must be called by the goroutine running detection.
+func (sd *Detector) threshold() float32 {
+	if sd.noise != nil {
+		return sd.noise.threshold
 	}
-
-	return event, nil
+	return sd.baseThreshold.Load()
 }
 
 func (sd *Detector) Reset() error {
 	if sd == nil {
-		return fmt.Errorf("invalid nil detector")
+		return ErrNilDetector
+	}
+
+	if err := sd.acquire(); err != nil {
+		return err
 	}
+	defer sd.release()
 
-	sd.currSample = 0
-	sd.triggered = false
-	sd.tempEnd = 0
-	sd.pendingStart = 0
-	sd.pendingStartValid = false
+	sd.seg.Reset()
+	sd.lastProb.Store(0)
 	sd.streamBuf = sd.streamBuf[:0]
-	clear(sd.state[:])
+	clear(sd.state)
 	clear(sd.inputBuf)
+	// Clearing the flag first so that a concurrent SetThreshold isn't missed.
+	sd.thresholdChanged.Store(false)
+	if sd.noise != nil {
+		sd.noise.reset(sd.baseThreshold.Load())
+	}
+	sd.effectiveThreshold.Store(sd.threshold())
+	if sd.gate != nil {
+		sd.gate.reset()
+	}
 
 	return nil
 }
 
+// Stats returns the counters accumulated since the detector was created,
+// which aren't affected by Reset. It's safe to call concurrently with
+// detection.
+func (sd *Detector) Stats() DetectorStats {
+	if sd == nil {
+		return DetectorStats{}
+	}
+	return DetectorStats{
+		Windows:        sd.windows.Load(),
+		SkippedWindows: sd.skippedWindows.Load(),
+	}
+}
+
+// Config returns the configuration the detector was created with, reflecting
+// any later change through SetThreshold. It's safe to call concurrently with
+// detection.
+func (sd *Detector) Config() DetectorConfig {
+	if sd == nil {
+		return DetectorConfig{}
+	}
+	cfg := sd.cfg
+	cfg.Threshold = sd.baseThreshold.Load()
+	return cfg
+}
+
+// LastProbability returns the speech probability of the last window processed
+// by Detect or DetectStream. Feeding DetectStream one window at a time allows
+// to follow the probability of each window. It's safe to call concurrently
+// with detection.
+func (sd *Detector) LastProbability() float32 {
+	if sd == nil {
+		return 0
+	}
+	return sd.lastProb.Load()
+}
+
+// ModelVersion returns the version of the loaded model.
+func (sd *Detector) ModelVersion() ModelVersion {
+	if sd == nil {
+		return ModelVersionAuto
+	}
+	return sd.variant.version
+}
+
+// SetThreshold changes the speech threshold, which should be in range (0, 1)
+// as for DetectorConfig.Threshold. With AdaptiveThreshold enabled, adaptation
+// starts over from value. It's safe to call concurrently with detection, in
+// which case it applies from the next window.
 func (sd *Detector) SetThreshold(value float32) {
-	sd.cfg.Threshold = value
+	sd.baseThreshold.Store(value)
+	if sd.noise != nil {
+		sd.effectiveThreshold.Store(sd.noise.initialThreshold(value))
+	} else {
+		sd.effectiveThreshold.Store(value)
+	}
+	sd.thresholdChanged.Store(true)
 }
 
+// EffectiveThreshold returns the speech threshold currently applied, which
+// differs from the configured one when AdaptiveThreshold is enabled. It's safe
+// to call concurrently with detection.
+func (sd *Detector) EffectiveThreshold() float32 {
+	if sd == nil {
+		return 0
+	}
+	return sd.effectiveThreshold.Load()
+}
+
+// Destroy releases all the resources held by the detector. It's safe to call
+// multiple times, any other use of the detector afterwards fails with ErrDestroyed.
 func (sd *Detector) Destroy() error {
 	if sd == nil {
-		return fmt.Errorf("invalid nil detector")
+		return ErrNilDetector
+	}
+
+	if !sd.status.CompareAndSwap(detectorIdle, detectorDestroyed) {
+		if sd.status.Load() == detectorDestroyed {
+			return nil
+		}
+		return ErrConcurrentUse
 	}
 
+	C.OrtApiReleaseIoBinding(sd.api, sd.binding)
+	for _, value := range sd.values {
+		C.OrtApiReleaseValue(sd.api, value)
+	}
+	for _, ptr := range sd.buffers {
+		C.free(ptr)
+	}
+	C.OrtApiReleaseRunOptions(sd.api, sd.runOpts)
 	C.OrtApiReleaseMemoryInfo(sd.api, sd.memoryInfo)
 	C.OrtApiReleaseSession(sd.api, sd.session)
 	C.OrtApiReleaseSessionOptions(sd.api, sd.sessionOpts)
@@ -436,5 +682,27 @@ func (sd *Detector) Destroy() error {
 		C.free(unsafe.Pointer(ptr))
 	}
 
-	return nil
+	var errs []error
+	for _, worker := range sd.workers {
+		errs = append(errs, worker.Destroy())
+	}
+	sd.workers = nil
+
+	// Dropping all the references so nothing can point to released memory.
+	sd.binding = nil
+	sd.values = nil
+	sd.buffers = nil
+	sd.runOpts = nil
+	sd.memoryInfo = nil
+	sd.session = nil
+	sd.sessionOpts = nil
+	sd.env = nil
+	sd.cStrings = nil
+	sd.state = nil
+	sd.stateOut = nil
+	sd.prob = nil
+	sd.rate = nil
+	sd.inputBuf = nil
+
+	return errors.Join(errs...)
 }
@@ -5,6 +5,8 @@ import (
 	"math"
 	"os"
 	"testing"
+
+	"github.com/streamer45/silero-vad-go/vad"
 )
 
 var (
@@ -30,11 +32,27 @@ func BenchmarkInfer(b *testing.B) {
 	}()
 
 	samples := readSamplesFromFileB(b, "../testfiles/samples.pcm")
-	windowSize := windowSizeForSampleRate(cfg.SampleRate)
+	windowSize := vad.WindowSizeForSampleRate(cfg.SampleRate)
 	if len(samples) < windowSize {
 		b.Fatalf("not enough samples")
 	}
 
+	// Steady-state inference is expected not to allocate. The race detector
+	// instruments allocations so we only check this in regular builds.
+	if !raceEnabled {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := sd.Infer(samples[:windowSize]); err != nil {
+				b.Fatal(err)
+			}
+		})
+		if allocs != 0 {
+			b.Fatalf("expected 0 allocs/op, got %v", allocs)
+		}
+		if err := sd.Reset(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
 	b.ReportAllocs()
 	b.ResetTimer()
 
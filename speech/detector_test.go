@@ -1,13 +1,20 @@
 package speech
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
 	"log/slog"
 	"math"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/vad"
 )
 
 func readSamplesFromFile(t *testing.T, path string) []float32 {
@@ -73,6 +80,42 @@ func TestDetectorConfigIsValid(t *testing.T) {
 			},
 			err: "invalid SpeechPadMs: should be a positive number",
 		},
+		{
+			name: "invalid ModelVersion",
+			cfg: DetectorConfig{
+				ModelPath:    "../testfiles/silero_vad.onnx",
+				SampleRate:   16000,
+				Threshold:    0.5,
+				ModelVersion: ModelVersionV6 + 1,
+			},
+			err: "invalid ModelVersion: unknown version",
+		},
+		{
+			name: "invalid AdaptiveThreshold",
+			cfg: DetectorConfig{
+				ModelPath:  "../testfiles/silero_vad.onnx",
+				SampleRate: 16000,
+				Threshold:  0.5,
+				AdaptiveThreshold: AdaptiveThresholdConfig{
+					Enabled:      true,
+					MinThreshold: 0.9,
+				},
+			},
+			err: "invalid AdaptiveThreshold: invalid MaxThreshold: should be greater than MinThreshold",
+		},
+		{
+			name: "invalid EnergyGate",
+			cfg: DetectorConfig{
+				ModelPath:  "../testfiles/silero_vad.onnx",
+				SampleRate: 16000,
+				Threshold:  0.5,
+				EnergyGate: EnergyGateConfig{
+					Enabled:    true,
+					RMSFloorDb: 1,
+				},
+			},
+			err: "invalid EnergyGate: invalid RMSFloorDb: should be a negative number",
+		},
 		{
 			name: "valid",
 			cfg: DetectorConfig{
@@ -95,6 +138,17 @@ func TestDetectorConfigIsValid(t *testing.T) {
 	}
 }
 
+func TestParseLogLevel(t *testing.T) {
+	for _, l := range []LogLevel{LevelVerbose, LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelFatal} {
+		parsed, err := ParseLogLevel(l.String())
+		require.NoError(t, err)
+		require.Equal(t, l, parsed)
+	}
+
+	_, err := ParseLogLevel("debug")
+	require.EqualError(t, err, `invalid log level "debug"`)
+}
+
 func TestNewDetector(t *testing.T) {
 	cfg := DetectorConfig{
 		ModelPath:  "../testfiles/silero_vad.onnx",
@@ -105,6 +159,11 @@ func TestNewDetector(t *testing.T) {
 	sd, err := NewDetector(cfg)
 	require.NoError(t, err)
 	require.NotNil(t, sd)
+	require.Equal(t, cfg, sd.Config())
+
+	sd.SetThreshold(0.7)
+	require.Equal(t, float32(0.7), sd.Config().Threshold)
+	require.Equal(t, float32(0.7), sd.EffectiveThreshold())
 
 	err = sd.Destroy()
 	require.NoError(t, err)
@@ -289,10 +348,291 @@ func TestDetectExactWindow(t *testing.T) {
 	}()
 
 	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
-	windowSize := windowSizeForSampleRate(cfg.SampleRate)
+	windowSize := vad.WindowSizeForSampleRate(cfg.SampleRate)
 	require.GreaterOrEqual(t, len(samples), windowSize)
 
 	segments, err := sd.Detect(samples[:windowSize])
 	require.NoError(t, err)
 	require.NotNil(t, segments)
 }
+
+func TestDetectContext(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sd)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+
+	expected := []Segment{
+		{
+			SpeechStartAt: 1.056,
+			SpeechEndAt:   1.632,
+		},
+		{
+			SpeechStartAt: 2.88,
+			SpeechEndAt:   3.232,
+		},
+		{
+			SpeechStartAt: 4.448,
+			SpeechEndAt:   0,
+		},
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		segments, err := sd.DetectContext(ctx, samples)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, segments)
+
+		segments, err = sd.DetectStreamContext(ctx, samples)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, segments)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		var long []float32
+		for i := 0; i < 100; i++ {
+			long = append(long, samples...)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		segments, err := sd.DetectContext(ctx, long)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Nil(t, segments)
+	})
+
+	t.Run("resettable", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+
+		segments, err := sd.DetectContext(context.Background(), samples)
+		require.NoError(t, err)
+		require.Equal(t, expected, segments)
+	})
+}
+
+func TestProbabilities(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	require.Equal(t, 512, sd.WindowSize())
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+
+	probs, err := sd.Probabilities(samples)
+	require.NoError(t, err)
+	require.Len(t, probs, len(samples)/sd.WindowSize())
+
+	require.NoError(t, sd.Reset())
+	for i, prob := range probs {
+		expected, err := sd.Infer(samples[i*512 : (i+1)*512])
+		require.NoError(t, err)
+		require.Equal(t, expected, prob)
+	}
+
+	_, err = sd.Probabilities(samples[:100])
+	require.ErrorIs(t, err, ErrNotEnoughSamples)
+
+	t.Run("segmentation", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+		expected, err := sd.Detect(samples)
+		require.NoError(t, err)
+
+		segments, err := SegmentProbabilities(probs, cfg)
+		require.NoError(t, err)
+		require.Equal(t, expected, segments)
+	})
+
+	t.Run("last probability", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+		require.Zero(t, sd.LastProbability())
+		for i, prob := range probs {
+			_, err := sd.DetectStream(samples[i*512 : (i+1)*512])
+			require.NoError(t, err)
+			require.Equal(t, prob, sd.LastProbability())
+		}
+		require.NoError(t, sd.Reset())
+		require.Zero(t, sd.LastProbability())
+	})
+}
+
+func TestSegmentProbabilities(t *testing.T) {
+	cfg := DetectorConfig{
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	segments, err := SegmentProbabilities([]float32{0.1, 0.9, 0.9, 0.1, 0.1, 0.8}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, []Segment{
+		{SpeechStartAt: 0.032, SpeechEndAt: 0.128},
+		{SpeechStartAt: 0.16},
+	}, segments)
+
+	// A longer minimum silence merges both segments.
+	cfg.MinSilenceDurationMs = 100
+	segments, err = SegmentProbabilities([]float32{0.1, 0.9, 0.9, 0.1, 0.1, 0.8}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, []Segment{{SpeechStartAt: 0.032}}, segments)
+
+	segments, err = SegmentProbabilities(nil, cfg)
+	require.NoError(t, err)
+	require.Empty(t, segments)
+
+	cfg.Threshold = 0
+	_, err = SegmentProbabilities(nil, cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestInferAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not reliable with the race detector enabled")
+	}
+
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sd)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	windowSize := vad.WindowSizeForSampleRate(cfg.SampleRate)
+
+	index := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		if index+windowSize > len(samples) {
+			index = 0
+		}
+		if _, err := sd.Infer(samples[index : index+windowSize]); err != nil {
+			t.Fatal(err)
+		}
+		index += windowSize
+	})
+	require.Zero(t, allocs)
+
+	// Results should match after going through the persistent bindings.
+	require.NoError(t, sd.Reset())
+	segments, err := sd.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, []Segment{
+		{
+			SpeechStartAt: 1.056,
+			SpeechEndAt:   1.632,
+		},
+		{
+			SpeechStartAt: 2.88,
+			SpeechEndAt:   3.232,
+		},
+		{
+			SpeechStartAt: 4.448,
+			SpeechEndAt:   0,
+		},
+	}, segments)
+}
+
+func TestDetectorGuard(t *testing.T) {
+	samples := make([]float32, vad.WindowSizeForSampleRate(16000))
+
+	t.Run("concurrent use", func(t *testing.T) {
+		sd := &Detector{cfg: DetectorConfig{SampleRate: 16000}}
+		sd.status.Store(detectorBusy)
+
+		_, err := sd.Detect(samples)
+		require.ErrorIs(t, err, ErrConcurrentUse)
+		_, err = sd.DetectStream(samples)
+		require.ErrorIs(t, err, ErrConcurrentUse)
+		_, err = sd.DetectParallel(samples, 2)
+		require.ErrorIs(t, err, ErrConcurrentUse)
+		_, err = sd.Infer(samples)
+		require.ErrorIs(t, err, ErrConcurrentUse)
+		require.ErrorIs(t, sd.Reset(), ErrConcurrentUse)
+		require.ErrorIs(t, sd.Destroy(), ErrConcurrentUse)
+
+		sd.release()
+		require.NoError(t, sd.Reset())
+	})
+
+	t.Run("use after destroy", func(t *testing.T) {
+		sd := &Detector{cfg: DetectorConfig{SampleRate: 16000}}
+		sd.status.Store(detectorDestroyed)
+
+		_, err := sd.Detect(samples)
+		require.ErrorIs(t, err, ErrDestroyed)
+		_, err = sd.DetectStream(samples)
+		require.ErrorIs(t, err, ErrDestroyed)
+		_, err = sd.Infer(samples)
+		require.ErrorIs(t, err, ErrDestroyed)
+		require.ErrorIs(t, sd.Reset(), ErrDestroyed)
+		require.NoError(t, sd.Destroy())
+	})
+}
+
+func TestDetectorConcurrentUse(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sd)
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := 0; offset+1000 <= len(samples); offset += 1000 {
+				_, err := sd.DetectStream(samples[offset : offset+1000])
+				if err != nil && !errors.Is(err, ErrConcurrentUse) {
+					t.Error(err)
+					return
+				}
+				if err == nil {
+					succeeded.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	require.NotZero(t, succeeded.Load())
+
+	require.NoError(t, sd.Destroy())
+	require.NoError(t, sd.Destroy())
+
+	_, err = sd.Detect(samples)
+	require.ErrorIs(t, err, ErrDestroyed)
+	require.ErrorIs(t, sd.Reset(), ErrDestroyed)
+}
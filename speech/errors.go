@@ -0,0 +1,109 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+var (
+	// ErrInvalidConfig is returned when a configuration fails validation.
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrModelLoad is returned when the model cannot be loaded.
+	ErrModelLoad = errors.New("failed to load model")
+	// ErrUnsupportedModel is returned, along with ErrModelLoad, when the model
+	// signature doesn't match any supported Silero VAD version.
+	ErrUnsupportedModel = errors.New("unsupported model")
+	// ErrRuntime is returned when ONNX Runtime fails to set up or run inference.
+	ErrRuntime = errors.New("runtime error")
+	// ErrNilDetector is returned when calling methods on a nil Detector.
+	ErrNilDetector = errors.New("invalid nil detector")
+	// ErrNotEnoughSamples is returned when the input is shorter than a window.
+	ErrNotEnoughSamples = vad.ErrNotEnoughSamples
+	// ErrInvalidSamplesLength is returned when inferring on a buffer that
+	// doesn't match the window size.
+	ErrInvalidSamplesLength = vad.ErrInvalidSamplesLength
+	// ErrConcurrentUse is returned when a Detector is used by multiple
+	// goroutines at the same time.
+	ErrConcurrentUse = errors.New("concurrent use of detector")
+	// ErrDestroyed is returned when a Detector is used after being destroyed.
+	ErrDestroyed = errors.New("detector is destroyed")
+	// ErrPoolClosed is returned when getting a detector from a closed pool.
+	ErrPoolClosed = errors.New("pool is closed")
+)
+
+// OrtErrorCode is an error code reported by ONNX Runtime.
+type OrtErrorCode int
+
+const (
+	OrtErrorFail OrtErrorCode = iota + 1
+	OrtErrorInvalidArgument
+	OrtErrorNoSuchFile
+	OrtErrorNoModel
+	OrtErrorEngineError
+	OrtErrorRuntimeException
+	OrtErrorInvalidProtobuf
+	OrtErrorModelLoaded
+	OrtErrorNotImplemented
+	OrtErrorInvalidGraph
+	OrtErrorEPFail
+)
+
+func (c OrtErrorCode) String() string {
+	switch c {
+	case OrtErrorFail:
+		return "ORT_FAIL"
+	case OrtErrorInvalidArgument:
+		return "ORT_INVALID_ARGUMENT"
+	case OrtErrorNoSuchFile:
+		return "ORT_NO_SUCHFILE"
+	case OrtErrorNoModel:
+		return "ORT_NO_MODEL"
+	case OrtErrorEngineError:
+		return "ORT_ENGINE_ERROR"
+	case OrtErrorRuntimeException:
+		return "ORT_RUNTIME_EXCEPTION"
+	case OrtErrorInvalidProtobuf:
+		return "ORT_INVALID_PROTOBUF"
+	case OrtErrorModelLoaded:
+		return "ORT_MODEL_LOADED"
+	case OrtErrorNotImplemented:
+		return "ORT_NOT_IMPLEMENTED"
+	case OrtErrorInvalidGraph:
+		return "ORT_INVALID_GRAPH"
+	case OrtErrorEPFail:
+		return "ORT_EP_FAIL"
+	default:
+		return fmt.Sprintf("OrtErrorCode(%d)", int(c))
+	}
+}
+
+// OrtError is an error reported by ONNX Runtime.
+type OrtError struct {
+	// The operation that failed (e.g. "create session").
+	Op string
+	// The error code reported by ONNX Runtime.
+	Code OrtErrorCode
+	// The error message reported by ONNX Runtime.
+	Message string
+}
+
+func (e *OrtError) Error() string {
+	return fmt.Sprintf("failed to %s: %s", e.Op, e.Message)
+}
+
+// ortError converts and releases the given non-nil status.
+func (sd *Detector) ortError(op string, status *C.OrtStatus) error {
+	defer C.OrtApiReleaseStatus(sd.api, status)
+	return &OrtError{
+		Op:      op,
+		Code:    OrtErrorCode(C.OrtApiGetErrorCode(sd.api, status)),
+		Message: C.GoString(C.OrtApiGetErrorMessage(sd.api, status)),
+	}
+}
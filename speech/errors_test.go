@@ -0,0 +1,108 @@
+package speech
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrtError(t *testing.T) {
+	err := error(&OrtError{
+		Op:      "create session",
+		Code:    OrtErrorNoSuchFile,
+		Message: "file not found",
+	})
+	require.EqualError(t, err, "failed to create session: file not found")
+
+	var ortErr *OrtError
+	require.True(t, errors.As(err, &ortErr))
+	require.Equal(t, OrtErrorNoSuchFile, ortErr.Code)
+
+	require.Equal(t, "ORT_NO_SUCHFILE", OrtErrorNoSuchFile.String())
+	require.Equal(t, "ORT_EP_FAIL", OrtErrorEPFail.String())
+	require.Equal(t, "OrtErrorCode(42)", OrtErrorCode(42).String())
+}
+
+func TestNewDetectorErrors(t *testing.T) {
+	t.Run("invalid config", func(t *testing.T) {
+		sd, err := NewDetector(DetectorConfig{})
+		require.Nil(t, sd)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		require.EqualError(t, err, "invalid config: invalid ModelPath: should not be empty")
+	})
+
+	t.Run("missing model", func(t *testing.T) {
+		sd, err := NewDetector(DetectorConfig{
+			ModelPath:  "../testfiles/missing.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		})
+		require.Nil(t, sd)
+		require.ErrorIs(t, err, ErrModelLoad)
+		require.NotErrorIs(t, err, ErrUnsupportedModel)
+
+		var ortErr *OrtError
+		require.True(t, errors.As(err, &ortErr))
+		require.Equal(t, "create session", ortErr.Op)
+		require.NotZero(t, ortErr.Code)
+	})
+
+	t.Run("invalid model", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "invalid.onnx")
+		require.NoError(t, os.WriteFile(path, []byte("not a model"), 0o644))
+
+		sd, err := NewDetector(DetectorConfig{
+			ModelPath:  path,
+			SampleRate: 16000,
+			Threshold:  0.5,
+		})
+		require.Nil(t, sd)
+		require.ErrorIs(t, err, ErrModelLoad)
+
+		var ortErr *OrtError
+		require.True(t, errors.As(err, &ortErr))
+	})
+
+	t.Run("unsupported model", func(t *testing.T) {
+		sd, err := NewDetector(DetectorConfig{
+			ModelPath:    "../testfiles/silero_vad.onnx",
+			SampleRate:   16000,
+			Threshold:    0.5,
+			ModelVersion: ModelVersionV4,
+		})
+		require.Nil(t, sd)
+		require.ErrorIs(t, err, ErrModelLoad)
+		require.ErrorIs(t, err, ErrUnsupportedModel)
+	})
+}
+
+func TestDetectorErrors(t *testing.T) {
+	t.Run("nil detector", func(t *testing.T) {
+		var sd *Detector
+		_, err := sd.Detect(nil)
+		require.ErrorIs(t, err, ErrNilDetector)
+		_, err = sd.Infer(nil)
+		require.ErrorIs(t, err, ErrNilDetector)
+		require.ErrorIs(t, sd.Reset(), ErrNilDetector)
+	})
+
+	sd, err := NewDetector(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	_, err = sd.Detect(make([]float32, 100))
+	require.ErrorIs(t, err, ErrNotEnoughSamples)
+
+	_, err = sd.Infer(make([]float32, 100))
+	require.ErrorIs(t, err, ErrInvalidSamplesLength)
+	require.EqualError(t, err, "invalid samples length: expected 512, got 100")
+}
@@ -0,0 +1,135 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/streamer45/silero-vad-go/audio"
+)
+
+// EnergyGateConfig configures the energy pre-gate, skipping inference on
+// windows of digital silence or near silence. A window is gated when both
+// its RMS and peak levels are below their floor. Gated windows are still
+// inferred for HangoverMs, then treated as having zero speech probability.
+// Past the hangover, the model state keeps aging through windows of digital
+// silence until it stops changing, after which inference is skipped
+// altogether, while the gated samples keep feeding the context of the next
+// window. Detection thus resumes as if the model had kept processing silence.
+type EnergyGateConfig struct {
+	// Whether to gate windows.
+	Enabled bool
+	// The RMS level floor in dBFS. Defaults to -60.
+	RMSFloorDb float64
+	// The peak level floor in dBFS. Defaults to -50.
+	PeakFloorDb float64
+	// The duration of gated audio still inferred as is, in milliseconds.
+	// Defaults to 100, a negative value disables the hangover.
+	HangoverMs int
+}
+
+func (c EnergyGateConfig) IsValid() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.RMSFloorDb > 0 {
+		return fmt.Errorf("invalid RMSFloorDb: should be a negative number")
+	}
+
+	if c.PeakFloorDb > 0 {
+		return fmt.Errorf("invalid PeakFloorDb: should be a negative number")
+	}
+
+	return nil
+}
+
+func (c *EnergyGateConfig) setDefaults() {
+	if c.RMSFloorDb == 0 {
+		c.RMSFloorDb = -60
+	}
+	if c.PeakFloorDb == 0 {
+		c.PeakFloorDb = -50
+	}
+	if c.HangoverMs == 0 {
+		c.HangoverMs = 100
+	}
+}
+
+// The maximum duration of silence the model state is aged through before
+// skipping inference, in milliseconds, should it not settle earlier.
+const maxAgingMs = 1000
+
+// The largest change of any state value for the state to be settled.
+const settledStateDelta = 1e-6
+
+// energyGate decides which windows skip inference.
+type energyGate struct {
+	rmsFloor  float64
+	peakFloor float32
+	// The number of gated windows inferred as is.
+	hangover int
+	// The number of gated windows past the hangover the model state is at
+	// most aged through.
+	maxAging int
+	// The number of consecutive gated windows.
+	gated int
+
+	// The window of digital silence the model state is aged through.
+	silence []float32
+	// The model state before the last aging window.
+	prevState []float32
+	// Whether the model state stopped changing while aging.
+	settled bool
+}
+
+func newEnergyGate(cfg EnergyGateConfig, windowSize, sampleRate int) *energyGate {
+	cfg.setDefaults()
+	return &energyGate{
+		rmsFloor:  math.Pow(10, cfg.RMSFloorDb/20),
+		peakFloor: float32(math.Pow(10, cfg.PeakFloorDb/20)),
+		hangover:  (max(cfg.HangoverMs, 0)*sampleRate/1000 + windowSize - 1) / windowSize,
+		maxAging:  (maxAgingMs*sampleRate/1000 + windowSize - 1) / windowSize,
+		silence:   make([]float32, windowSize),
+		prevState: make([]float32, stateLen),
+	}
+}
+
+// skip returns whether inference should be skipped for window.
+func (g *energyGate) skip(window []float32) bool {
+	if audio.Peak(window) >= g.peakFloor || audio.RMS(window) >= g.rmsFloor {
+		g.reset()
+		return false
+	}
+
+	g.gated++
+	return g.gated > g.hangover
+}
+
+// aged records the model state resulting from an aging window, which is
+// settled once it stops changing or aged long enough.
+func (g *energyGate) aged(state []float32) {
+	g.settled = g.gated-g.hangover >= g.maxAging
+	if g.settled {
+		return
+	}
+
+	for i, v := range state {
+		if math.Abs(float64(v-g.prevState[i])) > settledStateDelta {
+			return
+		}
+	}
+	g.settled = true
+}
+
+func (g *energyGate) reset() {
+	g.gated = 0
+	g.settled = false
+}
+
+// DetectorStats holds counters accumulated over the lifetime of a Detector.
+type DetectorStats struct {
+	// The number of windows processed through detection or Probabilities.
+	Windows uint64
+	// The number of those windows whose inference was skipped by the energy gate.
+	SkippedWindows uint64
+}
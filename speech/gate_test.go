@@ -0,0 +1,182 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnergyGateConfigIsValid(t *testing.T) {
+	require.NoError(t, EnergyGateConfig{}.IsValid())
+	require.NoError(t, EnergyGateConfig{HangoverMs: -1}.IsValid())
+	require.NoError(t, EnergyGateConfig{Enabled: true}.IsValid())
+	require.NoError(t, EnergyGateConfig{Enabled: true, RMSFloorDb: -70, PeakFloorDb: -40, HangoverMs: 50}.IsValid())
+	require.NoError(t, EnergyGateConfig{Enabled: true, HangoverMs: -1}.IsValid())
+
+	require.EqualError(t, EnergyGateConfig{Enabled: true, RMSFloorDb: 1}.IsValid(),
+		"invalid RMSFloorDb: should be a negative number")
+	require.EqualError(t, EnergyGateConfig{Enabled: true, PeakFloorDb: 1}.IsValid(),
+		"invalid PeakFloorDb: should be a negative number")
+}
+
+func TestEnergyGate(t *testing.T) {
+	g := newEnergyGate(EnergyGateConfig{Enabled: true}, 512, 16000)
+	// 100ms rounds up to 4 windows of 32ms.
+	require.Equal(t, 4, g.hangover)
+
+	silence := make([]float32, 512)
+	quiet := make([]float32, 512)
+	for i := range quiet {
+		quiet[i] = 0.0005
+	}
+	click := make([]float32, 512)
+	click[100] = 0.01
+	loud := make([]float32, 512)
+	for i := range loud {
+		loud[i] = 0.01
+	}
+
+	var skipped []bool
+	for _, window := range [][]float32{silence, quiet, silence, silence, silence, quiet, click, silence, loud, silence} {
+		skipped = append(skipped, g.skip(window))
+	}
+	require.Equal(t, []bool{false, false, false, false, true, true, false, false, false, false}, skipped)
+
+	// A negative hangover skips from the first gated window.
+	g = newEnergyGate(EnergyGateConfig{Enabled: true, HangoverMs: -1}, 512, 16000)
+	require.Zero(t, g.hangover)
+	require.True(t, g.skip(silence))
+}
+
+func TestEnergyGateAging(t *testing.T) {
+	g := newEnergyGate(EnergyGateConfig{Enabled: true}, 512, 16000)
+	// 1s rounds up to 32 windows of 32ms.
+	require.Equal(t, 32, g.maxAging)
+
+	silence := make([]float32, 512)
+	state := make([]float32, stateLen)
+	for i := 0; i < 4; i++ {
+		require.False(t, g.skip(silence))
+	}
+	require.True(t, g.skip(silence))
+
+	// The state is settled once it stops changing.
+	copy(g.prevState, state)
+	state[0] = 0.5
+	g.aged(state)
+	require.False(t, g.settled)
+	copy(g.prevState, state)
+	g.aged(state)
+	require.True(t, g.settled)
+
+	// A window above the floors starts aging over.
+	loud := make([]float32, 512)
+	for i := range loud {
+		loud[i] = 0.01
+	}
+	require.False(t, g.skip(loud))
+	require.False(t, g.settled)
+
+	// Otherwise it's settled after aging long enough.
+	for i := 0; i < 4; i++ {
+		require.False(t, g.skip(silence))
+	}
+	for i := 0; i < 32; i++ {
+		require.True(t, g.skip(silence))
+		require.False(t, g.settled)
+		copy(g.prevState, state)
+		state[0]++
+		g.aged(state)
+	}
+	require.True(t, g.settled)
+}
+
+func TestEnergyGateDetection(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	// Speech preceded and followed by two seconds of digital silence.
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	samples = append(append(make([]float32, 32000), samples...), make([]float32, 32000)...)
+	windows := uint64(len(samples) / 512)
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+	expected, err := sd.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, DetectorStats{Windows: windows}, sd.Stats())
+
+	cfg.EnergyGate = EnergyGateConfig{Enabled: true}
+	gated, err := NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, gated.Destroy())
+	}()
+	segments, err := gated.Detect(samples)
+	require.NoError(t, err)
+
+	require.Len(t, segments, len(expected))
+	for i := range segments {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+		require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.1)
+	}
+
+	// At least the silence after the hangover and the longest aging is skipped
+	// on both sides.
+	stats := gated.Stats()
+	require.Equal(t, windows, stats.Windows)
+	require.GreaterOrEqual(t, stats.SkippedWindows, uint64(2*(61-4-32)))
+
+	// Probabilities are gated the same way, with skipped windows at zero.
+	require.NoError(t, gated.Reset())
+	probs, err := gated.Probabilities(samples[:32000])
+	require.NoError(t, err)
+	require.Equal(t, make([]float32, 62-4), probs[4:])
+	require.Equal(t, windows+62, gated.Stats().Windows)
+
+	var nilDetector *Detector
+	require.Zero(t, nilDetector.Stats())
+}
+
+func TestEnergyGateReentry(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	// Speech resuming after a long gated silence is detected from the aged
+	// model state as it would be after inferring the silence.
+	speech := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	samples := append(append(append([]float32{}, speech...), make([]float32, 5*16000)...), speech...)
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+	expected, err := sd.Detect(samples)
+	require.NoError(t, err)
+
+	cfg.EnergyGate = EnergyGateConfig{Enabled: true}
+	gated, err := NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, gated.Destroy())
+	}()
+	segments, err := gated.Detect(samples)
+	require.NoError(t, err)
+	require.NotZero(t, gated.Stats().SkippedWindows)
+
+	require.Len(t, segments, len(expected))
+	for i := range segments {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+		require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.1)
+	}
+}
@@ -6,113 +6,299 @@ package speech
 import "C"
 
 import (
+	"context"
 	"fmt"
 	"unsafe"
+
+	"github.com/streamer45/silero-vad-go/vad"
 )
 
 func (sd *Detector) Infer(samples []float32) (float32, error) {
 	if sd == nil {
-		return 0, fmt.Errorf("invalid nil detector")
+		return 0, ErrNilDetector
+	}
+
+	if err := sd.acquire(); err != nil {
+		return 0, err
 	}
+	defer sd.release()
+
+	return sd.infer(context.Background(), samples)
+}
 
+// WindowSize returns the number of samples consumed by each inference.
+func (sd *Detector) WindowSize() int {
+	if sd == nil {
+		return 0
+	}
 	if sd.windowSize == 0 {
-		sd.windowSize = windowSizeForSampleRate(sd.cfg.SampleRate)
+		return vad.WindowSizeForSampleRate(sd.cfg.SampleRate)
 	}
-	if len(samples) != sd.windowSize {
-		return 0, fmt.Errorf("invalid samples length: expected %d, got %d", sd.windowSize, len(samples))
+	return sd.windowSize
+}
+
+// Probabilities returns the speech probability of each full window in pcm.
+// Trailing samples not filling a window are ignored. Like Infer, it advances
+// the model state but not the segmentation state.
+func (sd *Detector) Probabilities(pcm []float32) ([]float32, error) {
+	return sd.ProbabilitiesContext(context.Background(), pcm)
+}
+
+// ProbabilitiesContext is like Probabilities but stops processing as soon as
+// ctx is done, in which case ctx.Err() is returned.
+func (sd *Detector) ProbabilitiesContext(ctx context.Context, pcm []float32) ([]float32, error) {
+	if sd == nil {
+		return nil, ErrNilDetector
 	}
 
-	expectedInputLen := contextLen + sd.windowSize
-	if len(sd.inputBuf) != expectedInputLen {
-		sd.inputBuf = make([]float32, expectedInputLen)
-		sd.pcmInputDims = [2]C.int64_t{1, C.int64_t(expectedInputLen)}
+	if err := sd.acquire(); err != nil {
+		return nil, err
 	}
-	if sd.stateDims[0] == 0 {
-		sd.stateDims = [3]C.int64_t{2, 1, 128}
+	defer sd.release()
+
+	windowSize := sd.WindowSize()
+	if len(pcm) < windowSize {
+		return nil, ErrNotEnoughSamples
 	}
-	if sd.rateInputDims[0] == 0 {
-		sd.rateInputDims = [1]C.int64_t{1}
+
+	probs := make([]float32, 0, len(pcm)/windowSize)
+	for i := 0; i+windowSize <= len(pcm); i += windowSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		prob, err := sd.windowProbability(ctx, pcm[i:i+windowSize])
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, fmt.Errorf("infer failed: %w", err)
+		}
+		probs = append(probs, prob)
 	}
-	if sd.rateValue == 0 {
-		sd.rateValue = C.int64_t(sd.cfg.SampleRate)
+
+	return probs, nil
+}
+
+// terminateOnDone arranges for any in-flight run to be terminated as soon as
+// ctx is done. The returned function must be called once the run completes.
+func (sd *Detector) terminateOnDone(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {}
 	}
 
-	copy(sd.inputBuf[contextLen:], samples)
+	terminated := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		C.OrtApiReleaseStatus(sd.api, C.OrtApiRunOptionsSetTerminate(sd.api, sd.runOpts))
+		close(terminated)
+	})
 
-	// Create tensors
-	var pcmValue *C.OrtValue
-	status := C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, unsafe.Pointer(&sd.inputBuf[0]),
-		C.size_t(len(sd.inputBuf)*4), &sd.pcmInputDims[0], C.size_t(len(sd.pcmInputDims)),
-		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT, &pcmValue)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return 0, fmt.Errorf("failed to create value: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	return func() {
+		if stop() {
+			return
+		}
+		// The terminate flag was set, we need to clear it before any further run.
+		<-terminated
+		C.OrtApiReleaseStatus(sd.api, C.OrtApiRunOptionsUnsetTerminate(sd.api, sd.runOpts))
 	}
-	defer C.OrtApiReleaseValue(sd.api, pcmValue)
+}
 
-	var stateValue *C.OrtValue
-	status = C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, unsafe.Pointer(&sd.state[0]),
-		C.size_t(stateLen*4), &sd.stateDims[0], C.size_t(len(sd.stateDims)),
-		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT, &stateValue)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return 0, fmt.Errorf("failed to create value: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+// calloc allocates zeroed memory outside of the Go heap which is released on
+// Destroy. Buffers referenced by ONNX Runtime values across calls need to live
+// in C memory to comply with the cgo pointer passing rules.
+func (sd *Detector) calloc(n, size int) (unsafe.Pointer, error) {
+	ptr := C.calloc(C.size_t(n), C.size_t(size))
+	if ptr == nil {
+		return nil, fmt.Errorf("failed to allocate memory")
 	}
-	defer C.OrtApiReleaseValue(sd.api, stateValue)
+	sd.buffers = append(sd.buffers, ptr)
+	return ptr, nil
+}
 
-	var rateValue *C.OrtValue
-	status = C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, unsafe.Pointer(&sd.rateValue),
-		C.size_t(unsafe.Sizeof(sd.rateValue)), &sd.rateInputDims[0], C.size_t(len(sd.rateInputDims)),
-		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64, &rateValue)
-	defer C.OrtApiReleaseStatus(sd.api, status)
+// createTensor wraps the given memory into a value which is released on Destroy.
+func (sd *Detector) createTensor(data unsafe.Pointer, dataLen int, dims []C.int64_t, elemType C.ONNXTensorElementDataType) (*C.OrtValue, error) {
+	var value *C.OrtValue
+	status := C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, data, C.size_t(dataLen),
+		&dims[0], C.size_t(len(dims)), elemType, &value)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create value: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, sd.ortError("create value", status)
 	}
-	defer C.OrtApiReleaseValue(sd.api, rateValue)
+	sd.values = append(sd.values, value)
+	return value, nil
+}
 
-	// Run inference
-	inputs := []*C.OrtValue{pcmValue, stateValue, rateValue}
-	outputs := []*C.OrtValue{nil, nil}
+// bindTensors allocates the model inputs and outputs once and binds them to
+// the session so that inference can run without any further allocation.
+func (sd *Detector) bindTensors() error {
+	inputLen := sd.variant.contextLen + sd.windowSize
 
-	inputNames := []*C.char{
-		sd.cStrings["input"],
-		sd.cStrings["state"],
-		sd.cStrings["sr"],
+	inputPtr, err := sd.calloc(inputLen, 4)
+	if err != nil {
+		return err
 	}
-	outputNames := []*C.char{
-		sd.cStrings["output"],
-		sd.cStrings["stateN"],
+	sd.inputBuf = unsafe.Slice((*float32)(inputPtr), inputLen)
+
+	statePtr, err := sd.calloc(stateLen, 4)
+	if err != nil {
+		return err
 	}
-	status = C.OrtApiRun(sd.api, sd.session, nil, &inputNames[0], &inputs[0], C.size_t(len(inputNames)),
-		&outputNames[0], C.size_t(len(outputNames)), &outputs[0])
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return 0, fmt.Errorf("failed to run: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	sd.state = unsafe.Slice((*float32)(statePtr), stateLen)
+
+	stateOutPtr, err := sd.calloc(stateLen, 4)
+	if err != nil {
+		return err
 	}
+	sd.stateOut = unsafe.Slice((*float32)(stateOutPtr), stateLen)
 
-	// Get output values from tensor data
-	var prob unsafe.Pointer
-	var stateN unsafe.Pointer
+	probPtr, err := sd.calloc(1, 4)
+	if err != nil {
+		return err
+	}
+	sd.prob = (*float32)(probPtr)
 
-	status = C.OrtApiGetTensorMutableData(sd.api, outputs[0], &prob)
-	defer C.OrtApiReleaseStatus(sd.api, status)
-	if status != nil {
-		return 0, fmt.Errorf("failed to get tensor data: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+	ratePtr, err := sd.calloc(1, 8)
+	if err != nil {
+		return err
 	}
+	sd.rate = (*C.int64_t)(ratePtr)
+	*sd.rate = C.int64_t(sd.cfg.SampleRate)
 
-	status = C.OrtApiGetTensorMutableData(sd.api, outputs[1], &stateN)
-	defer C.OrtApiReleaseStatus(sd.api, status)
+	status := C.OrtApiCreateIoBinding(sd.api, sd.session, &sd.binding)
 	if status != nil {
-		return 0, fmt.Errorf("failed to get tensor data: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return sd.ortError("create binding", status)
 	}
 
-	C.memcpy(unsafe.Pointer(&sd.state[0]), stateN, stateLen*4)
+	bindInput := func(name string, value *C.OrtValue) error {
+		if status := C.OrtApiBindInput(sd.api, sd.binding, sd.cStrings[name], value); status != nil {
+			return sd.ortError(fmt.Sprintf("bind input %q", name), status)
+		}
+		return nil
+	}
+	bindOutput := func(name string, value *C.OrtValue) error {
+		if status := C.OrtApiBindOutput(sd.api, sd.binding, sd.cStrings[name], value); status != nil {
+			return sd.ortError(fmt.Sprintf("bind output %q", name), status)
+		}
+		return nil
+	}
 
-	C.OrtApiReleaseValue(sd.api, outputs[0])
-	C.OrtApiReleaseValue(sd.api, outputs[1])
+	pcmValue, err := sd.createTensor(inputPtr, inputLen*4, []C.int64_t{1, C.int64_t(inputLen)},
+		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT)
+	if err != nil {
+		return err
+	}
+	if err := bindInput("input", pcmValue); err != nil {
+		return err
+	}
+
+	rateValue, err := sd.createTensor(ratePtr, 8, []C.int64_t{1}, C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64)
+	if err != nil {
+		return err
+	}
+	if err := bindInput("sr", rateValue); err != nil {
+		return err
+	}
+
+	probValue, err := sd.createTensor(probPtr, 4, []C.int64_t{1, 1}, C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT)
+	if err != nil {
+		return err
+	}
+	if err := bindOutput("output", probValue); err != nil {
+		return err
+	}
+
+	// State tensors are stored back to back, reading from state and writing
+	// into stateOut which gets copied over after each run.
+	offset := 0
+	for _, st := range sd.variant.states {
+		dims := []C.int64_t{C.int64_t(st.dims[0]), C.int64_t(st.dims[1]), C.int64_t(st.dims[2])}
+
+		stateValue, err := sd.createTensor(unsafe.Pointer(&sd.state[offset]), st.len()*4, dims,
+			C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT)
+		if err != nil {
+			return err
+		}
+		if err := bindInput(st.input, stateValue); err != nil {
+			return err
+		}
+
+		stateOutValue, err := sd.createTensor(unsafe.Pointer(&sd.stateOut[offset]), st.len()*4, dims,
+			C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT)
+		if err != nil {
+			return err
+		}
+		if err := bindOutput(st.output, stateOutValue); err != nil {
+			return err
+		}
+
+		offset += st.len()
+	}
+
+	return nil
+}
+
+// windowProbability returns the speech probability of a window, unless the
+// energy gate skips it, in which case zero is returned. Skipped windows age the
+// model state through digital silence until it settles, only then skipping
+// inference, and feed the context of the next window.
+func (sd *Detector) windowProbability(ctx context.Context, window []float32) (float32, error) {
+	sd.windows.Add(1)
+
+	if sd.gate == nil || !sd.gate.skip(window) {
+		return sd.infer(ctx, window)
+	}
+
+	if sd.gate.settled {
+		sd.skippedWindows.Add(1)
+	} else {
+		copy(sd.gate.prevState, sd.state)
+		if _, err := sd.infer(ctx, sd.gate.silence); err != nil {
+			return 0, err
+		}
+		sd.gate.aged(sd.state)
+	}
+
+	ctxLen := sd.variant.contextLen
+	if len(window) >= ctxLen && len(sd.inputBuf) >= ctxLen {
+		copy(sd.inputBuf[:ctxLen], window[len(window)-ctxLen:])
+	}
+
+	return 0, nil
+}
+
+func (sd *Detector) infer(ctx context.Context, samples []float32) (float32, error) {
+	if sd == nil {
+		return 0, ErrNilDetector
+	}
+
+	if sd.windowSize == 0 {
+		sd.windowSize = vad.WindowSizeForSampleRate(sd.cfg.SampleRate)
+	}
+	if len(samples) != sd.windowSize {
+		return 0, fmt.Errorf("%w: expected %d, got %d", ErrInvalidSamplesLength, sd.windowSize, len(samples))
+	}
+
+	if sd.binding == nil {
+		return 0, fmt.Errorf("detector is not initialized")
+	}
+
+	ctxLen := sd.variant.contextLen
+	copy(sd.inputBuf[ctxLen:], samples)
+
+	// Run inference
+	done := sd.terminateOnDone(ctx)
+	status := C.OrtApiRunWithBinding(sd.api, sd.session, sd.runOpts, sd.binding)
+	done()
+	if status != nil {
+		if err := ctx.Err(); err != nil {
+			C.OrtApiReleaseStatus(sd.api, status)
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: %w", ErrRuntime, sd.ortError("run", status))
+	}
 
-	copy(sd.inputBuf[:contextLen], sd.inputBuf[sd.windowSize:])
+	copy(sd.state, sd.stateOut)
+	copy(sd.inputBuf[:ctxLen], sd.inputBuf[sd.windowSize:])
 
 	// Return speech probability
-	return *(*float32)(prob), nil
+	return *sd.prob, nil
 }
@@ -0,0 +1,377 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"unsafe"
+)
+
+// TensorInfo describes an input or output tensor of the model.
+type TensorInfo struct {
+	// The name of the tensor as declared in the model graph.
+	Name string
+	// The element type of the tensor (e.g. "float32", "int64").
+	ElementType string
+	// The dimensions of the tensor. Dynamic dimensions are reported as -1.
+	Shape []int64
+}
+
+// ModelInfo contains metadata about the loaded ONNX model.
+type ModelInfo struct {
+	// The model inputs, in declaration order.
+	Inputs []TensorInfo
+	// The model outputs, in declaration order.
+	Outputs []TensorInfo
+	// The ONNX IR version the model was serialized with.
+	IRVersion int64
+	// The operator set versions imported by the model keyed by domain.
+	// The default ONNX domain is the empty string.
+	Opsets map[string]int64
+	// The name of the tool that produced the model.
+	ProducerName string
+	// The name of the model graph.
+	GraphName string
+	// The model domain.
+	Domain string
+	// The model description (doc string).
+	Description string
+	// The model version.
+	Version int64
+	// Any custom metadata key/value pairs stored in the model.
+	CustomMetadata map[string]string
+}
+
+func (mi ModelInfo) clone() ModelInfo {
+	cloneTensors := func(tensors []TensorInfo) []TensorInfo {
+		out := slices.Clone(tensors)
+		for i := range out {
+			out[i].Shape = slices.Clone(out[i].Shape)
+		}
+		return out
+	}
+
+	mi.Inputs = cloneTensors(mi.Inputs)
+	mi.Outputs = cloneTensors(mi.Outputs)
+	mi.Opsets = maps.Clone(mi.Opsets)
+	mi.CustomMetadata = maps.Clone(mi.CustomMetadata)
+
+	return mi
+}
+
+// ModelInfo returns metadata about the loaded model, including its input and
+// output signature.
+func (sd *Detector) ModelInfo() ModelInfo {
+	if sd == nil {
+		return ModelInfo{}
+	}
+	return sd.modelInfo.clone()
+}
+
+func elementTypeName(t C.enum_ONNXTensorElementDataType) string {
+	switch t {
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT:
+		return "float32"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_DOUBLE:
+		return "float64"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT16:
+		return "float16"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT8:
+		return "int8"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT16:
+		return "int16"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT32:
+		return "int32"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64:
+		return "int64"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_UINT8:
+		return "uint8"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_UINT16:
+		return "uint16"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_BOOL:
+		return "bool"
+	case C.ONNX_TENSOR_ELEMENT_DATA_TYPE_STRING:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+func (sd *Detector) allocatedString(allocator *C.OrtAllocator, get func(value **C.char) *C.OrtStatus) (string, error) {
+	var value *C.char
+	if status := get(&value); status != nil {
+		return "", sd.ortError("get value", status)
+	}
+	defer sd.allocatorFree(allocator, unsafe.Pointer(value))
+	return C.GoString(value), nil
+}
+
+func (sd *Detector) allocatorFree(allocator *C.OrtAllocator, ptr unsafe.Pointer) {
+	if ptr == nil {
+		return
+	}
+	C.OrtApiReleaseStatus(sd.api, C.OrtApiAllocatorFree(sd.api, allocator, ptr))
+}
+
+func (sd *Detector) tensorInfo(typeInfo *C.OrtTypeInfo) (TensorInfo, error) {
+	var info TensorInfo
+
+	var onnxType C.enum_ONNXType
+	if status := C.OrtApiGetOnnxTypeFromTypeInfo(sd.api, typeInfo, &onnxType); status != nil {
+		return info, sd.ortError("get type", status)
+	}
+	if onnxType != C.ONNX_TYPE_TENSOR {
+		info.ElementType = "non-tensor"
+		return info, nil
+	}
+
+	var tensorInfo *C.OrtTensorTypeAndShapeInfo
+	if status := C.OrtApiCastTypeInfoToTensorInfo(sd.api, typeInfo, &tensorInfo); status != nil {
+		return info, sd.ortError("get tensor info", status)
+	}
+
+	var elemType C.enum_ONNXTensorElementDataType
+	if status := C.OrtApiGetTensorElementType(sd.api, tensorInfo, &elemType); status != nil {
+		return info, sd.ortError("get element type", status)
+	}
+	info.ElementType = elementTypeName(elemType)
+
+	var dimsCount C.size_t
+	if status := C.OrtApiGetDimensionsCount(sd.api, tensorInfo, &dimsCount); status != nil {
+		return info, sd.ortError("get dimensions count", status)
+	}
+	if dimsCount == 0 {
+		return info, nil
+	}
+
+	dims := make([]C.int64_t, dimsCount)
+	if status := C.OrtApiGetDimensions(sd.api, tensorInfo, &dims[0], dimsCount); status != nil {
+		return info, sd.ortError("get dimensions", status)
+	}
+	info.Shape = make([]int64, dimsCount)
+	for i, dim := range dims {
+		info.Shape[i] = int64(dim)
+	}
+
+	return info, nil
+}
+
+func (sd *Detector) sessionTensors(allocator *C.OrtAllocator, inputs bool) ([]TensorInfo, error) {
+	var count C.size_t
+	var status *C.OrtStatus
+	if inputs {
+		status = C.OrtApiSessionGetInputCount(sd.api, sd.session, &count)
+	} else {
+		status = C.OrtApiSessionGetOutputCount(sd.api, sd.session, &count)
+	}
+	if status != nil {
+		return nil, sd.ortError("get tensors count", status)
+	}
+
+	tensors := make([]TensorInfo, 0, count)
+	for i := C.size_t(0); i < count; i++ {
+		name, err := sd.allocatedString(allocator, func(value **C.char) *C.OrtStatus {
+			if inputs {
+				return C.OrtApiSessionGetInputName(sd.api, sd.session, i, allocator, value)
+			}
+			return C.OrtApiSessionGetOutputName(sd.api, sd.session, i, allocator, value)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tensor name: %w", err)
+		}
+
+		var typeInfo *C.OrtTypeInfo
+		if inputs {
+			status = C.OrtApiSessionGetInputTypeInfo(sd.api, sd.session, i, &typeInfo)
+		} else {
+			status = C.OrtApiSessionGetOutputTypeInfo(sd.api, sd.session, i, &typeInfo)
+		}
+		if status != nil {
+			return nil, sd.ortError("get type info", status)
+		}
+
+		info, err := sd.tensorInfo(typeInfo)
+		C.OrtApiReleaseTypeInfo(sd.api, typeInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tensor info for %q: %w", name, err)
+		}
+		info.Name = name
+
+		tensors = append(tensors, info)
+	}
+
+	return tensors, nil
+}
+
+func (sd *Detector) loadModelInfo() (ModelInfo, error) {
+	var info ModelInfo
+
+	var allocator *C.OrtAllocator
+	if status := C.OrtApiGetAllocatorWithDefaultOptions(sd.api, &allocator); status != nil {
+		return info, sd.ortError("get allocator", status)
+	}
+
+	var err error
+	info.Inputs, err = sd.sessionTensors(allocator, true)
+	if err != nil {
+		return info, fmt.Errorf("failed to get inputs: %w", err)
+	}
+	info.Outputs, err = sd.sessionTensors(allocator, false)
+	if err != nil {
+		return info, fmt.Errorf("failed to get outputs: %w", err)
+	}
+
+	var metadata *C.OrtModelMetadata
+	if status := C.OrtApiSessionGetModelMetadata(sd.api, sd.session, &metadata); status != nil {
+		return info, sd.ortError("get model metadata", status)
+	}
+	defer C.OrtApiReleaseModelMetadata(sd.api, metadata)
+
+	info.ProducerName, err = sd.allocatedString(allocator, func(value **C.char) *C.OrtStatus {
+		return C.OrtApiModelMetadataGetProducerName(sd.api, metadata, allocator, value)
+	})
+	if err != nil {
+		return info, fmt.Errorf("failed to get producer name: %w", err)
+	}
+
+	info.GraphName, err = sd.allocatedString(allocator, func(value **C.char) *C.OrtStatus {
+		return C.OrtApiModelMetadataGetGraphName(sd.api, metadata, allocator, value)
+	})
+	if err != nil {
+		return info, fmt.Errorf("failed to get graph name: %w", err)
+	}
+
+	info.Domain, err = sd.allocatedString(allocator, func(value **C.char) *C.OrtStatus {
+		return C.OrtApiModelMetadataGetDomain(sd.api, metadata, allocator, value)
+	})
+	if err != nil {
+		return info, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	info.Description, err = sd.allocatedString(allocator, func(value **C.char) *C.OrtStatus {
+		return C.OrtApiModelMetadataGetDescription(sd.api, metadata, allocator, value)
+	})
+	if err != nil {
+		return info, fmt.Errorf("failed to get description: %w", err)
+	}
+
+	var version C.int64_t
+	if status := C.OrtApiModelMetadataGetVersion(sd.api, metadata, &version); status != nil {
+		return info, sd.ortError("get version", status)
+	}
+	info.Version = int64(version)
+
+	var keys **C.char
+	var numKeys C.int64_t
+	if status := C.OrtApiModelMetadataGetCustomMetadataMapKeys(sd.api, metadata, allocator, &keys, &numKeys); status != nil {
+		return info, sd.ortError("get custom metadata keys", status)
+	}
+	info.CustomMetadata = make(map[string]string, int(numKeys))
+	if numKeys > 0 {
+		defer sd.allocatorFree(allocator, unsafe.Pointer(keys))
+		for _, key := range unsafe.Slice(keys, int(numKeys)) {
+			value, err := sd.allocatedString(allocator, func(value **C.char) *C.OrtStatus {
+				return C.OrtApiModelMetadataLookupCustomMetadataMap(sd.api, metadata, allocator, key, value)
+			})
+			info.CustomMetadata[C.GoString(key)] = value
+			sd.allocatorFree(allocator, unsafe.Pointer(key))
+			if err != nil {
+				return info, fmt.Errorf("failed to get custom metadata: %w", err)
+			}
+		}
+	}
+
+	// ONNX Runtime does not expose the IR version nor the opset imports so we
+	// read them from the model file directly.
+	info.IRVersion, info.Opsets, err = readONNXOpsets(sd.cfg.ModelPath)
+	if err != nil {
+		return info, fmt.Errorf("failed to read opsets: %w", err)
+	}
+
+	return info, nil
+}
+
+type tensorSpec struct {
+	name        string
+	elementType string
+	// The expected dimensions, -1 matches any size. A nil shape skips
+	// the rank check altogether.
+	shape []int64
+}
+
+func (ts tensorSpec) validate(kind string, tensors []TensorInfo) error {
+	idx := slices.IndexFunc(tensors, func(t TensorInfo) bool {
+		return t.Name == ts.name
+	})
+	if idx < 0 {
+		return fmt.Errorf("missing %s %q", kind, ts.name)
+	}
+	t := tensors[idx]
+
+	if t.ElementType != ts.elementType {
+		return fmt.Errorf("%s %q: expected element type %s, got %s", kind, ts.name, ts.elementType, t.ElementType)
+	}
+
+	if ts.shape == nil {
+		return nil
+	}
+
+	if len(t.Shape) != len(ts.shape) {
+		return fmt.Errorf("%s %q: expected shape %v, got %v", kind, ts.name, ts.shape, t.Shape)
+	}
+	for i, dim := range ts.shape {
+		if dim >= 0 && t.Shape[i] >= 0 && dim != t.Shape[i] {
+			return fmt.Errorf("%s %q: expected shape %v, got %v", kind, ts.name, ts.shape, t.Shape)
+		}
+	}
+
+	return nil
+}
+
+type modelSignature struct {
+	inputs  []tensorSpec
+	outputs []tensorSpec
+}
+
+// The Silero VAD v5 model signature. The sampling rate is declared either as
+// a scalar or as a single element vector depending on the export so we
+// don't check its rank.
+var sileroV5Signature = modelSignature{
+	inputs: []tensorSpec{
+		{name: "input", elementType: "float32", shape: []int64{-1, -1}},
+		{name: "state", elementType: "float32", shape: []int64{2, -1, 128}},
+		{name: "sr", elementType: "int64"},
+	},
+	outputs: []tensorSpec{
+		{name: "output", elementType: "float32", shape: []int64{-1, 1}},
+		{name: "stateN", elementType: "float32", shape: []int64{2, -1, 128}},
+	},
+}
+
+func (ms modelSignature) validate(info ModelInfo) error {
+	for _, spec := range ms.inputs {
+		if err := spec.validate("input", info.Inputs); err != nil {
+			return err
+		}
+	}
+
+	// Any extra input would be required to run the model so we fail early.
+	for _, input := range info.Inputs {
+		if !slices.ContainsFunc(ms.inputs, func(ts tensorSpec) bool { return ts.name == input.Name }) {
+			return fmt.Errorf("unexpected input %q", input.Name)
+		}
+	}
+
+	for _, spec := range ms.outputs {
+		if err := spec.validate("output", info.Outputs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
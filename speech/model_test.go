@@ -0,0 +1,237 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadONNXOpsets(t *testing.T) {
+	irVersion, opsets, err := readONNXOpsets("../testfiles/silero_vad.onnx")
+	require.NoError(t, err)
+	require.Equal(t, int64(8), irVersion)
+	require.Equal(t, map[string]int64{"": 16}, opsets)
+
+	_, _, err = readONNXOpsets("../testfiles/missing.onnx")
+	require.Error(t, err)
+}
+
+func TestModelSignatureValidate(t *testing.T) {
+	v5Info := func() ModelInfo {
+		return ModelInfo{
+			Inputs: []TensorInfo{
+				{Name: "input", ElementType: "float32", Shape: []int64{-1, -1}},
+				{Name: "state", ElementType: "float32", Shape: []int64{2, -1, 128}},
+				{Name: "sr", ElementType: "int64"},
+			},
+			Outputs: []TensorInfo{
+				{Name: "output", ElementType: "float32", Shape: []int64{-1, 1}},
+				{Name: "stateN", ElementType: "float32", Shape: []int64{-1, -1, -1}},
+			},
+		}
+	}
+
+	tcs := []struct {
+		name   string
+		modify func(info *ModelInfo)
+		err    string
+	}{
+		{
+			name:   "valid",
+			modify: func(_ *ModelInfo) {},
+		},
+		{
+			name: "missing input",
+			modify: func(info *ModelInfo) {
+				info.Inputs[1].Name = "h"
+			},
+			err: `missing input "state"`,
+		},
+		{
+			name: "unexpected input",
+			modify: func(info *ModelInfo) {
+				info.Inputs = append(info.Inputs, TensorInfo{Name: "c", ElementType: "float32"})
+			},
+			err: `unexpected input "c"`,
+		},
+		{
+			name: "invalid element type",
+			modify: func(info *ModelInfo) {
+				info.Inputs[2].ElementType = "int32"
+			},
+			err: `input "sr": expected element type int64, got int32`,
+		},
+		{
+			name: "invalid rank",
+			modify: func(info *ModelInfo) {
+				info.Inputs[1].Shape = []int64{2, 128}
+			},
+			err: `input "state": expected shape [2 -1 128], got [2 128]`,
+		},
+		{
+			name: "invalid dimension",
+			modify: func(info *ModelInfo) {
+				info.Outputs[1].Shape = []int64{2, 1, 64}
+			},
+			err: `output "stateN": expected shape [2 -1 128], got [2 1 64]`,
+		},
+		{
+			name: "missing output",
+			modify: func(info *ModelInfo) {
+				info.Outputs = info.Outputs[:1]
+			},
+			err: `missing output "stateN"`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			info := v5Info()
+			tc.modify(&info)
+			err := sileroV5Signature.validate(info)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDetectModelVariant(t *testing.T) {
+	v4Info := ModelInfo{
+		Inputs: []TensorInfo{
+			{Name: "input", ElementType: "float32", Shape: []int64{-1, -1}},
+			{Name: "sr", ElementType: "int64", Shape: []int64{1}},
+			{Name: "h", ElementType: "float32", Shape: []int64{2, -1, 64}},
+			{Name: "c", ElementType: "float32", Shape: []int64{2, -1, 64}},
+		},
+		Outputs: []TensorInfo{
+			{Name: "output", ElementType: "float32", Shape: []int64{-1, 1}},
+			{Name: "hn", ElementType: "float32", Shape: []int64{2, -1, 64}},
+			{Name: "cn", ElementType: "float32", Shape: []int64{2, -1, 64}},
+		},
+	}
+
+	v5Info := ModelInfo{
+		Inputs: []TensorInfo{
+			{Name: "input", ElementType: "float32", Shape: []int64{-1, -1}},
+			{Name: "state", ElementType: "float32", Shape: []int64{2, -1, 128}},
+			{Name: "sr", ElementType: "int64"},
+		},
+		Outputs: []TensorInfo{
+			{Name: "output", ElementType: "float32", Shape: []int64{-1, 1}},
+			{Name: "stateN", ElementType: "float32", Shape: []int64{-1, -1, -1}},
+		},
+	}
+
+	t.Run("v4", func(t *testing.T) {
+		variant, err := detectModelVariant(v4Info)
+		require.NoError(t, err)
+		require.Equal(t, ModelVersionV4, variant.version)
+		require.Equal(t, []string{"input", "sr", "h", "c"}, variant.inputNames())
+		require.Equal(t, []string{"output", "hn", "cn"}, variant.outputNames())
+		require.Zero(t, variant.contextLen)
+
+		total := 0
+		for _, st := range variant.states {
+			total += st.len()
+		}
+		require.Equal(t, stateLen, total)
+	})
+
+	t.Run("v5", func(t *testing.T) {
+		variant, err := detectModelVariant(v5Info)
+		require.NoError(t, err)
+		require.Equal(t, ModelVersionV5, variant.version)
+		require.Equal(t, []string{"input", "sr", "state"}, variant.inputNames())
+		require.Equal(t, []string{"output", "stateN"}, variant.outputNames())
+		require.Equal(t, contextLen, variant.contextLen)
+	})
+
+	t.Run("v6 from metadata", func(t *testing.T) {
+		info := v5Info
+		info.CustomMetadata = map[string]string{"version": "v6.0"}
+		variant, err := detectModelVariant(info)
+		require.NoError(t, err)
+		require.Equal(t, ModelVersionV6, variant.version)
+
+		info.CustomMetadata = nil
+		info.Version = 6
+		variant, err = detectModelVariant(info)
+		require.NoError(t, err)
+		require.Equal(t, ModelVersionV6, variant.version)
+	})
+
+	t.Run("unrecognized", func(t *testing.T) {
+		info := v5Info
+		info.Inputs = info.Inputs[:2]
+		_, err := detectModelVariant(info)
+		require.EqualError(t, err, `unrecognized model signature: missing input "sr"`)
+	})
+
+	t.Run("explicit version mismatch", func(t *testing.T) {
+		variant, ok := variantForVersion(ModelVersionV4)
+		require.True(t, ok)
+		require.EqualError(t, variant.signature.validate(v5Info), `missing input "h"`)
+
+		_, ok = variantForVersion(ModelVersionAuto)
+		require.False(t, ok)
+	})
+}
+
+func TestDetectorModelInfo(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sd)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	info := sd.ModelInfo()
+
+	var inputs []string
+	for _, input := range info.Inputs {
+		inputs = append(inputs, input.Name)
+	}
+	require.ElementsMatch(t, []string{"input", "state", "sr"}, inputs)
+
+	var outputs []string
+	for _, output := range info.Outputs {
+		outputs = append(outputs, output.Name)
+	}
+	require.ElementsMatch(t, []string{"output", "stateN"}, outputs)
+
+	// These describe testfiles/silero_vad.onnx (Silero VAD v5) as reported by
+	// ONNX Runtime v1.18.1.
+	require.Equal(t, "spox", info.ProducerName)
+	require.Equal(t, int64(8), info.IRVersion)
+	require.Equal(t, map[string]int64{"": 16}, info.Opsets)
+	require.NotNil(t, info.CustomMetadata)
+	require.Equal(t, ModelVersionV5, sd.ModelVersion())
+
+	// Returned info should be a copy.
+	info.Inputs[0].Name = "modified"
+	require.NotEqual(t, "modified", sd.ModelInfo().Inputs[0].Name)
+}
+
+func TestParseModelVersion(t *testing.T) {
+	for _, v := range []ModelVersion{ModelVersionAuto, ModelVersionV4, ModelVersionV5, ModelVersionV6} {
+		parsed, err := ParseModelVersion(v.String())
+		require.NoError(t, err)
+		require.Equal(t, v, parsed)
+	}
+
+	parsed, err := ParseModelVersion("V5")
+	require.NoError(t, err)
+	require.Equal(t, ModelVersionV5, parsed)
+
+	_, err = ParseModelVersion("v7")
+	require.EqualError(t, err, `invalid model version "v7"`)
+}
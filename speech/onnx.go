@@ -0,0 +1,119 @@
+package speech
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+)
+
+// ONNX ModelProto field numbers we care about.
+const (
+	onnxModelIRVersionField   = 1
+	onnxModelOpsetImportField = 8
+
+	onnxOpsetDomainField  = 1
+	onnxOpsetVersionField = 2
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+type protoField struct {
+	num   int
+	value uint64
+	data  []byte
+}
+
+// nextProtoField decodes the protobuf field at the beginning of buf and
+// returns the remaining bytes.
+func nextProtoField(buf []byte) (protoField, []byte, error) {
+	var f protoField
+
+	key, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return f, nil, fmt.Errorf("invalid field key")
+	}
+	buf = buf[n:]
+	f.num = int(key >> 3)
+
+	switch key & 0x7 {
+	case wireVarint:
+		f.value, n = binary.Uvarint(buf)
+		if n <= 0 {
+			return f, nil, fmt.Errorf("invalid varint for field %d", f.num)
+		}
+		buf = buf[n:]
+	case wireFixed64:
+		if len(buf) < 8 {
+			return f, nil, fmt.Errorf("truncated field %d", f.num)
+		}
+		f.value = binary.LittleEndian.Uint64(buf)
+		buf = buf[8:]
+	case wireFixed32:
+		if len(buf) < 4 {
+			return f, nil, fmt.Errorf("truncated field %d", f.num)
+		}
+		f.value = uint64(binary.LittleEndian.Uint32(buf))
+		buf = buf[4:]
+	case wireBytes:
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || size > math.MaxInt || int(size) > len(buf)-n {
+			return f, nil, fmt.Errorf("truncated field %d", f.num)
+		}
+		f.data = buf[n : n+int(size)]
+		buf = buf[n+int(size):]
+	default:
+		return f, nil, fmt.Errorf("unsupported wire type %d for field %d", key&0x7, f.num)
+	}
+
+	return f, buf, nil
+}
+
+// readONNXOpsets returns the IR version and the imported operator sets
+// (keyed by domain) of the ONNX model at the given path.
+func readONNXOpsets(path string) (int64, map[string]int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read model: %w", err)
+	}
+
+	var irVersion int64
+	opsets := map[string]int64{}
+	for len(data) > 0 {
+		var field protoField
+		field, data, err = nextProtoField(data)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to parse model: %w", err)
+		}
+
+		switch field.num {
+		case onnxModelIRVersionField:
+			irVersion = int64(field.value)
+		case onnxModelOpsetImportField:
+			var domain string
+			var version int64
+			for opset := field.data; len(opset) > 0; {
+				var f protoField
+				f, opset, err = nextProtoField(opset)
+				if err != nil {
+					return 0, nil, fmt.Errorf("failed to parse opset: %w", err)
+				}
+				switch f.num {
+				case onnxOpsetDomainField:
+					domain = string(f.data)
+				case onnxOpsetVersionField:
+					version = int64(f.value)
+				}
+			}
+			opsets[domain] = version
+		}
+	}
+
+	return irVersion, opsets, nil
+}
@@ -80,3 +80,137 @@ OrtStatus* OrtApiRun(OrtApi* api, OrtSession* session, const OrtRunOptions* run_
 OrtStatus* OrtApiGetTensorMutableData(OrtApi* api, OrtValue* value, void** data) {
   return api->GetTensorMutableData(value, data);
 }
+
+OrtStatus* OrtApiGetAllocatorWithDefaultOptions(OrtApi* api, OrtAllocator** allocator) {
+  return api->GetAllocatorWithDefaultOptions(allocator);
+}
+
+OrtStatus* OrtApiAllocatorFree(OrtApi* api, OrtAllocator* allocator, void* ptr) {
+  return api->AllocatorFree(allocator, ptr);
+}
+
+OrtStatus* OrtApiSessionGetInputCount(OrtApi* api, OrtSession* session, size_t* count) {
+  return api->SessionGetInputCount(session, count);
+}
+
+OrtStatus* OrtApiSessionGetOutputCount(OrtApi* api, OrtSession* session, size_t* count) {
+  return api->SessionGetOutputCount(session, count);
+}
+
+OrtStatus* OrtApiSessionGetInputName(OrtApi* api, OrtSession* session, size_t index, OrtAllocator* allocator, char** name) {
+  return api->SessionGetInputName(session, index, allocator, name);
+}
+
+OrtStatus* OrtApiSessionGetOutputName(OrtApi* api, OrtSession* session, size_t index, OrtAllocator* allocator, char** name) {
+  return api->SessionGetOutputName(session, index, allocator, name);
+}
+
+OrtStatus* OrtApiSessionGetInputTypeInfo(OrtApi* api, OrtSession* session, size_t index, OrtTypeInfo** info) {
+  return api->SessionGetInputTypeInfo(session, index, info);
+}
+
+OrtStatus* OrtApiSessionGetOutputTypeInfo(OrtApi* api, OrtSession* session, size_t index, OrtTypeInfo** info) {
+  return api->SessionGetOutputTypeInfo(session, index, info);
+}
+
+void OrtApiReleaseTypeInfo(OrtApi* api, OrtTypeInfo* info) {
+  return api->ReleaseTypeInfo(info);
+}
+
+OrtStatus* OrtApiGetOnnxTypeFromTypeInfo(OrtApi* api, OrtTypeInfo* info, enum ONNXType* type) {
+  return api->GetOnnxTypeFromTypeInfo(info, type);
+}
+
+OrtStatus* OrtApiCastTypeInfoToTensorInfo(OrtApi* api, OrtTypeInfo* info, const OrtTensorTypeAndShapeInfo** tensor_info) {
+  return api->CastTypeInfoToTensorInfo(info, tensor_info);
+}
+
+OrtStatus* OrtApiGetTensorElementType(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, enum ONNXTensorElementDataType* type) {
+  return api->GetTensorElementType(info, type);
+}
+
+OrtStatus* OrtApiGetDimensionsCount(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, size_t* count) {
+  return api->GetDimensionsCount(info, count);
+}
+
+OrtStatus* OrtApiGetDimensions(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, int64_t* dims, size_t dims_len) {
+  return api->GetDimensions(info, dims, dims_len);
+}
+
+OrtStatus* OrtApiSessionGetModelMetadata(OrtApi* api, OrtSession* session, OrtModelMetadata** metadata) {
+  return api->SessionGetModelMetadata(session, metadata);
+}
+
+void OrtApiReleaseModelMetadata(OrtApi* api, OrtModelMetadata* metadata) {
+  return api->ReleaseModelMetadata(metadata);
+}
+
+OrtStatus* OrtApiModelMetadataGetProducerName(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value) {
+  return api->ModelMetadataGetProducerName(metadata, allocator, value);
+}
+
+OrtStatus* OrtApiModelMetadataGetGraphName(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value) {
+  return api->ModelMetadataGetGraphName(metadata, allocator, value);
+}
+
+OrtStatus* OrtApiModelMetadataGetDomain(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value) {
+  return api->ModelMetadataGetDomain(metadata, allocator, value);
+}
+
+OrtStatus* OrtApiModelMetadataGetDescription(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value) {
+  return api->ModelMetadataGetDescription(metadata, allocator, value);
+}
+
+OrtStatus* OrtApiModelMetadataGetVersion(OrtApi* api, OrtModelMetadata* metadata, int64_t* version) {
+  return api->ModelMetadataGetVersion(metadata, version);
+}
+
+OrtStatus* OrtApiModelMetadataGetCustomMetadataMapKeys(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator,
+    char*** keys, int64_t* num_keys) {
+  return api->ModelMetadataGetCustomMetadataMapKeys(metadata, allocator, keys, num_keys);
+}
+
+OrtStatus* OrtApiModelMetadataLookupCustomMetadataMap(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator,
+    const char* key, char** value) {
+  return api->ModelMetadataLookupCustomMetadataMap(metadata, allocator, key, value);
+}
+
+OrtStatus* OrtApiCreateRunOptions(OrtApi* api, OrtRunOptions** opts) {
+  return api->CreateRunOptions(opts);
+}
+
+void OrtApiReleaseRunOptions(OrtApi* api, OrtRunOptions* opts) {
+  return api->ReleaseRunOptions(opts);
+}
+
+OrtStatus* OrtApiRunOptionsSetTerminate(OrtApi* api, OrtRunOptions* opts) {
+  return api->RunOptionsSetTerminate(opts);
+}
+
+OrtStatus* OrtApiRunOptionsUnsetTerminate(OrtApi* api, OrtRunOptions* opts) {
+  return api->RunOptionsUnsetTerminate(opts);
+}
+
+OrtStatus* OrtApiCreateIoBinding(OrtApi* api, OrtSession* session, OrtIoBinding** binding) {
+  return api->CreateIoBinding(session, binding);
+}
+
+void OrtApiReleaseIoBinding(OrtApi* api, OrtIoBinding* binding) {
+  return api->ReleaseIoBinding(binding);
+}
+
+OrtStatus* OrtApiBindInput(OrtApi* api, OrtIoBinding* binding, const char* name, const OrtValue* value) {
+  return api->BindInput(binding, name, value);
+}
+
+OrtStatus* OrtApiBindOutput(OrtApi* api, OrtIoBinding* binding, const char* name, const OrtValue* value) {
+  return api->BindOutput(binding, name, value);
+}
+
+OrtStatus* OrtApiRunWithBinding(OrtApi* api, OrtSession* session, const OrtRunOptions* run_options, const OrtIoBinding* binding) {
+  return api->RunWithBinding(session, run_options, binding);
+}
+
+OrtErrorCode OrtApiGetErrorCode(OrtApi* api, OrtStatus* status) {
+  return api->GetErrorCode(status);
+}
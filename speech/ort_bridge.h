@@ -31,3 +31,45 @@ OrtStatus* OrtApiRun(OrtApi* api, OrtSession* session, const OrtRunOptions* run_
     const char* const* output_names, size_t output_names_len, OrtValue** outputs);
 
 OrtStatus* OrtApiGetTensorMutableData(OrtApi* api, OrtValue* value, void** data);
+
+OrtStatus* OrtApiGetAllocatorWithDefaultOptions(OrtApi* api, OrtAllocator** allocator);
+OrtStatus* OrtApiAllocatorFree(OrtApi* api, OrtAllocator* allocator, void* ptr);
+
+OrtStatus* OrtApiSessionGetInputCount(OrtApi* api, OrtSession* session, size_t* count);
+OrtStatus* OrtApiSessionGetOutputCount(OrtApi* api, OrtSession* session, size_t* count);
+OrtStatus* OrtApiSessionGetInputName(OrtApi* api, OrtSession* session, size_t index, OrtAllocator* allocator, char** name);
+OrtStatus* OrtApiSessionGetOutputName(OrtApi* api, OrtSession* session, size_t index, OrtAllocator* allocator, char** name);
+OrtStatus* OrtApiSessionGetInputTypeInfo(OrtApi* api, OrtSession* session, size_t index, OrtTypeInfo** info);
+OrtStatus* OrtApiSessionGetOutputTypeInfo(OrtApi* api, OrtSession* session, size_t index, OrtTypeInfo** info);
+void OrtApiReleaseTypeInfo(OrtApi* api, OrtTypeInfo* info);
+
+OrtStatus* OrtApiGetOnnxTypeFromTypeInfo(OrtApi* api, OrtTypeInfo* info, enum ONNXType* type);
+OrtStatus* OrtApiCastTypeInfoToTensorInfo(OrtApi* api, OrtTypeInfo* info, const OrtTensorTypeAndShapeInfo** tensor_info);
+OrtStatus* OrtApiGetTensorElementType(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, enum ONNXTensorElementDataType* type);
+OrtStatus* OrtApiGetDimensionsCount(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, size_t* count);
+OrtStatus* OrtApiGetDimensions(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, int64_t* dims, size_t dims_len);
+
+OrtStatus* OrtApiSessionGetModelMetadata(OrtApi* api, OrtSession* session, OrtModelMetadata** metadata);
+void OrtApiReleaseModelMetadata(OrtApi* api, OrtModelMetadata* metadata);
+OrtStatus* OrtApiModelMetadataGetProducerName(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value);
+OrtStatus* OrtApiModelMetadataGetGraphName(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value);
+OrtStatus* OrtApiModelMetadataGetDomain(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value);
+OrtStatus* OrtApiModelMetadataGetDescription(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator, char** value);
+OrtStatus* OrtApiModelMetadataGetVersion(OrtApi* api, OrtModelMetadata* metadata, int64_t* version);
+OrtStatus* OrtApiModelMetadataGetCustomMetadataMapKeys(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator,
+    char*** keys, int64_t* num_keys);
+OrtStatus* OrtApiModelMetadataLookupCustomMetadataMap(OrtApi* api, OrtModelMetadata* metadata, OrtAllocator* allocator,
+    const char* key, char** value);
+
+OrtStatus* OrtApiCreateRunOptions(OrtApi* api, OrtRunOptions** opts);
+void OrtApiReleaseRunOptions(OrtApi* api, OrtRunOptions* opts);
+OrtStatus* OrtApiRunOptionsSetTerminate(OrtApi* api, OrtRunOptions* opts);
+OrtStatus* OrtApiRunOptionsUnsetTerminate(OrtApi* api, OrtRunOptions* opts);
+
+OrtStatus* OrtApiCreateIoBinding(OrtApi* api, OrtSession* session, OrtIoBinding** binding);
+void OrtApiReleaseIoBinding(OrtApi* api, OrtIoBinding* binding);
+OrtStatus* OrtApiBindInput(OrtApi* api, OrtIoBinding* binding, const char* name, const OrtValue* value);
+OrtStatus* OrtApiBindOutput(OrtApi* api, OrtIoBinding* binding, const char* name, const OrtValue* value);
+OrtStatus* OrtApiRunWithBinding(OrtApi* api, OrtSession* session, const OrtRunOptions* run_options, const OrtIoBinding* binding);
+
+OrtErrorCode OrtApiGetErrorCode(OrtApi* api, OrtStatus* status);
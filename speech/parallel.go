@@ -0,0 +1,190 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+const (
+	// The amount of audio processed before each chunk (and then discarded) to
+	// let the model state settle.
+	parallelWarmUpMs = 2000
+	// The minimum amount of audio assigned to a single chunk. Shorter inputs
+	// are not worth the overhead of running additional detectors.
+	parallelMinChunkMs = 30000
+)
+
+// parallelChunk is a range of windows, [start, end), processed by a worker
+// after warming up on the windows in [warmUpStart, start).
+type parallelChunk struct {
+	warmUpStart int
+	start       int
+	end         int
+}
+
+// parallelChunks splits numWindows windows into at most workers chunks of at
+// least minChunkWindows windows each.
+func parallelChunks(numWindows, workers, minChunkWindows, warmUpWindows int) []parallelChunk {
+	if numWindows <= 0 {
+		return nil
+	}
+
+	numChunks := workers
+	if maxChunks := numWindows / max(minChunkWindows, 1); numChunks > maxChunks {
+		numChunks = maxChunks
+	}
+	numChunks = max(numChunks, 1)
+
+	chunkWindows := (numWindows + numChunks - 1) / numChunks
+	chunks := make([]parallelChunk, 0, numChunks)
+	for start := 0; start < numWindows; start += chunkWindows {
+		chunks = append(chunks, parallelChunk{
+			warmUpStart: max(start-warmUpWindows, 0),
+			start:       start,
+			end:         min(start+chunkWindows, numWindows),
+		})
+	}
+
+	return chunks
+}
+
+// DetectParallel is like Detect but splits long audio into chunks which are
+// processed concurrently by up to workers additional detectors sharing the
+// same configuration, which are created on first use, kept for later calls
+// and destroyed along with sd. Each chunk is preceded by a warm-up overlap to let the
+// model state settle, after which segmentation runs over the merged speech
+// probabilities. Results match Detect within a small tolerance around chunk
+// boundaries.
+//
+// The model state of the detector itself is not used but its segmentation
+// state is, so it should be Reset before processing unrelated audio. Windows
+// processed by workers, including warm-up ones, are accounted for in Stats.
+func (sd *Detector) DetectParallel(pcm []float32, workers int) ([]Segment, error) {
+	if sd == nil {
+		return nil, ErrNilDetector
+	}
+
+	if workers <= 0 {
+		return nil, fmt.Errorf("invalid workers: should be a positive number")
+	}
+
+	if err := sd.acquire(); err != nil {
+		return nil, err
+	}
+	defer sd.release()
+
+	if sd.windowSize == 0 {
+		sd.windowSize = vad.WindowSizeForSampleRate(sd.cfg.SampleRate)
+	}
+	windowSize := sd.windowSize
+
+	if len(pcm) < windowSize {
+		return nil, ErrNotEnoughSamples
+	}
+
+	windowsPerSecond := sd.cfg.SampleRate / windowSize
+	minChunkWindows := parallelMinChunkMs * windowsPerSecond / 1000
+	warmUpWindows := parallelWarmUpMs * windowsPerSecond / 1000
+	chunks := parallelChunks(len(pcm)/windowSize, workers, minChunkWindows, warmUpWindows)
+	if len(chunks) == 1 {
+		return sd.detect(context.Background(), pcm)
+	}
+
+	slog.Debug("starting parallel speech detection",
+		slog.Int("samplesLen", len(pcm)), slog.Int("chunks", len(chunks)))
+
+	workerDetectors, err := sd.parallelWorkers(min(workers, len(chunks)))
+	if err != nil {
+		return nil, err
+	}
+
+	probs := make([]float32, len(pcm)/windowSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var detectErr error
+	setErr := func(err error) {
+		errOnce.Do(func() {
+			detectErr = err
+			cancel()
+		})
+	}
+
+	chunksCh := make(chan parallelChunk, len(chunks))
+	for _, chunk := range chunks {
+		chunksCh <- chunk
+	}
+	close(chunksCh)
+
+	for _, worker := range workerDetectors {
+		wg.Add(1)
+		go func(worker *Detector) {
+			defer wg.Done()
+
+			before := worker.Stats()
+			defer func() {
+				stats := worker.Stats()
+				sd.windows.Add(stats.Windows - before.Windows)
+				sd.skippedWindows.Add(stats.SkippedWindows - before.SkippedWindows)
+			}()
+
+			for chunk := range chunksCh {
+				if err := worker.Reset(); err != nil {
+					setErr(err)
+					return
+				}
+
+				for w := chunk.warmUpStart; w < chunk.end; w++ {
+					prob, err := worker.windowProbability(ctx, pcm[w*windowSize:(w+1)*windowSize])
+					if err != nil {
+						setErr(fmt.Errorf("infer failed: %w", err))
+						return
+					}
+					if w >= chunk.start {
+						probs[w] = prob
+					}
+				}
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+
+	if detectErr != nil {
+		return nil, detectErr
+	}
+
+	var segments []Segment
+	for w, prob := range probs {
+		event, err := sd.advance(prob, pcm[w*windowSize:(w+1)*windowSize])
+		if err != nil {
+			return nil, err
+		}
+		segments = appendEventSegments(segments, event)
+	}
+
+	slog.Debug("parallel speech detection done", slog.Int("segmentsLen", len(segments)))
+
+	return segments, nil
+}
+
+// parallelWorkers returns n detectors sharing the configuration of sd,
+// creating the missing ones.
+func (sd *Detector) parallelWorkers(n int) ([]*Detector, error) {
+	for len(sd.workers) < n {
+		worker, err := NewDetector(sd.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create detector: %w", err)
+		}
+		sd.workers = append(sd.workers, worker)
+	}
+
+	return sd.workers[:n], nil
+}
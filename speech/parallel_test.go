@@ -0,0 +1,187 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelChunks(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		require.Empty(t, parallelChunks(0, 4, 10, 2))
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		require.Equal(t, []parallelChunk{
+			{warmUpStart: 0, start: 0, end: 15},
+		}, parallelChunks(15, 4, 10, 2))
+	})
+
+	t.Run("limited by workers", func(t *testing.T) {
+		require.Equal(t, []parallelChunk{
+			{warmUpStart: 0, start: 0, end: 50},
+			{warmUpStart: 48, start: 50, end: 100},
+		}, parallelChunks(100, 2, 10, 2))
+	})
+
+	t.Run("limited by chunk size", func(t *testing.T) {
+		require.Equal(t, []parallelChunk{
+			{warmUpStart: 0, start: 0, end: 12},
+			{warmUpStart: 7, start: 12, end: 24},
+			{warmUpStart: 19, start: 24, end: 35},
+		}, parallelChunks(35, 8, 10, 5))
+	})
+}
+
+func TestDetectParallel(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sd)
+	defer func() {
+		workers := sd.workers
+		require.NoError(t, sd.Destroy())
+		for _, worker := range workers {
+			require.ErrorIs(t, worker.Reset(), ErrDestroyed)
+		}
+	}()
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	samples2 := readSamplesFromFile(t, "../testfiles/samples2.pcm")
+
+	// Build a couple of minutes of audio so that it gets split into chunks.
+	var pcm []float32
+	for len(pcm) < 120*cfg.SampleRate {
+		pcm = append(pcm, samples2...)
+		pcm = append(pcm, samples...)
+	}
+
+	t.Run("invalid workers", func(t *testing.T) {
+		_, err := sd.DetectParallel(pcm, 0)
+		require.EqualError(t, err, "invalid workers: should be a positive number")
+	})
+
+	expected, err := sd.Detect(pcm)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	require.NoError(t, sd.Reset())
+	segments, err := sd.DetectParallel(pcm, 4)
+	require.NoError(t, err)
+	require.Len(t, segments, len(expected))
+
+	for i := range expected {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+		require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.1)
+	}
+
+	t.Run("workers reuse", func(t *testing.T) {
+		workers := append([]*Detector(nil), sd.workers...)
+		require.Len(t, workers, 4)
+
+		require.NoError(t, sd.Reset())
+		again, err := sd.DetectParallel(pcm, 4)
+		require.NoError(t, err)
+		require.Equal(t, segments, again)
+		require.Equal(t, workers, sd.workers)
+	})
+
+	t.Run("short input", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+		segments, err := sd.DetectParallel(samples, 4)
+		require.NoError(t, err)
+		require.Equal(t, []Segment{
+			{
+				SpeechStartAt: 1.056,
+				SpeechEndAt:   1.632,
+			},
+			{
+				SpeechStartAt: 2.88,
+				SpeechEndAt:   3.232,
+			},
+			{
+				SpeechStartAt: 4.448,
+				SpeechEndAt:   0,
+			},
+		}, segments)
+	})
+}
+
+func TestDetectParallelConsistency(t *testing.T) {
+	tcs := []struct {
+		name string
+		cfg  DetectorConfig
+	}{
+		{
+			name: "adaptive threshold",
+			cfg: DetectorConfig{
+				ModelPath:  "../testfiles/silero_vad.onnx",
+				SampleRate: 16000,
+				Threshold:  0.5,
+				AdaptiveThreshold: AdaptiveThresholdConfig{
+					Enabled:      true,
+					AdaptationMs: 500,
+				},
+			},
+		},
+		{
+			name: "energy gate",
+			cfg: DetectorConfig{
+				ModelPath:  "../testfiles/silero_vad.onnx",
+				SampleRate: 16000,
+				Threshold:  0.5,
+				EnergyGate: EnergyGateConfig{Enabled: true},
+			},
+		},
+	}
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	samples2 := readSamplesFromFile(t, "../testfiles/samples2.pcm")
+
+	// Long silences let the adaptation kick in before speech resumes.
+	var pcm []float32
+	for len(pcm) < 120*16000 {
+		pcm = append(pcm, make([]float32, 5*16000)...)
+		pcm = append(pcm, samples2...)
+		pcm = append(pcm, samples...)
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			sd, err := NewDetector(tc.cfg)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, sd.Destroy())
+			}()
+
+			expected, err := sd.Detect(pcm)
+			require.NoError(t, err)
+			require.NotEmpty(t, expected)
+			threshold := sd.EffectiveThreshold()
+			stats := sd.Stats()
+
+			require.NoError(t, sd.Reset())
+			segments, err := sd.DetectParallel(pcm, 4)
+			require.NoError(t, err)
+			require.Len(t, segments, len(expected))
+			for i := range expected {
+				require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+				require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.1)
+			}
+			require.InDelta(t, threshold, sd.EffectiveThreshold(), 0.05)
+
+			// Workers also process warm-up windows.
+			parallelStats := sd.Stats()
+			require.GreaterOrEqual(t, parallelStats.Windows, 2*stats.Windows)
+			if tc.cfg.EnergyGate.Enabled {
+				require.NotZero(t, stats.SkippedWindows)
+				require.Greater(t, parallelStats.SkippedWindows, stats.SkippedWindows)
+			}
+		})
+	}
+}
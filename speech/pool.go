@@ -0,0 +1,227 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type PoolConfig struct {
+	// The configuration used to create the pooled detectors.
+	DetectorConfig DetectorConfig
+	// The maximum number of detectors, idle or in use, the pool can hold.
+	MaxSize int
+	// The duration after which an idle detector gets destroyed. Zero disables eviction.
+	IdleTimeout time.Duration
+}
+
+func (c PoolConfig) IsValid() error {
+	if err := c.DetectorConfig.IsValid(); err != nil {
+		return fmt.Errorf("invalid DetectorConfig: %w", err)
+	}
+
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("invalid MaxSize: should be a positive number")
+	}
+
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("invalid IdleTimeout: should be a positive duration")
+	}
+
+	return nil
+}
+
+type idleDetector struct {
+	sd       *Detector
+	idleFrom time.Time
+}
+
+// Pool manages a bounded set of detectors sharing the same configuration so
+// they can be reused across goroutines. A Detector obtained through Get is
+// owned by the caller until it's returned through Put. Pool is safe for
+// concurrent use.
+type Pool struct {
+	cfg PoolConfig
+
+	// tokens limits the number of detectors in existence, each held token
+	// accounts for a detector that is either idle or in use.
+	tokens chan struct{}
+
+	mut    sync.Mutex
+	idle   []idleDetector
+	inUse  map[*Detector]struct{}
+	closed bool
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func NewPool(cfg PoolConfig) (*Pool, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	p := &Pool{
+		cfg:    cfg,
+		tokens: make(chan struct{}, cfg.MaxSize),
+		inUse:  map[*Detector]struct{}{},
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+
+	if cfg.IdleTimeout > 0 {
+		go p.evictLoop()
+	} else {
+		close(p.doneCh)
+	}
+
+	return p, nil
+}
+
+// Config returns the configuration the pool was created with.
+func (p *Pool) Config() PoolConfig {
+	return p.cfg
+}
+
+// Get returns a detector from the pool, creating one if none is idle. It
+// blocks until a detector is available or ctx is done.
+func (p *Pool) Get(ctx context.Context) (*Detector, error) {
+	p.mut.Lock()
+	closed := p.closed
+	p.mut.Unlock()
+	if closed {
+		return nil, ErrPoolClosed
+	}
+
+	select {
+	case p.tokens <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mut.Lock()
+	if p.closed {
+		p.mut.Unlock()
+		<-p.tokens
+		return nil, ErrPoolClosed
+	}
+	// We take the most recently used detector so that the others can age and
+	// get evicted when the load decreases.
+	if n := len(p.idle); n > 0 {
+		sd := p.idle[n-1].sd
+		p.idle = p.idle[:n-1]
+		p.inUse[sd] = struct{}{}
+		p.mut.Unlock()
+		return sd, nil
+	}
+	p.mut.Unlock()
+
+	sd, err := NewDetector(p.cfg.DetectorConfig)
+	if err != nil {
+		<-p.tokens
+		return nil, fmt.Errorf("failed to create detector: %w", err)
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.closed {
+		<-p.tokens
+		return nil, errors.Join(ErrPoolClosed, sd.Destroy())
+	}
+	p.inUse[sd] = struct{}{}
+
+	return sd, nil
+}
+
+// Put resets the given detector and returns it to the pool. If the pool
+// is closed or the reset fails the detector gets destroyed instead.
+func (p *Pool) Put(sd *Detector) error {
+	if sd == nil {
+		return ErrNilDetector
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if _, ok := p.inUse[sd]; !ok {
+		return fmt.Errorf("detector does not belong to the pool")
+	}
+	delete(p.inUse, sd)
+	defer func() { <-p.tokens }()
+
+	if p.closed {
+		return sd.Destroy()
+	}
+
+	if err := sd.Reset(); err != nil {
+		return errors.Join(fmt.Errorf("failed to reset detector: %w", err), sd.Destroy())
+	}
+
+	p.idle = append(p.idle, idleDetector{sd: sd, idleFrom: time.Now()})
+
+	return nil
+}
+
+// Close destroys all idle detectors and prevents further use of the pool.
+// Detectors in use get destroyed as they are returned.
+func (p *Pool) Close() error {
+	p.mut.Lock()
+	if p.closed {
+		p.mut.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mut.Unlock()
+
+	close(p.stopCh)
+	<-p.doneCh
+
+	var errs []error
+	for _, d := range idle {
+		errs = append(errs, d.sd.Destroy())
+		<-p.tokens
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Pool) evictLoop() {
+	defer close(p.doneCh)
+
+	ticker := time.NewTicker(max(p.cfg.IdleTimeout/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.evictIdle(time.Now())
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+func (p *Pool) evictIdle(now time.Time) {
+	p.mut.Lock()
+	var evicted []*Detector
+	// Idle detectors are ordered from least to most recently used.
+	n := 0
+	for n < len(p.idle) && now.Sub(p.idle[n].idleFrom) >= p.cfg.IdleTimeout {
+		evicted = append(evicted, p.idle[n].sd)
+		n++
+	}
+	p.idle = append(p.idle[:0], p.idle[n:]...)
+	p.mut.Unlock()
+
+	for _, sd := range evicted {
+		if err := sd.Destroy(); err != nil {
+			slog.Error("failed to destroy detector", slog.String("err", err.Error()))
+		}
+		<-p.tokens
+	}
+}
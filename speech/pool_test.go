@@ -0,0 +1,251 @@
+package speech
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfigIsValid(t *testing.T) {
+	detectorCfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	tcs := []struct {
+		name string
+		cfg  PoolConfig
+		err  string
+	}{
+		{
+			name: "invalid DetectorConfig",
+			cfg:  PoolConfig{},
+			err:  "invalid DetectorConfig: invalid ModelPath: should not be empty",
+		},
+		{
+			name: "invalid MaxSize",
+			cfg: PoolConfig{
+				DetectorConfig: detectorCfg,
+			},
+			err: "invalid MaxSize: should be a positive number",
+		},
+		{
+			name: "invalid IdleTimeout",
+			cfg: PoolConfig{
+				DetectorConfig: detectorCfg,
+				MaxSize:        1,
+				IdleTimeout:    -time.Second,
+			},
+			err: "invalid IdleTimeout: should be a positive duration",
+		},
+		{
+			name: "valid",
+			cfg: PoolConfig{
+				DetectorConfig: detectorCfg,
+				MaxSize:        1,
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.IsValid()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPool(t *testing.T) {
+	cfg := PoolConfig{
+		DetectorConfig: DetectorConfig{
+			ModelPath:  "../testfiles/silero_vad.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		},
+		MaxSize: 2,
+	}
+
+	t.Run("get put", func(t *testing.T) {
+		p, err := NewPool(cfg)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, p.Close())
+		}()
+		require.Equal(t, cfg, p.Config())
+
+		sd, err := p.Get(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, sd)
+
+		require.NoError(t, p.Put(sd))
+		require.EqualError(t, p.Put(sd), "detector does not belong to the pool")
+
+		// The idle detector should be reused.
+		sd2, err := p.Get(context.Background())
+		require.NoError(t, err)
+		require.Same(t, sd, sd2)
+		require.NoError(t, p.Put(sd2))
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		p, err := NewPool(cfg)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, p.Close())
+		}()
+
+		sd1, err := p.Get(context.Background())
+		require.NoError(t, err)
+		sd2, err := p.Get(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = p.Get(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.NoError(t, p.Put(sd1))
+		sd3, err := p.Get(context.Background())
+		require.NoError(t, err)
+		require.Same(t, sd1, sd3)
+
+		require.NoError(t, p.Put(sd2))
+		require.NoError(t, p.Put(sd3))
+	})
+
+	t.Run("reset on put", func(t *testing.T) {
+		p, err := NewPool(cfg)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, p.Close())
+		}()
+
+		sd, err := p.Get(context.Background())
+		require.NoError(t, err)
+
+		samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+		_, err = sd.DetectStream(samples[:1000])
+		require.NoError(t, err)
+		require.NotZero(t, sd.seg.Samples())
+		require.NotEmpty(t, sd.streamBuf)
+
+		require.NoError(t, p.Put(sd))
+		require.Zero(t, sd.seg.Samples())
+		require.Empty(t, sd.streamBuf)
+	})
+
+	t.Run("idle eviction", func(t *testing.T) {
+		cfg := cfg
+		cfg.IdleTimeout = 20 * time.Millisecond
+		p, err := NewPool(cfg)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, p.Close())
+		}()
+
+		sd, err := p.Get(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, p.Put(sd))
+
+		require.Eventually(t, func() bool {
+			p.mut.Lock()
+			defer p.mut.Unlock()
+			return len(p.idle) == 0
+		}, time.Second, 10*time.Millisecond)
+		require.Empty(t, p.tokens)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		p, err := NewPool(cfg)
+		require.NoError(t, err)
+
+		sd1, err := p.Get(context.Background())
+		require.NoError(t, err)
+		sd2, err := p.Get(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, p.Put(sd1))
+
+		require.NoError(t, p.Close())
+		require.NoError(t, p.Close())
+
+		_, err = p.Get(context.Background())
+		require.ErrorIs(t, err, ErrPoolClosed)
+
+		// Detectors returned after closing get destroyed.
+		require.NoError(t, p.Put(sd2))
+		require.Empty(t, p.tokens)
+	})
+}
+
+func TestPoolConcurrency(t *testing.T) {
+	p, err := NewPool(PoolConfig{
+		DetectorConfig: DetectorConfig{
+			ModelPath:  "../testfiles/silero_vad.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		},
+		MaxSize:     4,
+		IdleTimeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, p.Close())
+	}()
+
+	samples := readSamplesFromFile(t, "../testfiles/samples.pcm")
+	expected := []Segment{
+		{
+			SpeechStartAt: 1.056,
+			SpeechEndAt:   1.632,
+		},
+		{
+			SpeechStartAt: 2.88,
+			SpeechEndAt:   3.232,
+		},
+		{
+			SpeechStartAt: 4.448,
+			SpeechEndAt:   0,
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 2; j++ {
+				sd, err := p.Get(context.Background())
+				if !assertNoError(t, err) {
+					return
+				}
+
+				segments, err := sd.Detect(samples)
+				assertNoError(t, err)
+				if len(segments) != len(expected) || segments[0] != expected[0] {
+					t.Errorf("unexpected segments: %v", segments)
+				}
+
+				assertNoError(t, p.Put(sd))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func assertNoError(t *testing.T, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
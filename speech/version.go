@@ -0,0 +1,172 @@
+package speech
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ModelVersion identifies a Silero VAD model release.
+type ModelVersion int
+
+const (
+	// ModelVersionAuto detects the model version from its metadata and signature.
+	ModelVersionAuto ModelVersion = iota
+	// ModelVersionV4 is the Silero VAD v4 model, using separate h and c LSTM state tensors.
+	ModelVersionV4
+	// ModelVersionV5 is the Silero VAD v5 model, using a single state tensor and audio context.
+	ModelVersionV5
+	// ModelVersionV6 is the Silero VAD v6 model. It shares the v5 interface.
+	ModelVersionV6
+)
+
+func (v ModelVersion) String() string {
+	switch v {
+	case ModelVersionAuto:
+		return "auto"
+	case ModelVersionV4:
+		return "v4"
+	case ModelVersionV5:
+		return "v5"
+	case ModelVersionV6:
+		return "v6"
+	default:
+		return fmt.Sprintf("ModelVersion(%d)", int(v))
+	}
+}
+
+// ParseModelVersion parses a model version from its string representation
+// (e.g. "auto", "v5").
+func ParseModelVersion(s string) (ModelVersion, error) {
+	for v := ModelVersionAuto; v <= ModelVersionV6; v++ {
+		if strings.EqualFold(s, v.String()) {
+			return v, nil
+		}
+	}
+	return ModelVersionAuto, fmt.Errorf("invalid model version %q", s)
+}
+
+// stateTensor describes a recurrent state tensor which is fed back as input
+// on the next inference.
+type stateTensor struct {
+	input  string
+	output string
+	dims   [3]int64
+}
+
+func (st stateTensor) len() int {
+	return int(st.dims[0] * st.dims[1] * st.dims[2])
+}
+
+// modelVariant holds the interface details that differ between model versions.
+type modelVariant struct {
+	version   ModelVersion
+	signature modelSignature
+	// The state tensors, stored back to back in the detector's state buffer.
+	states []stateTensor
+	// The number of trailing samples from the previous window prepended to the
+	// current one.
+	contextLen int
+}
+
+func (mv modelVariant) inputNames() []string {
+	names := []string{"input", "sr"}
+	for _, st := range mv.states {
+		names = append(names, st.input)
+	}
+	return names
+}
+
+func (mv modelVariant) outputNames() []string {
+	names := []string{"output"}
+	for _, st := range mv.states {
+		names = append(names, st.output)
+	}
+	return names
+}
+
+var sileroV4Variant = modelVariant{
+	version: ModelVersionV4,
+	signature: modelSignature{
+		inputs: []tensorSpec{
+			{name: "input", elementType: "float32", shape: []int64{-1, -1}},
+			{name: "sr", elementType: "int64"},
+			{name: "h", elementType: "float32", shape: []int64{2, -1, 64}},
+			{name: "c", elementType: "float32", shape: []int64{2, -1, 64}},
+		},
+		outputs: []tensorSpec{
+			{name: "output", elementType: "float32", shape: []int64{-1, 1}},
+			{name: "hn", elementType: "float32", shape: []int64{2, -1, 64}},
+			{name: "cn", elementType: "float32", shape: []int64{2, -1, 64}},
+		},
+	},
+	states: []stateTensor{
+		{input: "h", output: "hn", dims: [3]int64{2, 1, 64}},
+		{input: "c", output: "cn", dims: [3]int64{2, 1, 64}},
+	},
+}
+
+var sileroV5Variant = modelVariant{
+	version:   ModelVersionV5,
+	signature: sileroV5Signature,
+	states: []stateTensor{
+		{input: "state", output: "stateN", dims: [3]int64{2, 1, 128}},
+	},
+	contextLen: contextLen,
+}
+
+var sileroV6Variant = modelVariant{
+	version:    ModelVersionV6,
+	signature:  sileroV5Variant.signature,
+	states:     sileroV5Variant.states,
+	contextLen: sileroV5Variant.contextLen,
+}
+
+func variantForVersion(version ModelVersion) (modelVariant, bool) {
+	switch version {
+	case ModelVersionV4:
+		return sileroV4Variant, true
+	case ModelVersionV5:
+		return sileroV5Variant, true
+	case ModelVersionV6:
+		return sileroV6Variant, true
+	default:
+		return modelVariant{}, false
+	}
+}
+
+// metadataVersion returns the major model version declared in the model
+// metadata, if any.
+func metadataVersion(info ModelInfo) int64 {
+	for _, key := range []string{"version", "model_version"} {
+		value, ok := info.CustomMetadata[key]
+		if !ok {
+			continue
+		}
+		value = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "v")
+		major, _, _ := strings.Cut(value, ".")
+		if v, err := strconv.ParseInt(major, 10, 64); err == nil {
+			return v
+		}
+	}
+	return info.Version
+}
+
+// detectModelVariant picks the model variant matching the given model info.
+// The v5 and v6 releases share the same signature so we rely on the model
+// metadata to tell them apart, defaulting to v5.
+func detectModelVariant(info ModelInfo) (modelVariant, error) {
+	if err := sileroV4Variant.signature.validate(info); err == nil {
+		return sileroV4Variant, nil
+	}
+
+	if err := sileroV5Variant.signature.validate(info); err != nil {
+		return modelVariant{}, fmt.Errorf("unrecognized model signature: %w", err)
+	}
+
+	if metadataVersion(info) >= 6 {
+		return sileroV6Variant, nil
+	}
+
+	return sileroV5Variant, nil
+}
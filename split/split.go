@@ -0,0 +1,207 @@
+package split
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+type Config struct {
+	// The directory to write the segment files and the manifest to. It gets
+	// created if missing.
+	Dir string
+	// The prefix of the segment file names, followed by the 1-based segment
+	// number (e.g. segment_0001.wav). Defaults to "segment".
+	Prefix string
+	// The padding added to each side of the segments. It's clamped so that
+	// it never extends past the input bounds nor halfway to adjacent segments.
+	PaddingMs int
+	// The name of the JSON lines manifest file. Defaults to "manifest.jsonl".
+	ManifestName string
+}
+
+func (c Config) IsValid() error {
+	if c.Dir == "" {
+		return fmt.Errorf("invalid Dir: should not be empty")
+	}
+
+	if c.PaddingMs < 0 {
+		return fmt.Errorf("invalid PaddingMs: should be a positive number")
+	}
+
+	if filepath.Base(c.Prefix) != c.Prefix && c.Prefix != "" {
+		return fmt.Errorf("invalid Prefix: should not contain path separators")
+	}
+
+	if filepath.Base(c.ManifestName) != c.ManifestName && c.ManifestName != "" {
+		return fmt.Errorf("invalid ManifestName: should not contain path separators")
+	}
+
+	return nil
+}
+
+func (c Config) prefix() string {
+	if c.Prefix == "" {
+		return "segment"
+	}
+	return c.Prefix
+}
+
+func (c Config) manifestName() string {
+	if c.ManifestName == "" {
+		return "manifest.jsonl"
+	}
+	return c.ManifestName
+}
+
+// Entry describes a segment file, as written to the manifest.
+type Entry struct {
+	// The file name, relative to the output directory.
+	File string `json:"file"`
+	// The time in seconds, relative to the input, at which the file begins.
+	Start float64 `json:"start"`
+	// The time in seconds, relative to the input, at which the file ends.
+	End float64 `json:"end"`
+	// The duration of the file in seconds.
+	Duration float64 `json:"duration"`
+	// Whether speech was still ongoing at the end of the input.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Split runs speech detection over pcm, sampled at the detector rate, and
+// writes each speech segment to a numbered WAV file along with a manifest.
+func Split(sd *speech.Detector, pcm []float32, cfg Config) ([]Entry, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	segments, err := sd.Detect(pcm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect speech: %w", err)
+	}
+
+	return WriteSegments(segments, pcm, sd.Config().SampleRate, cfg)
+}
+
+// WriteSegments writes each of the segments, as returned by Detect, to a
+// numbered WAV file along with a manifest. Segments are cut from pcm which
+// can be sampled at any rate. A trailing segment still open at the end of the
+// input is closed there and marked as truncated.
+func WriteSegments(segments []vad.Segment, pcm []float32, sampleRate int, cfg Config) ([]Entry, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: should be a positive number")
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	entries := Entries(segments, float64(len(pcm))/float64(sampleRate), cfg)
+	for _, entry := range entries {
+		start := min(int(math.Round(entry.Start*float64(sampleRate))), len(pcm))
+		end := min(max(int(math.Round(entry.End*float64(sampleRate))), start), len(pcm))
+		if err := writeWAVFile(filepath.Join(cfg.Dir, entry.File), pcm[start:end], sampleRate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeManifestFile(filepath.Join(cfg.Dir, cfg.manifestName()), entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Entries computes the manifest entries for the given segments over an
+// input of the given duration in seconds, without writing anything.
+func Entries(segments []vad.Segment, duration float64, cfg Config) []Entry {
+	padding := float64(cfg.PaddingMs) / 1000
+
+	entries := make([]Entry, 0, len(segments))
+	for i, seg := range segments {
+		end := seg.SpeechEndAt
+		truncated := end == 0
+		if truncated {
+			end = duration
+		}
+
+		lower, upper := 0.0, duration
+		if i > 0 {
+			lower = max(lower, (segments[i-1].SpeechEndAt+seg.SpeechStartAt)/2)
+		}
+		if i < len(segments)-1 {
+			upper = min(upper, (end+segments[i+1].SpeechStartAt)/2)
+		}
+
+		start := max(seg.SpeechStartAt-padding, min(lower, seg.SpeechStartAt))
+		end = min(end+padding, max(upper, end))
+
+		// Rounding to get rid of floating point noise in the manifest.
+		start, end = roundTime(start), roundTime(end)
+
+		entries = append(entries, Entry{
+			File:      fmt.Sprintf("%s_%04d.wav", cfg.prefix(), i+1),
+			Start:     start,
+			End:       end,
+			Duration:  roundTime(end - start),
+			Truncated: truncated,
+		})
+	}
+
+	return entries
+}
+
+func roundTime(t float64) float64 {
+	return math.Round(t*1e6) / 1e6
+}
+
+// WriteManifest writes the entries to w as JSON lines.
+func WriteManifest(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write manifest entry: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeManifestFile(path string, entries []Entry) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	return WriteManifest(file, entries)
+}
+
+func writeWAVFile(path string, samples []float32, sampleRate int) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create segment file: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	if err := audio.WriteWAV(file, samples, sampleRate); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
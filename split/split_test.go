@@ -0,0 +1,194 @@
+package split
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	tcs := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "missing Dir",
+			cfg:  Config{},
+			err:  "invalid Dir: should not be empty",
+		},
+		{
+			name: "invalid PaddingMs",
+			cfg:  Config{Dir: "out", PaddingMs: -1},
+			err:  "invalid PaddingMs: should be a positive number",
+		},
+		{
+			name: "invalid Prefix",
+			cfg:  Config{Dir: "out", Prefix: "a/b"},
+			err:  "invalid Prefix: should not contain path separators",
+		},
+		{
+			name: "invalid ManifestName",
+			cfg:  Config{Dir: "out", ManifestName: "../manifest.jsonl"},
+			err:  "invalid ManifestName: should not contain path separators",
+		},
+		{
+			name: "valid",
+			cfg:  Config{Dir: "out", Prefix: "utt", PaddingMs: 100},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.IsValid()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	segments := []speech.Segment{
+		{SpeechStartAt: 0.05, SpeechEndAt: 1},
+		{SpeechStartAt: 1.2, SpeechEndAt: 2},
+		{SpeechStartAt: 3, SpeechEndAt: 0},
+	}
+
+	t.Run("no padding", func(t *testing.T) {
+		require.Equal(t, []Entry{
+			{File: "segment_0001.wav", Start: 0.05, End: 1, Duration: 0.95},
+			{File: "segment_0002.wav", Start: 1.2, End: 2, Duration: 0.8},
+			{File: "segment_0003.wav", Start: 3, End: 3.5, Duration: 0.5, Truncated: true},
+		}, Entries(segments, 3.5, Config{Dir: "out"}))
+	})
+
+	t.Run("padding", func(t *testing.T) {
+		require.Equal(t, []Entry{
+			// Clamped at the input start and halfway to the next segment.
+			{File: "utt_0001.wav", Start: 0, End: 1.1, Duration: 1.1},
+			{File: "utt_0002.wav", Start: 1.1, End: 2.25, Duration: 1.15},
+			// Clamped at the input end.
+			{File: "utt_0003.wav", Start: 2.75, End: 3.5, Duration: 0.75, Truncated: true},
+		}, Entries(segments, 3.5, Config{Dir: "out", Prefix: "utt", PaddingMs: 250}))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.Empty(t, Entries(nil, 3.5, Config{Dir: "out"}))
+	})
+}
+
+func readManifest(t *testing.T, path string) []Entry {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+
+	return entries
+}
+
+func readWAVFile(t *testing.T, path string) ([]float32, audio.WAVFormat) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	samples, format, err := audio.ReadWAV(file)
+	require.NoError(t, err)
+
+	return samples, format
+}
+
+func TestWriteSegments(t *testing.T) {
+	sampleRate := 8000
+	pcm := make([]float32, 4*sampleRate)
+	for i := range pcm {
+		pcm[i] = float32(i%100) / 200
+	}
+
+	segments := []speech.Segment{
+		{SpeechStartAt: 0.5, SpeechEndAt: 1.5},
+		{SpeechStartAt: 3, SpeechEndAt: 0},
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	entries, err := WriteSegments(segments, pcm, sampleRate, Config{Dir: dir, PaddingMs: 100})
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{File: "segment_0001.wav", Start: 0.4, End: 1.6, Duration: 1.2},
+		{File: "segment_0002.wav", Start: 2.9, End: 4, Duration: 1.1, Truncated: true},
+	}, entries)
+
+	require.Equal(t, entries, readManifest(t, filepath.Join(dir, "manifest.jsonl")))
+
+	for _, entry := range entries {
+		samples, format := readWAVFile(t, filepath.Join(dir, entry.File))
+		require.Equal(t, sampleRate, format.SampleRate)
+		require.Len(t, samples, int(math.Round(entry.Duration*float64(sampleRate))))
+
+		offset := int(math.Round(entry.Start * float64(sampleRate)))
+		require.InDelta(t, pcm[offset+10], samples[10], 1.0/32768)
+	}
+
+	t.Run("invalid sample rate", func(t *testing.T) {
+		_, err := WriteSegments(segments, pcm, 0, Config{Dir: dir})
+		require.EqualError(t, err, "invalid sample rate: should be a positive number")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := WriteSegments(segments, pcm, sampleRate, Config{})
+		require.EqualError(t, err, "invalid config: invalid Dir: should not be empty")
+	})
+}
+
+func TestSplit(t *testing.T) {
+	sd, err := speech.NewDetector(speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	data, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	pcm := make([]float32, 0, len(data)/4)
+	for i := 0; i+4 <= len(data); i += 4 {
+		pcm = append(pcm, math.Float32frombits(binary.LittleEndian.Uint32(data[i:])))
+	}
+
+	dir := t.TempDir()
+	entries, err := Split(sd, pcm, Config{Dir: dir, ManifestName: "utterances.jsonl"})
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{File: "segment_0001.wav", Start: 1.056, End: 1.632, Duration: 0.576},
+		{File: "segment_0002.wav", Start: 2.88, End: 3.232, Duration: 0.352},
+		{File: "segment_0003.wav", Start: 4.448, End: roundTime(float64(len(pcm)) / 16000),
+			Duration: roundTime(float64(len(pcm))/16000 - 4.448), Truncated: true},
+	}, entries)
+	require.Equal(t, entries, readManifest(t, filepath.Join(dir, "utterances.jsonl")))
+}
@@ -0,0 +1,231 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+type Config struct {
+	// The sample rate of the input audio, which gets resampled to the detector
+	// one as needed. Defaults to the detector sample rate.
+	SampleRate int
+	// The encoding of the audio passed to Write. Defaults to EncodingFloat32LE.
+	Encoding audio.Encoding
+	// Whether to emit the speech probability of each window.
+	Probabilities bool
+}
+
+func (c Config) IsValid() error {
+	if c.SampleRate < 0 {
+		return fmt.Errorf("invalid SampleRate: should be a positive number")
+	}
+
+	if c.Encoding != 0 && c.Encoding.SampleSize() == 0 {
+		return fmt.Errorf("invalid Encoding: unknown encoding")
+	}
+
+	return nil
+}
+
+type EventType int
+
+const (
+	// EventSpeechStart is emitted when speech starts.
+	EventSpeechStart EventType = iota + 1
+	// EventSpeechEnd is emitted when speech ends.
+	EventSpeechEnd
+	// EventProbability is emitted for each window when Config.Probabilities is set.
+	EventProbability
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSpeechStart:
+		return "start"
+	case EventSpeechEnd:
+		return "end"
+	case EventProbability:
+		return "probability"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a speech update. Times are in seconds from the beginning of the
+// stream.
+type Event struct {
+	Type EventType
+	// The start of the speech segment, for speech events.
+	Start float64
+	// The end of the speech segment, for EventSpeechEnd.
+	End float64
+	// The end of the window, for EventProbability.
+	Time float64
+	// The speech probability of the window, for EventProbability.
+	Probability float32
+}
+
+// Session runs streaming detection over audio of any sample rate and encoding,
+// turning the segment updates of Detector.DetectStream into events. Windows are
+// fed one at a time so that the probability of each can be reported.
+//
+// A Session doesn't own its detector, which should be freshly created or Reset
+// and not be used by anything else until the session is done.
+type Session struct {
+	sd        *speech.Detector
+	cfg       Config
+	resampler *audio.Resampler
+	// The detector sample rate.
+	sampleRate int
+
+	// Bytes not yet forming a full sample.
+	pending []byte
+	// Samples at the detector rate not yet forming a full window.
+	window []float32
+	// The number of samples at the detector rate fed to the detector.
+	fed int
+	// Whether a speech segment is open, and since when.
+	speaking  bool
+	startedAt float64
+}
+
+func NewSession(sd *speech.Detector, cfg Config) (*Session, error) {
+	if sd == nil {
+		return nil, speech.ErrNilDetector
+	}
+
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	sampleRate := sd.Config().SampleRate
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = sampleRate
+	}
+	if cfg.Encoding == 0 {
+		cfg.Encoding = audio.EncodingFloat32LE
+	}
+
+	resampler, err := audio.NewResampler(cfg.SampleRate, sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		sd:         sd,
+		cfg:        cfg,
+		resampler:  resampler,
+		sampleRate: sampleRate,
+		window:     make([]float32, 0, sd.WindowSize()),
+	}, nil
+}
+
+// Config returns the session configuration, with defaults applied.
+func (s *Session) Config() Config {
+	return s.cfg
+}
+
+// WindowDuration returns the duration in seconds of each window.
+func (s *Session) WindowDuration() float64 {
+	return float64(cap(s.window)) / float64(s.sampleRate)
+}
+
+// Duration returns the duration in seconds of the audio received so far,
+// excluding the few milliseconds held back by resampling.
+func (s *Session) Duration() float64 {
+	return float64(s.fed+len(s.window)) / float64(s.sampleRate)
+}
+
+// Speaking returns whether a speech segment is open.
+func (s *Session) Speaking() bool {
+	return s.speaking
+}
+
+// Write decodes data according to the configured encoding and processes the
+// resulting samples. Data doesn't need to be aligned on samples, trailing bytes
+// are kept until the next call.
+func (s *Session) Write(ctx context.Context, data []byte) ([]Event, error) {
+	sampleSize := s.cfg.Encoding.SampleSize()
+	if len(s.pending) > 0 {
+		data = append(s.pending, data...)
+	}
+	n := len(data) - len(data)%sampleSize
+	samples := audio.DecodeSamples(nil, data[:n], s.cfg.Encoding)
+	s.pending = append(s.pending[:0], data[n:]...)
+
+	return s.WriteSamples(ctx, samples)
+}
+
+// WriteSamples processes samples at the configured sample rate and returns
+// the resulting events.
+func (s *Session) WriteSamples(ctx context.Context, samples []float32) ([]Event, error) {
+	return s.process(ctx, nil, s.resampler.Process(nil, samples))
+}
+
+// Flush processes the audio held back by resampling and ends any open segment
+// at the end of the stream. Trailing samples not filling a window are ignored,
+// as with Detect. The session shouldn't be written to afterwards.
+func (s *Session) Flush(ctx context.Context) ([]Event, error) {
+	events, err := s.process(ctx, nil, s.resampler.Flush(nil))
+	if err != nil {
+		return events, err
+	}
+
+	if s.speaking {
+		s.speaking = false
+		events = append(events, Event{Type: EventSpeechEnd, Start: s.startedAt, End: s.Duration()})
+	}
+
+	return events, nil
+}
+
+func (s *Session) process(ctx context.Context, events []Event, samples []float32) ([]Event, error) {
+	for len(samples) > 0 {
+		n := min(cap(s.window)-len(s.window), len(samples))
+		s.window = append(s.window, samples[:n]...)
+		samples = samples[n:]
+		if len(s.window) < cap(s.window) {
+			break
+		}
+
+		var err error
+		if events, err = s.processWindow(ctx, events); err != nil {
+			return events, err
+		}
+	}
+
+	return events, nil
+}
+
+func (s *Session) processWindow(ctx context.Context, events []Event) ([]Event, error) {
+	segments, err := s.sd.DetectStreamContext(ctx, s.window)
+	if err != nil {
+		return events, fmt.Errorf("failed to detect speech: %w", err)
+	}
+	s.fed += len(s.window)
+	s.window = s.window[:0]
+
+	if s.cfg.Probabilities {
+		events = append(events, Event{
+			Type:        EventProbability,
+			Time:        float64(s.fed) / float64(s.sampleRate),
+			Probability: s.sd.LastProbability(),
+		})
+	}
+
+	for _, seg := range segments {
+		if seg.SpeechEndAt == 0 {
+			s.speaking = true
+			s.startedAt = seg.SpeechStartAt
+			events = append(events, Event{Type: EventSpeechStart, Start: seg.SpeechStartAt})
+			continue
+		}
+		s.speaking = false
+		events = append(events, Event{Type: EventSpeechEnd, Start: seg.SpeechStartAt, End: seg.SpeechEndAt})
+	}
+
+	return events, nil
+}
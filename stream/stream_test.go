@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{SampleRate: 48000, Encoding: audio.EncodingS16LE, Probabilities: true}.IsValid())
+	require.EqualError(t, Config{SampleRate: -1}.IsValid(), "invalid SampleRate: should be a positive number")
+	require.EqualError(t, Config{Encoding: audio.Encoding(10)}.IsValid(), "invalid Encoding: unknown encoding")
+}
+
+func TestEventType(t *testing.T) {
+	require.Equal(t, "start", EventSpeechStart.String())
+	require.Equal(t, "end", EventSpeechEnd.String())
+	require.Equal(t, "probability", EventProbability.String())
+	require.Equal(t, "EventType(0)", EventType(0).String())
+}
+
+func TestNewSession(t *testing.T) {
+	_, err := NewSession(nil, Config{})
+	require.ErrorIs(t, err, speech.ErrNilDetector)
+}
+
+func TestSession(t *testing.T) {
+	cfg := speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+
+	sd, err := speech.NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+	duration := float64(len(samples)) / 16000
+
+	probs, err := sd.Probabilities(samples)
+	require.NoError(t, err)
+	require.NoError(t, sd.Reset())
+	expected, err := sd.Detect(samples)
+	require.NoError(t, err)
+	expected[len(expected)-1].SpeechEndAt = duration
+
+	// toSegments checks start events are followed by matching end events and
+	// returns the resulting segments along with the probabilities.
+	toSegments := func(t *testing.T, events []Event) ([]speech.Segment, []float32) {
+		t.Helper()
+		var segments []speech.Segment
+		var probs []float32
+		started := false
+		for _, ev := range events {
+			switch ev.Type {
+			case EventSpeechStart:
+				require.False(t, started)
+				started = true
+			case EventSpeechEnd:
+				require.True(t, started)
+				started = false
+				segments = append(segments, speech.Segment{SpeechStartAt: ev.Start, SpeechEndAt: ev.End})
+			case EventProbability:
+				require.InDelta(t, float64(len(probs)+1)*0.032, ev.Time, 1e-9)
+				probs = append(probs, ev.Probability)
+			}
+		}
+		return segments, probs
+	}
+
+	t.Run("f32le", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+		s, err := NewSession(sd, Config{Probabilities: true})
+		require.NoError(t, err)
+		require.Equal(t, Config{SampleRate: 16000, Encoding: audio.EncodingFloat32LE, Probabilities: true}, s.Config())
+		require.Equal(t, 0.032, s.WindowDuration())
+
+		// Chunks not aligned on samples nor windows.
+		var events []Event
+		for i := 0; i < len(pcm); i += 1001 {
+			evs, err := s.Write(context.Background(), pcm[i:min(i+1001, len(pcm))])
+			require.NoError(t, err)
+			events = append(events, evs...)
+		}
+		require.True(t, s.Speaking())
+		evs, err := s.Flush(context.Background())
+		require.NoError(t, err)
+		events = append(events, evs...)
+		require.False(t, s.Speaking())
+		require.Equal(t, duration, s.Duration())
+
+		segments, streamProbs := toSegments(t, events)
+		require.Equal(t, expected, segments)
+		require.Equal(t, probs, streamProbs)
+	})
+
+	t.Run("s16le at 48kHz", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+		s, err := NewSession(sd, Config{SampleRate: 48000, Encoding: audio.EncodingS16LE})
+		require.NoError(t, err)
+
+		data := audio.EncodeSamples(nil, audio.Resample(samples, 16000, 48000), audio.EncodingS16LE)
+		var events []Event
+		for i := 0; i < len(data); i += 4001 {
+			evs, err := s.Write(context.Background(), data[i:min(i+4001, len(data))])
+			require.NoError(t, err)
+			events = append(events, evs...)
+		}
+		evs, err := s.Flush(context.Background())
+		require.NoError(t, err)
+		events = append(events, evs...)
+
+		segments, streamProbs := toSegments(t, events)
+		require.Empty(t, streamProbs)
+		require.Len(t, segments, len(expected))
+		for i := range segments {
+			require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+			require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.1)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		require.NoError(t, sd.Reset())
+		s, err := NewSession(sd, Config{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = s.WriteSamples(ctx, samples)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,245 @@
+package trim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+type Config struct {
+	// Non-speech gaps longer than this get shortened to GapMs. Leading and
+	// trailing non-speech count as gaps too.
+	MaxGapMs int
+	// The duration shortened gaps are reduced to. Zero removes them entirely.
+	// It should not exceed MaxGapMs.
+	GapMs int
+	// The duration of the crossfade applied at each join. It's limited by the
+	// amount of audio removed at the join and the length of the joined parts.
+	// Zero disables crossfading.
+	CrossfadeMs int
+}
+
+func (c Config) IsValid() error {
+	if c.MaxGapMs < 0 {
+		return fmt.Errorf("invalid MaxGapMs: should be a positive number")
+	}
+
+	if c.GapMs < 0 || c.GapMs > c.MaxGapMs {
+		return fmt.Errorf("invalid GapMs: should be in range [0, MaxGapMs]")
+	}
+
+	if c.CrossfadeMs < 0 {
+		return fmt.Errorf("invalid CrossfadeMs: should be a positive number")
+	}
+
+	return nil
+}
+
+// Span maps a contiguous range of the output audio to the input audio.
+type Span struct {
+	// The time in seconds at which the span begins in the output.
+	OutputStart float64 `json:"output_start"`
+	// The time in seconds at which the span begins in the input.
+	InputStart float64 `json:"input_start"`
+	// The duration of the span in seconds.
+	Duration float64 `json:"duration"`
+}
+
+// TimeMap maps timestamps in the trimmed output back to the original input,
+// and vice versa. Spans are ordered and contiguous in the output.
+type TimeMap []Span
+
+// InputTime maps a time in seconds in the output to the input. Times past
+// the end of the output map to the end of the last span.
+func (m TimeMap) InputTime(t float64) float64 {
+	if len(m) == 0 {
+		return t
+	}
+
+	i := sort.Search(len(m), func(i int) bool {
+		return m[i].OutputStart+m[i].Duration > t
+	})
+	if i == len(m) {
+		i = len(m) - 1
+	}
+
+	return m[i].InputStart + min(max(t-m[i].OutputStart, 0), m[i].Duration)
+}
+
+// OutputTime maps a time in seconds in the input to the output. Times falling
+// within removed audio map to the join where it was removed.
+func (m TimeMap) OutputTime(t float64) float64 {
+	if len(m) == 0 {
+		return t
+	}
+
+	i := sort.Search(len(m), func(i int) bool {
+		return m[i].InputStart+m[i].Duration > t
+	})
+	if i == len(m) {
+		i = len(m) - 1
+	}
+
+	return m[i].OutputStart + min(max(t-m[i].InputStart, 0), m[i].Duration)
+}
+
+// Trim runs speech detection over pcm, sampled at the detector rate, and
+// returns the audio with non-speech gaps shortened according to cfg, along
+// with the time map between the output and pcm.
+func Trim(sd *speech.Detector, pcm []float32, cfg Config) ([]float32, TimeMap, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	segments, err := sd.Detect(pcm)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect speech: %w", err)
+	}
+
+	return Apply(segments, pcm, sd.Config().SampleRate, cfg)
+}
+
+// Apply shortens the non-speech gaps between segments, as returned by Detect,
+// in pcm which can be sampled at any rate. A trailing segment still open at
+// the end of the input is considered to extend up to it.
+func Apply(segments []vad.Segment, pcm []float32, sampleRate int, cfg Config) ([]float32, TimeMap, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if sampleRate <= 0 {
+		return nil, nil, fmt.Errorf("invalid sample rate: should be a positive number")
+	}
+
+	toSamples := func(ms int) int {
+		return ms * sampleRate / 1000
+	}
+	ranges := keptRanges(speechRanges(segments, len(pcm), sampleRate), len(pcm),
+		toSamples(cfg.MaxGapMs), toSamples(cfg.GapMs))
+
+	var out []float32
+	timeMap := make(TimeMap, 0, len(ranges))
+	// The half crossfade of the previous join, reaching into the previous range.
+	prevHalf := 0
+	for i, r := range ranges {
+		timeMap = append(timeMap, Span{
+			OutputStart: float64(len(out)) / float64(sampleRate),
+			InputStart:  float64(r.start) / float64(sampleRate),
+			Duration:    float64(r.end-r.start) / float64(sampleRate),
+		})
+
+		join := len(out)
+		out = append(out, pcm[r.start:r.end]...)
+
+		if i > 0 {
+			prev := ranges[i-1]
+			// Half of the crossfade happens on each side of the join, reaching
+			// into the removed audio. It's kept within the room left by the
+			// previous join and half of this range, leaving the other half to
+			// the next join, so that fades never overlap.
+			half := min(toSamples(cfg.CrossfadeMs)/2, r.start-prev.end, prev.end-prev.start-prevHalf, (r.end-r.start)/2)
+			crossfade(out[join-half:join+half], pcm[prev.end-half:prev.end+half], pcm[r.start-half:r.start+half])
+			prevHalf = half
+		}
+	}
+
+	return out, timeMap, nil
+}
+
+// crossfade writes to dst an equal-power crossfade from a to b.
+func crossfade(dst, a, b []float32) {
+	for i := range dst {
+		w := (float64(i) + 0.5) / float64(len(dst)) * math.Pi / 2
+		dst[i] = float32(float64(a[i])*math.Cos(w) + float64(b[i])*math.Sin(w))
+	}
+}
+
+// sampleRange is a [start, end) range of samples.
+type sampleRange struct {
+	start int
+	end   int
+}
+
+// speechRanges converts segments to ordered, non-overlapping sample ranges.
+func speechRanges(segments []vad.Segment, numSamples, sampleRate int) []sampleRange {
+	var ranges []sampleRange
+	for _, seg := range segments {
+		start := min(int(math.Round(seg.SpeechStartAt*float64(sampleRate))), numSamples)
+		end := numSamples
+		if seg.SpeechEndAt != 0 {
+			end = min(int(math.Round(seg.SpeechEndAt*float64(sampleRate))), numSamples)
+		}
+		if end <= start {
+			continue
+		}
+
+		if n := len(ranges); n > 0 && start <= ranges[n-1].end {
+			ranges[n-1].end = max(ranges[n-1].end, end)
+			continue
+		}
+		ranges = append(ranges, sampleRange{start: start, end: end})
+	}
+	return ranges
+}
+
+// keptRanges returns the ranges of samples to keep so that gaps between
+// speech longer than maxGap get shortened to gap. Interior gaps keep audio
+// from both of their sides while leading and trailing gaps keep the audio
+// next to the speech.
+func keptRanges(voiced []sampleRange, numSamples, maxGap, gap int) []sampleRange {
+	var kept []sampleRange
+	add := func(r sampleRange) {
+		if r.end <= r.start {
+			return
+		}
+		if n := len(kept); n > 0 && kept[n-1].end == r.start {
+			kept[n-1].end = r.end
+			return
+		}
+		kept = append(kept, r)
+	}
+
+	if len(voiced) == 0 {
+		if numSamples <= maxGap {
+			add(sampleRange{start: 0, end: numSamples})
+		} else {
+			add(sampleRange{start: 0, end: gap})
+		}
+		return kept
+	}
+
+	// Leading gap.
+	if start := voiced[0].start; start <= maxGap {
+		add(sampleRange{start: 0, end: start})
+	} else {
+		add(sampleRange{start: start - gap, end: start})
+	}
+
+	for i, r := range voiced {
+		add(r)
+
+		if i == len(voiced)-1 {
+			break
+		}
+
+		next := voiced[i+1].start
+		if next-r.end <= maxGap {
+			add(sampleRange{start: r.end, end: next})
+			continue
+		}
+		add(sampleRange{start: r.end, end: r.end + gap/2})
+		add(sampleRange{start: next - (gap - gap/2), end: next})
+	}
+
+	// Trailing gap.
+	if end := voiced[len(voiced)-1].end; numSamples-end <= maxGap {
+		add(sampleRange{start: end, end: numSamples})
+	} else {
+		add(sampleRange{start: end, end: end + gap})
+	}
+
+	return kept
+}
@@ -0,0 +1,250 @@
+package trim
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	tcs := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "invalid MaxGapMs",
+			cfg:  Config{MaxGapMs: -1},
+			err:  "invalid MaxGapMs: should be a positive number",
+		},
+		{
+			name: "negative GapMs",
+			cfg:  Config{MaxGapMs: 100, GapMs: -1},
+			err:  "invalid GapMs: should be in range [0, MaxGapMs]",
+		},
+		{
+			name: "GapMs exceeding MaxGapMs",
+			cfg:  Config{MaxGapMs: 100, GapMs: 200},
+			err:  "invalid GapMs: should be in range [0, MaxGapMs]",
+		},
+		{
+			name: "invalid CrossfadeMs",
+			cfg:  Config{CrossfadeMs: -1},
+			err:  "invalid CrossfadeMs: should be a positive number",
+		},
+		{
+			name: "valid",
+			cfg:  Config{MaxGapMs: 500, GapMs: 200, CrossfadeMs: 10},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.IsValid()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKeptRanges(t *testing.T) {
+	voiced := []sampleRange{{start: 100, end: 200}, {start: 250, end: 300}, {start: 600, end: 700}}
+
+	tcs := []struct {
+		name     string
+		voiced   []sampleRange
+		maxGap   int
+		gap      int
+		expected []sampleRange
+	}{
+		{
+			name:     "remove all",
+			voiced:   voiced,
+			expected: voiced,
+		},
+		{
+			name:     "keep short gaps",
+			voiced:   voiced,
+			maxGap:   100,
+			expected: []sampleRange{{start: 0, end: 300}, {start: 600, end: 800}},
+		},
+		{
+			name:   "shorten long gaps",
+			voiced: voiced,
+			maxGap: 60,
+			gap:    41,
+			expected: []sampleRange{
+				{start: 59, end: 320},
+				{start: 579, end: 741},
+			},
+		},
+		{
+			name:     "no speech",
+			maxGap:   100,
+			gap:      50,
+			expected: []sampleRange{{start: 0, end: 50}},
+		},
+		{
+			name:     "no speech within max gap",
+			maxGap:   1000,
+			gap:      50,
+			expected: []sampleRange{{start: 0, end: 800}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, keptRanges(tc.voiced, 800, tc.maxGap, tc.gap))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	sampleRate := 1000
+	pcm := make([]float32, 3000)
+	for i := range pcm {
+		pcm[i] = float32(i)
+	}
+
+	segments := []speech.Segment{
+		{SpeechStartAt: 0.5, SpeechEndAt: 1},
+		{SpeechStartAt: 2, SpeechEndAt: 0},
+	}
+
+	t.Run("remove gaps", func(t *testing.T) {
+		out, timeMap, err := Apply(segments, pcm, sampleRate, Config{})
+		require.NoError(t, err)
+		require.Len(t, out, 1500)
+		require.Equal(t, float32(500), out[0])
+		require.Equal(t, float32(999), out[499])
+		require.Equal(t, float32(2000), out[500])
+		require.Equal(t, TimeMap{
+			{OutputStart: 0, InputStart: 0.5, Duration: 0.5},
+			{OutputStart: 0.5, InputStart: 2, Duration: 1},
+		}, timeMap)
+	})
+
+	t.Run("shorten gaps", func(t *testing.T) {
+		out, timeMap, err := Apply(segments, pcm, sampleRate, Config{MaxGapMs: 400, GapMs: 200})
+		require.NoError(t, err)
+		require.Len(t, out, 1900)
+		require.Equal(t, TimeMap{
+			{OutputStart: 0, InputStart: 0.3, Duration: 0.8},
+			{OutputStart: 0.8, InputStart: 1.9, Duration: 1.1},
+		}, timeMap)
+	})
+
+	t.Run("crossfade", func(t *testing.T) {
+		out, _, err := Apply(segments, pcm, sampleRate, Config{CrossfadeMs: 20})
+		require.NoError(t, err)
+		require.Len(t, out, 1500)
+		// Away from the join the audio is untouched.
+		require.Equal(t, float32(989), out[489])
+		require.Equal(t, float32(2010), out[510])
+		// Across the join it fades from the first part into the second.
+		require.InDelta(t, 990, out[490], 100)
+		require.InDelta(t, 2009, out[509], 100)
+		require.Greater(t, out[500], out[490])
+	})
+
+	t.Run("crossfade short range", func(t *testing.T) {
+		// The middle range is shorter than two crossfades, each join gets half
+		// of it.
+		segments := []speech.Segment{
+			{SpeechStartAt: 0.5, SpeechEndAt: 1},
+			{SpeechStartAt: 1.5, SpeechEndAt: 1.51},
+			{SpeechStartAt: 2, SpeechEndAt: 0},
+		}
+		out, _, err := Apply(segments, pcm, sampleRate, Config{CrossfadeMs: 40})
+		require.NoError(t, err)
+		require.Len(t, out, 1510)
+
+		first := make([]float32, 10)
+		crossfade(first, pcm[995:1005], pcm[1495:1505])
+		require.Equal(t, first, out[495:505])
+
+		second := make([]float32, 10)
+		crossfade(second, pcm[1505:1515], pcm[1995:2005])
+		require.Equal(t, second, out[505:515])
+
+		require.Equal(t, float32(994), out[494])
+		require.Equal(t, float32(2005), out[515])
+	})
+
+	t.Run("invalid sample rate", func(t *testing.T) {
+		_, _, err := Apply(segments, pcm, 0, Config{})
+		require.EqualError(t, err, "invalid sample rate: should be a positive number")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, _, err := Apply(segments, pcm, sampleRate, Config{GapMs: 100})
+		require.EqualError(t, err, "invalid config: invalid GapMs: should be in range [0, MaxGapMs]")
+	})
+}
+
+func TestTimeMap(t *testing.T) {
+	timeMap := TimeMap{
+		{OutputStart: 0, InputStart: 0.5, Duration: 0.5},
+		{OutputStart: 0.5, InputStart: 2, Duration: 1},
+	}
+
+	t.Run("input time", func(t *testing.T) {
+		require.Equal(t, 0.5, timeMap.InputTime(0))
+		require.Equal(t, 0.75, timeMap.InputTime(0.25))
+		require.Equal(t, 2.0, timeMap.InputTime(0.5))
+		require.Equal(t, 2.5, timeMap.InputTime(1))
+		require.Equal(t, 3.0, timeMap.InputTime(5))
+	})
+
+	t.Run("output time", func(t *testing.T) {
+		require.Equal(t, 0.0, timeMap.OutputTime(0))
+		require.Equal(t, 0.25, timeMap.OutputTime(0.75))
+		// Removed audio maps to the join.
+		require.Equal(t, 0.5, timeMap.OutputTime(1.5))
+		require.Equal(t, 1.0, timeMap.OutputTime(2.5))
+		require.Equal(t, 1.5, timeMap.OutputTime(5))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.Equal(t, 1.5, TimeMap(nil).InputTime(1.5))
+		require.Equal(t, 1.5, TimeMap(nil).OutputTime(1.5))
+	})
+}
+
+func TestTrim(t *testing.T) {
+	sd, err := speech.NewDetector(speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+
+	data, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	pcm := make([]float32, 0, len(data)/4)
+	for i := 0; i+4 <= len(data); i += 4 {
+		pcm = append(pcm, math.Float32frombits(binary.LittleEndian.Uint32(data[i:])))
+	}
+
+	out, timeMap, err := Trim(sd, pcm, Config{MaxGapMs: 500, GapMs: 200, CrossfadeMs: 10})
+	require.NoError(t, err)
+	require.NotEmpty(t, out)
+	require.Less(t, len(out), len(pcm))
+
+	// The start of the first speech segment, at 1.056s, is preceded by 200ms
+	// of kept silence.
+	require.Len(t, timeMap, 3)
+	require.InDelta(t, 0.856, timeMap[0].InputStart, 1e-9)
+	require.InDelta(t, 1.056, timeMap.InputTime(0.2), 1e-9)
+}
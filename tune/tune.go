@@ -0,0 +1,310 @@
+package tune
+
+import (
+	"fmt"
+
+	"github.com/streamer45/silero-vad-go/eval"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/vad"
+)
+
+// Item is an input to tune against, with its speech probabilities computed
+// once so that segmentation settings can be evaluated without running the
+// model again.
+type Item struct {
+	// The per-window speech probabilities of the input, as returned by
+	// Detector.Probabilities.
+	Probs []float32
+	// The duration of the input in seconds.
+	Duration float64
+	// The reference speech segments of the input.
+	Reference []vad.Segment
+}
+
+// Space holds the candidate values of each tuned parameter.
+type Space struct {
+	Thresholds           []float32
+	MinSilenceDurationMs []int
+	SpeechPadMs          []int
+}
+
+// DefaultSpace returns a search space covering commonly used values.
+func DefaultSpace() Space {
+	return Space{
+		Thresholds:           []float32{0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8},
+		MinSilenceDurationMs: []int{0, 50, 100, 200, 300, 500, 1000},
+		SpeechPadMs:          []int{0, 30, 60, 100, 200},
+	}
+}
+
+func (s Space) IsValid() error {
+	if len(s.Thresholds) == 0 {
+		return fmt.Errorf("invalid Thresholds: should not be empty")
+	}
+	for _, v := range s.Thresholds {
+		if v <= 0 || v >= 1 {
+			return fmt.Errorf("invalid Thresholds: values should be in range (0, 1)")
+		}
+	}
+
+	if len(s.MinSilenceDurationMs) == 0 {
+		return fmt.Errorf("invalid MinSilenceDurationMs: should not be empty")
+	}
+	for _, v := range s.MinSilenceDurationMs {
+		if v < 0 {
+			return fmt.Errorf("invalid MinSilenceDurationMs: values should be positive numbers")
+		}
+	}
+
+	if len(s.SpeechPadMs) == 0 {
+		return fmt.Errorf("invalid SpeechPadMs: should not be empty")
+	}
+	for _, v := range s.SpeechPadMs {
+		if v < 0 {
+			return fmt.Errorf("invalid SpeechPadMs: values should be positive numbers")
+		}
+	}
+
+	return nil
+}
+
+// Method is a search strategy.
+type Method int
+
+const (
+	// GridSearch evaluates every combination of the space values.
+	GridSearch Method = iota
+	// CoordinateDescent optimizes one parameter at a time, keeping the others
+	// fixed, until no change improves the score. It evaluates far fewer
+	// combinations but can settle on a local optimum.
+	CoordinateDescent
+)
+
+// Metric is the score being optimized.
+type Metric int
+
+const (
+	// MetricF1 maximizes the F1 score.
+	MetricF1 Metric = iota
+	// MetricDetectionErrorRate minimizes the detection error rate.
+	MetricDetectionErrorRate
+)
+
+// score returns the value of the metric for res, higher being better.
+func (m Metric) score(res eval.Result) float64 {
+	if m == MetricDetectionErrorRate {
+		return -res.DetectionErrorRate()
+	}
+	return res.F1()
+}
+
+type Config struct {
+	Space  Space
+	Method Method
+	Metric Metric
+	// The configuration used to score segments against the references.
+	Eval eval.Config
+	// The maximum number of passes over the parameters when using
+	// CoordinateDescent. Defaults to 10.
+	MaxRounds int
+}
+
+func (c Config) IsValid() error {
+	if err := c.Space.IsValid(); err != nil {
+		return fmt.Errorf("invalid Space: %w", err)
+	}
+
+	if c.Method != GridSearch && c.Method != CoordinateDescent {
+		return fmt.Errorf("invalid Method: unknown method")
+	}
+
+	if c.Metric != MetricF1 && c.Metric != MetricDetectionErrorRate {
+		return fmt.Errorf("invalid Metric: unknown metric")
+	}
+
+	if err := c.Eval.IsValid(); err != nil {
+		return fmt.Errorf("invalid Eval: %w", err)
+	}
+
+	if c.MaxRounds < 0 {
+		return fmt.Errorf("invalid MaxRounds: should be a positive number")
+	}
+
+	return nil
+}
+
+// Result is the outcome of tuning.
+type Result struct {
+	// The base configuration with the best parameters found.
+	Config speech.DetectorConfig
+	// The evaluation of the best parameters, summed over all items.
+	Eval eval.Result
+	// The number of parameter combinations evaluated.
+	Evaluated int
+}
+
+// point indexes a combination of values in the space.
+type point [3]int
+
+// Tune searches the space for the segmentation parameters scoring best over
+// the items. The other fields of base, including SampleRate which should
+// match the one the probabilities were computed at, are kept as is.
+func Tune(base speech.DetectorConfig, items []Item, cfg Config) (Result, error) {
+	if err := cfg.IsValid(); err != nil {
+		return Result{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if len(items) == 0 {
+		return Result{}, fmt.Errorf("no items to tune against")
+	}
+
+	t := &tuner{
+		base:    base,
+		items:   items,
+		cfg:     cfg,
+		results: map[point]eval.Result{},
+	}
+
+	var best point
+	var err error
+	if cfg.Method == CoordinateDescent {
+		best, err = t.coordinateDescent()
+	} else {
+		best, err = t.gridSearch()
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Config:    t.config(best),
+		Eval:      t.results[best],
+		Evaluated: len(t.results),
+	}, nil
+}
+
+type tuner struct {
+	base    speech.DetectorConfig
+	items   []Item
+	cfg     Config
+	results map[point]eval.Result
+}
+
+func (t *tuner) config(p point) speech.DetectorConfig {
+	cfg := t.base
+	cfg.Threshold = t.cfg.Space.Thresholds[p[0]]
+	cfg.MinSilenceDurationMs = t.cfg.Space.MinSilenceDurationMs[p[1]]
+	cfg.SpeechPadMs = t.cfg.Space.SpeechPadMs[p[2]]
+	return cfg
+}
+
+func (t *tuner) dims() point {
+	return point{len(t.cfg.Space.Thresholds), len(t.cfg.Space.MinSilenceDurationMs), len(t.cfg.Space.SpeechPadMs)}
+}
+
+// score evaluates the parameters at p over all items, caching the result.
+func (t *tuner) score(p point) (float64, error) {
+	if res, ok := t.results[p]; ok {
+		return t.cfg.Metric.score(res), nil
+	}
+
+	cfg := t.config(p)
+	var total eval.Result
+	for i, item := range t.items {
+		segments, err := speech.SegmentProbabilities(item.Probs, cfg)
+		if err != nil {
+			return 0, fmt.Errorf("item %d: %w", i, err)
+		}
+		res, err := eval.Evaluate(item.Reference, segments, item.Duration, t.cfg.Eval)
+		if err != nil {
+			return 0, fmt.Errorf("item %d: %w", i, err)
+		}
+		total = total.Add(res)
+	}
+	t.results[p] = total
+
+	return t.cfg.Metric.score(total), nil
+}
+
+func (t *tuner) gridSearch() (point, error) {
+	dims := t.dims()
+
+	var best point
+	bestScore := 0.0
+	first := true
+	for i := 0; i < dims[0]; i++ {
+		for j := 0; j < dims[1]; j++ {
+			for k := 0; k < dims[2]; k++ {
+				p := point{i, j, k}
+				score, err := t.score(p)
+				if err != nil {
+					return point{}, err
+				}
+				if first || score > bestScore {
+					best, bestScore, first = p, score, false
+				}
+			}
+		}
+	}
+
+	return best, nil
+}
+
+func (t *tuner) coordinateDescent() (point, error) {
+	dims := t.dims()
+	maxRounds := t.cfg.MaxRounds
+	if maxRounds == 0 {
+		maxRounds = 10
+	}
+
+	// Starting from the values closest to the base configuration.
+	best := point{
+		closest(t.cfg.Space.Thresholds, t.base.Threshold),
+		closest(t.cfg.Space.MinSilenceDurationMs, t.base.MinSilenceDurationMs),
+		closest(t.cfg.Space.SpeechPadMs, t.base.SpeechPadMs),
+	}
+	bestScore, err := t.score(best)
+	if err != nil {
+		return point{}, err
+	}
+
+	for round := 0; round < maxRounds; round++ {
+		improved := false
+		for d := range dims {
+			for v := 0; v < dims[d]; v++ {
+				p := best
+				p[d] = v
+				score, err := t.score(p)
+				if err != nil {
+					return point{}, err
+				}
+				if score > bestScore {
+					best, bestScore, improved = p, score, true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	return best, nil
+}
+
+// closest returns the index of the value closest to v.
+func closest[T int | float32](values []T, v T) int {
+	idx := 0
+	for i := range values {
+		if abs(values[i]-v) < abs(values[idx]-v) {
+			idx = i
+		}
+	}
+	return idx
+}
+
+func abs[T int | float32](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,192 @@
+package tune
+
+import (
+	"testing"
+
+	"github.com/streamer45/silero-vad-go/eval"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/stretchr/testify/require"
+)
+
+func testItems() []Item {
+	// 32ms windows: speech from 0.32s to 0.64s, followed by noise scoring
+	// below 0.5.
+	probs := make([]float32, 40)
+	for i := range probs {
+		switch {
+		case i >= 10 && i < 20:
+			probs[i] = 0.9
+		case i >= 25 && i < 30:
+			probs[i] = 0.4
+		default:
+			probs[i] = 0.05
+		}
+	}
+	return []Item{
+		{
+			Probs:     probs,
+			Duration:  1.28,
+			Reference: []speech.Segment{{SpeechStartAt: 0.32, SpeechEndAt: 0.64}},
+		},
+	}
+}
+
+func testBase() speech.DetectorConfig {
+	return speech.DetectorConfig{
+		ModelPath:            "model.onnx",
+		SampleRate:           16000,
+		Threshold:            0.3,
+		MinSilenceDurationMs: 300,
+		SpeechPadMs:          100,
+	}
+}
+
+func TestConfigIsValid(t *testing.T) {
+	tcs := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "valid",
+			modify: func(_ *Config) {},
+		},
+		{
+			name: "empty thresholds",
+			modify: func(cfg *Config) {
+				cfg.Space.Thresholds = nil
+			},
+			err: "invalid Space: invalid Thresholds: should not be empty",
+		},
+		{
+			name: "invalid threshold",
+			modify: func(cfg *Config) {
+				cfg.Space.Thresholds = []float32{0.5, 1}
+			},
+			err: "invalid Space: invalid Thresholds: values should be in range (0, 1)",
+		},
+		{
+			name: "invalid min silence",
+			modify: func(cfg *Config) {
+				cfg.Space.MinSilenceDurationMs = []int{-1}
+			},
+			err: "invalid Space: invalid MinSilenceDurationMs: values should be positive numbers",
+		},
+		{
+			name: "empty speech pads",
+			modify: func(cfg *Config) {
+				cfg.Space.SpeechPadMs = []int{}
+			},
+			err: "invalid Space: invalid SpeechPadMs: should not be empty",
+		},
+		{
+			name: "invalid method",
+			modify: func(cfg *Config) {
+				cfg.Method = Method(5)
+			},
+			err: "invalid Method: unknown method",
+		},
+		{
+			name: "invalid metric",
+			modify: func(cfg *Config) {
+				cfg.Metric = Metric(5)
+			},
+			err: "invalid Metric: unknown metric",
+		},
+		{
+			name: "invalid eval",
+			modify: func(cfg *Config) {
+				cfg.Eval.FrameMs = -1
+			},
+			err: "invalid Eval: invalid FrameMs: should be a positive number",
+		},
+		{
+			name: "invalid max rounds",
+			modify: func(cfg *Config) {
+				cfg.MaxRounds = -1
+			},
+			err: "invalid MaxRounds: should be a positive number",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{Space: DefaultSpace()}
+			tc.modify(&cfg)
+			err := cfg.IsValid()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTune(t *testing.T) {
+	space := Space{
+		Thresholds:           []float32{0.3, 0.5, 0.7},
+		MinSilenceDurationMs: []int{0, 300},
+		SpeechPadMs:          []int{0, 100},
+	}
+
+	for _, method := range []Method{GridSearch, CoordinateDescent} {
+		for _, metric := range []Metric{MetricF1, MetricDetectionErrorRate} {
+			res, err := Tune(testBase(), testItems(), Config{
+				Space:  space,
+				Method: method,
+				Metric: metric,
+				// Forgiving the window of latency on speech end.
+				Eval: eval.Config{CollarMs: 50},
+			})
+			require.NoError(t, err)
+
+			require.GreaterOrEqual(t, res.Config.Threshold, float32(0.5))
+			require.Equal(t, 0, res.Config.SpeechPadMs)
+			require.Equal(t, 1.0, res.Eval.F1())
+			require.Zero(t, res.Eval.DetectionErrorRate())
+
+			// Other fields are kept.
+			require.Equal(t, "model.onnx", res.Config.ModelPath)
+			require.Equal(t, 16000, res.Config.SampleRate)
+
+			if method == GridSearch {
+				require.Equal(t, 12, res.Evaluated)
+			} else {
+				require.Less(t, res.Evaluated, 12)
+			}
+		}
+	}
+}
+
+func TestTuneErrors(t *testing.T) {
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := Tune(testBase(), testItems(), Config{})
+		require.EqualError(t, err, "invalid config: invalid Space: invalid Thresholds: should not be empty")
+	})
+
+	t.Run("no items", func(t *testing.T) {
+		_, err := Tune(testBase(), nil, Config{Space: DefaultSpace()})
+		require.EqualError(t, err, "no items to tune against")
+	})
+
+	t.Run("invalid sample rate", func(t *testing.T) {
+		base := testBase()
+		base.SampleRate = 44100
+		_, err := Tune(base, testItems(), Config{Space: DefaultSpace()})
+		require.ErrorIs(t, err, speech.ErrInvalidConfig)
+	})
+
+	t.Run("invalid eval", func(t *testing.T) {
+		items := testItems()
+		items[0].Duration = -1
+		_, err := Tune(testBase(), items, Config{Space: DefaultSpace(), Eval: eval.Config{FrameMs: 10}})
+		require.Error(t, err)
+	})
+}
+
+func TestClosest(t *testing.T) {
+	require.Equal(t, 1, closest([]float32{0.3, 0.5, 0.7}, 0.45))
+	require.Equal(t, 0, closest([]int{0, 100, 200}, -10))
+	require.Equal(t, 2, closest([]int{0, 100, 200}, 500))
+}
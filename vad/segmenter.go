@@ -0,0 +1,178 @@
+package vad
+
+import "fmt"
+
+// SegmenterConfig holds the settings turning speech probabilities into segments.
+type SegmenterConfig struct {
+	// The sampling rate of the audio.
+	SampleRate int
+	// The number of samples of each window.
+	WindowSize int
+	// The duration of silence to wait for each speech segment before separating it.
+	MinSilenceDurationMs int
+	// The padding to add to speech segments to avoid aggressive cutting.
+	SpeechPadMs int
+}
+
+// Segmenter turns per-window speech probabilities into speech segments,
+// following the Silero VAD reference logic: speech starts on the first window
+// reaching the threshold and ends once probabilities stay below the threshold
+// minus 0.15 for MinSilenceDurationMs.
+type Segmenter struct {
+	windowSize        int
+	sampleRate        int
+	minSilenceSamples int
+	speechPadSamples  int
+
+	currSample        int
+	triggered         bool
+	tempEnd           int
+	pendingStart      float64
+	pendingStartValid bool
+}
+
+// WindowSizeForSampleRate returns the number of samples of each window at
+// sampleRate, as expected by the Silero model: 256 at 8kHz and 512 at 16kHz.
+func WindowSizeForSampleRate(sampleRate int) int {
+	if sampleRate == 8000 {
+		return 256
+	}
+	return 512
+}
+
+func NewSegmenter(cfg SegmenterConfig) Segmenter {
+	return Segmenter{
+		windowSize:        cfg.WindowSize,
+		sampleRate:        cfg.SampleRate,
+		minSilenceSamples: cfg.MinSilenceDurationMs * cfg.SampleRate / 1000,
+		speechPadSamples:  cfg.SpeechPadMs * cfg.SampleRate / 1000,
+	}
+}
+
+// Event is the outcome of a window, which either starts a segment, ends the
+// open one, or neither.
+type Event struct {
+	HasStart bool
+	StartAt  float64
+	HasEnd   bool
+	EndAt    float64
+	// The start of the segment ending.
+	EndStartAt float64
+}
+
+// Samples returns the number of samples accounted for so far.
+func (s *Segmenter) Samples() int {
+	return s.currSample
+}
+
+// Triggered returns whether a speech segment is open.
+func (s *Segmenter) Triggered() bool {
+	return s.triggered
+}
+
+// Reset clears the segmentation state.
+func (s *Segmenter) Reset() {
+	s.currSample = 0
+	s.triggered = false
+	s.tempEnd = 0
+	s.pendingStart = 0
+	s.pendingStartValid = false
+}
+
+// Advance accounts for the next window, of the given speech probability,
+// against threshold.
+func (s *Segmenter) Advance(speechProb, threshold float32) (Event, error) {
+	var event Event
+	s.currSample += s.windowSize
+
+	if speechProb >= threshold && s.tempEnd != 0 {
+		s.tempEnd = 0
+	}
+
+	if speechProb >= threshold && !s.triggered {
+		s.triggered = true
+		speechStartAt := float64(s.currSample-s.windowSize-s.speechPadSamples) / float64(s.sampleRate)
+
+		// We clamp at zero since due to padding the starting position could be negative.
+		if speechStartAt < 0 {
+			speechStartAt = 0
+		}
+
+		s.pendingStart = speechStartAt
+		s.pendingStartValid = true
+
+		event.HasStart = true
+		event.StartAt = speechStartAt
+	}
+
+	if speechProb < (threshold-0.15) && s.triggered {
+		if s.tempEnd == 0 {
+			s.tempEnd = s.currSample
+		}
+
+		// Not enough silence yet to split, we continue.
+		if s.currSample-s.tempEnd < s.minSilenceSamples {
+			return event, nil
+		}
+
+		speechEndAt := float64(s.tempEnd+s.speechPadSamples) / float64(s.sampleRate)
+		s.tempEnd = 0
+		s.triggered = false
+
+		if !s.pendingStartValid {
+			return event, fmt.Errorf("unexpected speech end")
+		}
+
+		event.HasEnd = true
+		event.EndAt = speechEndAt
+		event.EndStartAt = s.pendingStart
+		s.pendingStartValid = false
+	}
+
+	return event, nil
+}
+
+// AppendSegments merges event into the segments returned by batch detection,
+// closing the open segment when it ends.
+func AppendSegments(segments []Segment, event Event) []Segment {
+	if event.HasStart {
+		segments = append(segments, Segment{
+			SpeechStartAt: event.StartAt,
+		})
+	}
+
+	if event.HasEnd {
+		if len(segments) > 0 &&
+			segments[len(segments)-1].SpeechEndAt == 0 &&
+			segments[len(segments)-1].SpeechStartAt == event.EndStartAt {
+			segments[len(segments)-1].SpeechEndAt = event.EndAt
+		} else {
+			segments = append(segments, Segment{
+				SpeechStartAt: event.EndStartAt,
+				SpeechEndAt:   event.EndAt,
+			})
+		}
+	}
+
+	return segments
+}
+
+// AppendUpdates appends the segment updates of event, as returned by
+// streaming detection: a segment without end when speech starts and a full
+// one when it ends.
+func AppendUpdates(segments []Segment, event Event) []Segment {
+	if event.HasStart {
+		segments = append(segments, Segment{
+			SpeechStartAt: event.StartAt,
+		})
+	}
+
+	if event.HasEnd {
+		segments = append(segments, Segment{
+			SpeechStartAt: event.EndStartAt,
+			SpeechEndAt:   event.EndAt,
+		})
+	}
+
+	return segments
+}
@@ -0,0 +1,120 @@
+package vad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmenter(t *testing.T) {
+	cfg := SegmenterConfig{
+		SampleRate: 16000,
+		WindowSize: 512,
+	}
+
+	segment := func(cfg SegmenterConfig, probs []float32, stream bool) []Segment {
+		t.Helper()
+		s := NewSegmenter(cfg)
+		var segments []Segment
+		for _, prob := range probs {
+			event, err := s.Advance(prob, 0.5)
+			require.NoError(t, err)
+			if stream {
+				segments = AppendUpdates(segments, event)
+			} else {
+				segments = AppendSegments(segments, event)
+			}
+		}
+		require.Equal(t, len(probs)*cfg.WindowSize, s.Samples())
+		return segments
+	}
+
+	tcs := []struct {
+		name     string
+		cfg      SegmenterConfig
+		probs    []float32
+		stream   bool
+		expected []Segment
+	}{
+		{
+			name:  "batch",
+			cfg:   cfg,
+			probs: []float32{0.1, 0.9, 0.9, 0.1, 0.1, 0.8},
+			expected: []Segment{
+				{SpeechStartAt: 0.032, SpeechEndAt: 0.128},
+				{SpeechStartAt: 0.16},
+			},
+		},
+		{
+			name:   "stream",
+			cfg:    cfg,
+			probs:  []float32{0.1, 0.9, 0.9, 0.1, 0.1, 0.8},
+			stream: true,
+			expected: []Segment{
+				{SpeechStartAt: 0.032},
+				{SpeechStartAt: 0.032, SpeechEndAt: 0.128},
+				{SpeechStartAt: 0.16},
+			},
+		},
+		{
+			name:     "hysteresis",
+			cfg:      cfg,
+			probs:    []float32{0.9, 0.4, 0.4, 0.9},
+			expected: []Segment{{SpeechStartAt: 0}},
+		},
+		{
+			name: "min silence",
+			cfg: SegmenterConfig{
+				SampleRate:           16000,
+				WindowSize:           512,
+				MinSilenceDurationMs: 100,
+			},
+			probs:    []float32{0.1, 0.9, 0.9, 0.1, 0.1, 0.8},
+			expected: []Segment{{SpeechStartAt: 0.032}},
+		},
+		{
+			name: "padding",
+			cfg: SegmenterConfig{
+				SampleRate:  8000,
+				WindowSize:  256,
+				SpeechPadMs: 16,
+			},
+			probs: []float32{0.1, 0.1, 0.9, 0.1, 0.1},
+			expected: []Segment{
+				{SpeechStartAt: 0.048, SpeechEndAt: 0.144},
+			},
+		},
+		{
+			name:  "empty",
+			cfg:   cfg,
+			probs: nil,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, segment(tc.cfg, tc.probs, tc.stream))
+		})
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		s := NewSegmenter(cfg)
+		event, err := s.Advance(0.9, 0.5)
+		require.NoError(t, err)
+		require.True(t, event.HasStart)
+		require.True(t, s.Triggered())
+
+		s.Reset()
+		require.False(t, s.Triggered())
+		require.Zero(t, s.Samples())
+
+		event, err = s.Advance(0.9, 0.5)
+		require.NoError(t, err)
+		require.Equal(t, Event{HasStart: true}, event)
+	})
+}
+
+func TestWindowSizeForSampleRate(t *testing.T) {
+	require.Equal(t, 256, WindowSizeForSampleRate(8000))
+	require.Equal(t, 512, WindowSizeForSampleRate(16000))
+}
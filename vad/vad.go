@@ -0,0 +1,35 @@
+package vad
+
+import "errors"
+
+var (
+	// ErrNotEnoughSamples is returned when the input is shorter than a window.
+	ErrNotEnoughSamples = errors.New("not enough samples")
+	// ErrInvalidSamplesLength is returned when inferring on a buffer that
+	// doesn't match the window size.
+	ErrInvalidSamplesLength = errors.New("invalid samples length")
+)
+
+// Segment contains timing information of a speech segment.
+type Segment struct {
+	// The relative timestamp in seconds of when a speech segment begins.
+	SpeechStartAt float64
+	// The relative timestamp in seconds of when a speech segment ends.
+	SpeechEndAt float64
+}
+
+// Detector is the API shared by speech detectors, allowing to swap between
+// the Silero detector of the speech package and lightweight fallbacks.
+type Detector interface {
+	// Detect returns the speech segments of pcm. A segment still open at the
+	// end has a zero SpeechEndAt.
+	Detect(pcm []float32) ([]Segment, error)
+	// DetectStream processes streaming audio chunks and emits segment
+	// updates: a segment when speech starts (SpeechEndAt == 0) and when it
+	// ends.
+	DetectStream(pcm []float32) ([]Segment, error)
+	// Reset clears the detection state so that a new stream can be processed.
+	Reset() error
+	// Destroy releases the resources held by the detector.
+	Destroy() error
+}
@@ -0,0 +1,277 @@
+package vadgrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+	"github.com/streamer45/silero-vad-go/vadpb"
+)
+
+type Config struct {
+	// The maximum duration to wait for a detector to be available. Zero means
+	// waiting as long as the call lasts.
+	AcquireTimeout time.Duration
+}
+
+func (c Config) IsValid() error {
+	if c.AcquireTimeout < 0 {
+		return fmt.Errorf("invalid AcquireTimeout: should be a positive duration")
+	}
+
+	return nil
+}
+
+// Server implements the VAD gRPC service using a pool of detectors.
+type Server struct {
+	vadpb.UnimplementedVADServer
+
+	cfg  Config
+	pool *speech.Pool
+}
+
+func NewServer(pool *speech.Pool, cfg Config) (*Server, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("invalid nil pool")
+	}
+
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &Server{
+		cfg:  cfg,
+		pool: pool,
+	}, nil
+}
+
+func (s *Server) sampleRate() int {
+	return s.pool.Config().DetectorConfig.SampleRate
+}
+
+// getDetector acquires a detector from the pool. The caller is responsible
+// for calling putDetector once done.
+func (s *Server) getDetector(ctx context.Context) (*speech.Detector, error) {
+	if s.cfg.AcquireTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.AcquireTimeout)
+		defer cancel()
+	}
+
+	sd, err := s.pool.Get(ctx)
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("failed to get detector: %w", err))
+	}
+
+	return sd, nil
+}
+
+func (s *Server) putDetector(sd *speech.Detector) {
+	if err := s.pool.Put(sd); err != nil {
+		slog.Error("failed to return detector", slog.String("err", err.Error()))
+	}
+}
+
+// toStatus converts err to a gRPC status error.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, speech.ErrPoolClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func invalidArgument(format string, args ...any) error {
+	return status.Errorf(codes.InvalidArgument, format, args...)
+}
+
+// decodeAudio decodes data according to format, returning the samples along
+// with their sample rate.
+func (s *Server) decodeAudio(data []byte, format *vadpb.AudioFormat) ([]float32, int, error) {
+	if format.GetEncoding() == vadpb.AudioEncoding_AUDIO_ENCODING_WAV {
+		samples, wavFormat, err := audio.ReadWAV(bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, invalidArgument("invalid audio: %s", err)
+		}
+		return samples, wavFormat.SampleRate, nil
+	}
+
+	enc, err := encoding(format.GetEncoding())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rate, err := s.formatSampleRate(format)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return audio.DecodeSamples(nil, data, enc), rate, nil
+}
+
+// encoding returns the PCM encoding matching enc.
+func encoding(enc vadpb.AudioEncoding) (audio.Encoding, error) {
+	switch enc {
+	case vadpb.AudioEncoding_AUDIO_ENCODING_UNSPECIFIED, vadpb.AudioEncoding_AUDIO_ENCODING_F32LE:
+		return audio.EncodingFloat32LE, nil
+	case vadpb.AudioEncoding_AUDIO_ENCODING_S16LE:
+		return audio.EncodingS16LE, nil
+	default:
+		return 0, invalidArgument("unsupported encoding %s", enc)
+	}
+}
+
+func (s *Server) formatSampleRate(format *vadpb.AudioFormat) (int, error) {
+	rate := int(format.GetSampleRate())
+	if rate < 0 {
+		return 0, invalidArgument("invalid sample rate %d", rate)
+	}
+	if rate == 0 {
+		rate = s.sampleRate()
+	}
+	return rate, nil
+}
+
+func (s *Server) Detect(ctx context.Context, req *vadpb.DetectRequest) (*vadpb.DetectResponse, error) {
+	samples, sampleRate, err := s.decodeAudio(req.GetAudio(), req.GetFormat())
+	if err != nil {
+		return nil, err
+	}
+	duration := float64(len(samples)) / float64(sampleRate)
+	samples = audio.Resample(samples, sampleRate, s.sampleRate())
+
+	sd, err := s.getDetector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.putDetector(sd)
+
+	res := &vadpb.DetectResponse{Duration: duration}
+	if len(samples) < sd.WindowSize() {
+		return res, nil
+	}
+
+	segments, err := sd.DetectContext(ctx, samples)
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("failed to detect speech: %w", err))
+	}
+
+	for _, seg := range segments {
+		// A segment still open at the end of the input ends with it.
+		if seg.SpeechEndAt == 0 {
+			seg.SpeechEndAt = duration
+		}
+		res.Segments = append(res.Segments, &vadpb.Segment{Start: seg.SpeechStartAt, End: seg.SpeechEndAt})
+	}
+
+	return res, nil
+}
+
+func (s *Server) DetectStream(srv vadpb.VAD_DetectStreamServer) error {
+	ctx := srv.Context()
+
+	req, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	cfg := req.GetConfig()
+	if cfg == nil {
+		return invalidArgument("expected config as first request")
+	}
+
+	sessionCfg := stream.Config{Probabilities: cfg.GetProbabilities()}
+	if sessionCfg.Encoding, err = encoding(cfg.GetFormat().GetEncoding()); err != nil {
+		return err
+	}
+	if sessionCfg.SampleRate, err = s.formatSampleRate(cfg.GetFormat()); err != nil {
+		return err
+	}
+	if cfg.GetThreshold() < 0 || cfg.GetThreshold() >= 1 {
+		return invalidArgument("invalid threshold: should be in range (0, 1)")
+	}
+
+	sd, err := s.getDetector(ctx)
+	if err != nil {
+		return err
+	}
+	threshold := sd.Config().Threshold
+	defer func() {
+		sd.SetThreshold(threshold)
+		s.putDetector(sd)
+	}()
+	if cfg.GetThreshold() != 0 {
+		sd.SetThreshold(cfg.GetThreshold())
+	}
+
+	session, err := stream.NewSession(sd, sessionCfg)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for {
+		req, err := srv.Recv()
+		if errors.Is(err, io.EOF) {
+			events, err := session.Flush(ctx)
+			if err != nil {
+				return toStatus(err)
+			}
+			return sendEvents(srv, events)
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.GetConfig() != nil {
+			return invalidArgument("unexpected config request")
+		}
+
+		events, err := session.Write(ctx, req.GetAudio())
+		if err != nil {
+			return toStatus(err)
+		}
+		if err := sendEvents(srv, events); err != nil {
+			return err
+		}
+	}
+}
+
+func sendEvents(srv vadpb.VAD_DetectStreamServer, events []stream.Event) error {
+	for _, ev := range events {
+		res := &vadpb.DetectStreamResponse{}
+		switch ev.Type {
+		case stream.EventSpeechStart:
+			res.Event = &vadpb.DetectStreamResponse_SpeechStart{
+				SpeechStart: &vadpb.SpeechStart{Start: ev.Start},
+			}
+		case stream.EventSpeechEnd:
+			res.Event = &vadpb.DetectStreamResponse_SpeechEnd{
+				SpeechEnd: &vadpb.SpeechEnd{Start: ev.Start, End: ev.End},
+			}
+		case stream.EventProbability:
+			res.Event = &vadpb.DetectStreamResponse_Probability{
+				Probability: &vadpb.Probability{Time: ev.Time, Probability: ev.Probability},
+			}
+		}
+		if err := srv.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package vadgrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/vadpb"
+)
+
+func newTestPool(t *testing.T) *speech.Pool {
+	t.Helper()
+
+	pool, err := speech.NewPool(speech.PoolConfig{
+		DetectorConfig: speech.DetectorConfig{
+			ModelPath:  "../testfiles/silero_vad.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		},
+		MaxSize: 2,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, pool.Close())
+	})
+
+	return pool
+}
+
+// newTestClient serves the VAD service over an in-process listener and
+// returns a client connected to it.
+func newTestClient(t *testing.T, pool *speech.Pool) vadpb.VADClient {
+	t.Helper()
+
+	srv, err := NewServer(pool, Config{AcquireTimeout: time.Second})
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	grpcSrv := grpc.NewServer()
+	vadpb.RegisterVADServer(grpcSrv, srv)
+	go func() {
+		_ = grpcSrv.Serve(lis)
+	}()
+	t.Cleanup(grpcSrv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+
+	return vadpb.NewVADClient(conn)
+}
+
+// runStream sends the given requests, closes the send side and returns all
+// the received events.
+func runStream(t *testing.T, client vadpb.VADClient, reqs ...*vadpb.DetectStreamRequest) ([]*vadpb.DetectStreamResponse, error) {
+	t.Helper()
+
+	st, err := client.DetectStream(context.Background())
+	require.NoError(t, err)
+
+	for _, req := range reqs {
+		if err := st.Send(req); err != nil {
+			// The server failed, the error is reported by Recv.
+			break
+		}
+	}
+	require.NoError(t, st.CloseSend())
+
+	var events []*vadpb.DetectStreamResponse
+	for {
+		res, err := st.Recv()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, res)
+	}
+}
+
+func configRequest(cfg *vadpb.StreamConfig) *vadpb.DetectStreamRequest {
+	return &vadpb.DetectStreamRequest{Request: &vadpb.DetectStreamRequest_Config{Config: cfg}}
+}
+
+func audioRequest(data []byte) *vadpb.DetectStreamRequest {
+	return &vadpb.DetectStreamRequest{Request: &vadpb.DetectStreamRequest_Audio{Audio: data}}
+}
+
+func TestNewServer(t *testing.T) {
+	_, err := NewServer(nil, Config{})
+	require.EqualError(t, err, "invalid nil pool")
+
+	_, err = NewServer(newTestPool(t), Config{AcquireTimeout: -1})
+	require.EqualError(t, err, "invalid config: invalid AcquireTimeout: should be a positive duration")
+}
+
+func TestServerErrors(t *testing.T) {
+	pool := newTestPool(t)
+	client := newTestClient(t, pool)
+
+	t.Run("detect invalid wav", func(t *testing.T) {
+		_, err := client.Detect(context.Background(), &vadpb.DetectRequest{
+			Format: &vadpb.AudioFormat{Encoding: vadpb.AudioEncoding_AUDIO_ENCODING_WAV},
+			Audio:  []byte("RIFF"),
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("detect invalid sample rate", func(t *testing.T) {
+		_, err := client.Detect(context.Background(), &vadpb.DetectRequest{
+			Format: &vadpb.AudioFormat{SampleRate: -1},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Equal(t, "invalid sample rate -1", status.Convert(err).Message())
+	})
+
+	t.Run("stream missing config", func(t *testing.T) {
+		_, err := runStream(t, client, audioRequest([]byte{0, 0, 0, 0}))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Equal(t, "expected config as first request", status.Convert(err).Message())
+	})
+
+	t.Run("stream wav", func(t *testing.T) {
+		_, err := runStream(t, client, configRequest(&vadpb.StreamConfig{
+			Format: &vadpb.AudioFormat{Encoding: vadpb.AudioEncoding_AUDIO_ENCODING_WAV},
+		}))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Equal(t, "unsupported encoding AUDIO_ENCODING_WAV", status.Convert(err).Message())
+	})
+
+	t.Run("stream invalid threshold", func(t *testing.T) {
+		_, err := runStream(t, client, configRequest(&vadpb.StreamConfig{Threshold: 1.5}))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("pool closed", func(t *testing.T) {
+		require.NoError(t, pool.Close())
+
+		_, err := client.Detect(context.Background(), &vadpb.DetectRequest{Audio: make([]byte, 4096)})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+
+		_, err = runStream(t, client, configRequest(&vadpb.StreamConfig{}))
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestServer(t *testing.T) {
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+	duration := float64(len(samples)) / 16000
+
+	pool := newTestPool(t)
+	client := newTestClient(t, pool)
+
+	expected := []*vadpb.Segment{
+		{Start: 1.056, End: 1.632},
+		{Start: 2.88, End: 3.232},
+		{Start: 4.448, End: duration},
+	}
+
+	requireSegments := func(t *testing.T, expected, actual []*vadpb.Segment, delta float64) {
+		t.Helper()
+		require.Len(t, actual, len(expected))
+		for i := range expected {
+			require.InDelta(t, expected[i].Start, actual[i].Start, delta)
+			require.InDelta(t, expected[i].End, actual[i].End, delta)
+		}
+	}
+
+	t.Run("detect", func(t *testing.T) {
+		res, err := client.Detect(context.Background(), &vadpb.DetectRequest{Audio: pcm})
+		require.NoError(t, err)
+		require.Equal(t, duration, res.Duration)
+		requireSegments(t, expected, res.Segments, 1e-9)
+	})
+
+	t.Run("detect wav", func(t *testing.T) {
+		var wav bytes.Buffer
+		require.NoError(t, audio.WriteWAV(&wav, audio.Resample(samples, 16000, 8000), 8000))
+		res, err := client.Detect(context.Background(), &vadpb.DetectRequest{
+			Format: &vadpb.AudioFormat{Encoding: vadpb.AudioEncoding_AUDIO_ENCODING_WAV},
+			Audio:  wav.Bytes(),
+		})
+		require.NoError(t, err)
+		requireSegments(t, expected, res.Segments, 0.1)
+	})
+
+	t.Run("detect short input", func(t *testing.T) {
+		res, err := client.Detect(context.Background(), &vadpb.DetectRequest{Audio: pcm[:100]})
+		require.NoError(t, err)
+		require.Empty(t, res.Segments)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		reqs := []*vadpb.DetectStreamRequest{configRequest(&vadpb.StreamConfig{Probabilities: true})}
+		for i := 0; i < len(pcm); i += 1001 {
+			reqs = append(reqs, audioRequest(pcm[i:min(i+1001, len(pcm))]))
+		}
+
+		events, err := runStream(t, client, reqs...)
+		require.NoError(t, err)
+
+		var segments []*vadpb.Segment
+		var probs int
+		var start *vadpb.SpeechStart
+		for _, ev := range events {
+			switch e := ev.Event.(type) {
+			case *vadpb.DetectStreamResponse_SpeechStart:
+				require.Nil(t, start)
+				start = e.SpeechStart
+			case *vadpb.DetectStreamResponse_SpeechEnd:
+				require.NotNil(t, start)
+				require.Equal(t, start.Start, e.SpeechEnd.Start)
+				segments = append(segments, &vadpb.Segment{Start: e.SpeechEnd.Start, End: e.SpeechEnd.End})
+				start = nil
+			case *vadpb.DetectStreamResponse_Probability:
+				probs++
+			}
+		}
+		requireSegments(t, expected, segments, 1e-9)
+		require.Equal(t, len(samples)/512, probs)
+	})
+
+	t.Run("stream without audio", func(t *testing.T) {
+		// Closing the stream right after the config ends it cleanly.
+		events, err := runStream(t, client, configRequest(&vadpb.StreamConfig{}))
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("stream unexpected config", func(t *testing.T) {
+		_, err := runStream(t, client, configRequest(&vadpb.StreamConfig{}), configRequest(&vadpb.StreamConfig{}))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,993 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        (unknown)
+// source: vad.proto
+
+package vadpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AudioEncoding int32
+
+const (
+	AudioEncoding_AUDIO_ENCODING_UNSPECIFIED AudioEncoding = 0
+	// 32-bit IEEE float little-endian PCM.
+	AudioEncoding_AUDIO_ENCODING_F32LE AudioEncoding = 1
+	// 16-bit signed integer little-endian PCM.
+	AudioEncoding_AUDIO_ENCODING_S16LE AudioEncoding = 2
+	// A WAV file, only supported by Detect.
+	AudioEncoding_AUDIO_ENCODING_WAV AudioEncoding = 3
+)
+
+// Enum value maps for AudioEncoding.
+var (
+	AudioEncoding_name = map[int32]string{
+		0: "AUDIO_ENCODING_UNSPECIFIED",
+		1: "AUDIO_ENCODING_F32LE",
+		2: "AUDIO_ENCODING_S16LE",
+		3: "AUDIO_ENCODING_WAV",
+	}
+	AudioEncoding_value = map[string]int32{
+		"AUDIO_ENCODING_UNSPECIFIED": 0,
+		"AUDIO_ENCODING_F32LE":       1,
+		"AUDIO_ENCODING_S16LE":       2,
+		"AUDIO_ENCODING_WAV":         3,
+	}
+)
+
+func (x AudioEncoding) Enum() *AudioEncoding {
+	p := new(AudioEncoding)
+	*p = x
+	return p
+}
+
+func (x AudioEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AudioEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_vad_proto_enumTypes[0].Descriptor()
+}
+
+func (AudioEncoding) Type() protoreflect.EnumType {
+	return &file_vad_proto_enumTypes[0]
+}
+
+func (x AudioEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AudioEncoding.Descriptor instead.
+func (AudioEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{0}
+}
+
+type AudioFormat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Defaults to AUDIO_ENCODING_F32LE.
+	Encoding AudioEncoding `protobuf:"varint,1,opt,name=encoding,proto3,enum=silerovad.v1.AudioEncoding" json:"encoding,omitempty"`
+	// The sample rate of PCM audio. Defaults to the model sample rate.
+	SampleRate int32 `protobuf:"varint,2,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+}
+
+func (x *AudioFormat) Reset() {
+	*x = AudioFormat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AudioFormat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioFormat) ProtoMessage() {}
+
+func (x *AudioFormat) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioFormat.ProtoReflect.Descriptor instead.
+func (*AudioFormat) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AudioFormat) GetEncoding() AudioEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return AudioEncoding_AUDIO_ENCODING_UNSPECIFIED
+}
+
+func (x *AudioFormat) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+type DetectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format *AudioFormat `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Audio  []byte       `protobuf:"bytes,2,opt,name=audio,proto3" json:"audio,omitempty"`
+}
+
+func (x *DetectRequest) Reset() {
+	*x = DetectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectRequest) ProtoMessage() {}
+
+func (x *DetectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectRequest.ProtoReflect.Descriptor instead.
+func (*DetectRequest) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DetectRequest) GetFormat() *AudioFormat {
+	if x != nil {
+		return x.Format
+	}
+	return nil
+}
+
+func (x *DetectRequest) GetAudio() []byte {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+// Segment is a speech segment, in seconds from the beginning of the audio.
+type Segment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start float64 `protobuf:"fixed64,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   float64 `protobuf:"fixed64,2,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *Segment) Reset() {
+	*x = Segment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Segment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Segment) ProtoMessage() {}
+
+func (x *Segment) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Segment.ProtoReflect.Descriptor instead.
+func (*Segment) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Segment) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Segment) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type DetectResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The duration of the audio in seconds.
+	Duration float64    `protobuf:"fixed64,1,opt,name=duration,proto3" json:"duration,omitempty"`
+	Segments []*Segment `protobuf:"bytes,2,rep,name=segments,proto3" json:"segments,omitempty"`
+}
+
+func (x *DetectResponse) Reset() {
+	*x = DetectResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectResponse) ProtoMessage() {}
+
+func (x *DetectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectResponse.ProtoReflect.Descriptor instead.
+func (*DetectResponse) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DetectResponse) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *DetectResponse) GetSegments() []*Segment {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+type StreamConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format *AudioFormat `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// The speech probability threshold. Defaults to the server one.
+	Threshold float32 `protobuf:"fixed32,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// Whether to send the speech probability of each window.
+	Probabilities bool `protobuf:"varint,3,opt,name=probabilities,proto3" json:"probabilities,omitempty"`
+}
+
+func (x *StreamConfig) Reset() {
+	*x = StreamConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamConfig) ProtoMessage() {}
+
+func (x *StreamConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamConfig.ProtoReflect.Descriptor instead.
+func (*StreamConfig) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamConfig) GetFormat() *AudioFormat {
+	if x != nil {
+		return x.Format
+	}
+	return nil
+}
+
+func (x *StreamConfig) GetThreshold() float32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *StreamConfig) GetProbabilities() bool {
+	if x != nil {
+		return x.Probabilities
+	}
+	return false
+}
+
+type DetectStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Request:
+	//	*DetectStreamRequest_Config
+	//	*DetectStreamRequest_Audio
+	Request isDetectStreamRequest_Request `protobuf_oneof:"request"`
+}
+
+func (x *DetectStreamRequest) Reset() {
+	*x = DetectStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectStreamRequest) ProtoMessage() {}
+
+func (x *DetectStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectStreamRequest.ProtoReflect.Descriptor instead.
+func (*DetectStreamRequest) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{5}
+}
+
+func (m *DetectStreamRequest) GetRequest() isDetectStreamRequest_Request {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (x *DetectStreamRequest) GetConfig() *StreamConfig {
+	if x, ok := x.GetRequest().(*DetectStreamRequest_Config); ok {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *DetectStreamRequest) GetAudio() []byte {
+	if x, ok := x.GetRequest().(*DetectStreamRequest_Audio); ok {
+		return x.Audio
+	}
+	return nil
+}
+
+type isDetectStreamRequest_Request interface {
+	isDetectStreamRequest_Request()
+}
+
+type DetectStreamRequest_Config struct {
+	Config *StreamConfig `protobuf:"bytes,1,opt,name=config,proto3,oneof"`
+}
+
+type DetectStreamRequest_Audio struct {
+	// A chunk of PCM audio, which doesn't need to be aligned on samples.
+	Audio []byte `protobuf:"bytes,2,opt,name=audio,proto3,oneof"`
+}
+
+func (*DetectStreamRequest_Config) isDetectStreamRequest_Request() {}
+
+func (*DetectStreamRequest_Audio) isDetectStreamRequest_Request() {}
+
+// SpeechStart is sent when speech starts.
+type SpeechStart struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start float64 `protobuf:"fixed64,1,opt,name=start,proto3" json:"start,omitempty"`
+}
+
+func (x *SpeechStart) Reset() {
+	*x = SpeechStart{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SpeechStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeechStart) ProtoMessage() {}
+
+func (x *SpeechStart) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeechStart.ProtoReflect.Descriptor instead.
+func (*SpeechStart) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SpeechStart) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+// SpeechEnd is sent when speech ends.
+type SpeechEnd struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start float64 `protobuf:"fixed64,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   float64 `protobuf:"fixed64,2,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *SpeechEnd) Reset() {
+	*x = SpeechEnd{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SpeechEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeechEnd) ProtoMessage() {}
+
+func (x *SpeechEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeechEnd.ProtoReflect.Descriptor instead.
+func (*SpeechEnd) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SpeechEnd) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *SpeechEnd) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// Probability is the speech probability of the window ending at time.
+type Probability struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time        float64 `protobuf:"fixed64,1,opt,name=time,proto3" json:"time,omitempty"`
+	Probability float32 `protobuf:"fixed32,2,opt,name=probability,proto3" json:"probability,omitempty"`
+}
+
+func (x *Probability) Reset() {
+	*x = Probability{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Probability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Probability) ProtoMessage() {}
+
+func (x *Probability) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Probability.ProtoReflect.Descriptor instead.
+func (*Probability) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Probability) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Probability) GetProbability() float32 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+type DetectStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*DetectStreamResponse_SpeechStart
+	//	*DetectStreamResponse_SpeechEnd
+	//	*DetectStreamResponse_Probability
+	Event isDetectStreamResponse_Event `protobuf_oneof:"event"`
+}
+
+func (x *DetectStreamResponse) Reset() {
+	*x = DetectStreamResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vad_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectStreamResponse) ProtoMessage() {}
+
+func (x *DetectStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vad_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectStreamResponse.ProtoReflect.Descriptor instead.
+func (*DetectStreamResponse) Descriptor() ([]byte, []int) {
+	return file_vad_proto_rawDescGZIP(), []int{9}
+}
+
+func (m *DetectStreamResponse) GetEvent() isDetectStreamResponse_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *DetectStreamResponse) GetSpeechStart() *SpeechStart {
+	if x, ok := x.GetEvent().(*DetectStreamResponse_SpeechStart); ok {
+		return x.SpeechStart
+	}
+	return nil
+}
+
+func (x *DetectStreamResponse) GetSpeechEnd() *SpeechEnd {
+	if x, ok := x.GetEvent().(*DetectStreamResponse_SpeechEnd); ok {
+		return x.SpeechEnd
+	}
+	return nil
+}
+
+func (x *DetectStreamResponse) GetProbability() *Probability {
+	if x, ok := x.GetEvent().(*DetectStreamResponse_Probability); ok {
+		return x.Probability
+	}
+	return nil
+}
+
+type isDetectStreamResponse_Event interface {
+	isDetectStreamResponse_Event()
+}
+
+type DetectStreamResponse_SpeechStart struct {
+	SpeechStart *SpeechStart `protobuf:"bytes,1,opt,name=speech_start,json=speechStart,proto3,oneof"`
+}
+
+type DetectStreamResponse_SpeechEnd struct {
+	SpeechEnd *SpeechEnd `protobuf:"bytes,2,opt,name=speech_end,json=speechEnd,proto3,oneof"`
+}
+
+type DetectStreamResponse_Probability struct {
+	Probability *Probability `protobuf:"bytes,3,opt,name=probability,proto3,oneof"`
+}
+
+func (*DetectStreamResponse_SpeechStart) isDetectStreamResponse_Event() {}
+
+func (*DetectStreamResponse_SpeechEnd) isDetectStreamResponse_Event() {}
+
+func (*DetectStreamResponse_Probability) isDetectStreamResponse_Event() {}
+
+var File_vad_proto protoreflect.FileDescriptor
+
+var file_vad_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x76, 0x61, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x73, 0x69, 0x6c,
+	0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x22, 0x67, 0x0a, 0x0b, 0x41, 0x75, 0x64,
+	0x69, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x37, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x73, 0x69, 0x6c,
+	0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x45,
+	0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e,
+	0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x61,
+	0x74, 0x65, 0x22, 0x58, 0x0a, 0x0d, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06,
+	0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x22, 0x31, 0x0a, 0x07,
+	0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x22,
+	0x5f, 0x0a, 0x0e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a,
+	0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x22, 0x85, 0x01, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x12, 0x31, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x62, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x22, 0x6e, 0x0a, 0x13, 0x44, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x34, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x48, 0x00, 0x52, 0x06, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x42, 0x09, 0x0a,
+	0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x23, 0x0a, 0x0b, 0x53, 0x70, 0x65, 0x65,
+	0x63, 0x68, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x22, 0x33, 0x0a,
+	0x09, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x45, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x65,
+	0x6e, 0x64, 0x22, 0x43, 0x0a, 0x0b, 0x50, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x62,
+	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x22, 0xd8, 0x01, 0x0a, 0x14, 0x44, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x3e, 0x0a, 0x0c, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76,
+	0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x12, 0x38, 0x0a, 0x0a, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x45, 0x6e, 0x64, 0x48, 0x00, 0x52,
+	0x09, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x45, 0x6e, 0x64, 0x12, 0x3d, 0x0a, 0x0b, 0x70, 0x72,
+	0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x48, 0x00, 0x52, 0x0b, 0x70, 0x72,
+	0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x2a, 0x7b, 0x0a, 0x0d, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x45, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x1a, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x45, 0x4e, 0x43,
+	0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x45, 0x4e, 0x43,
+	0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x46, 0x33, 0x32, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x18, 0x0a,
+	0x14, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f,
+	0x53, 0x31, 0x36, 0x4c, 0x45, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12, 0x41, 0x55, 0x44, 0x49, 0x4f,
+	0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x41, 0x56, 0x10, 0x03, 0x32,
+	0xa5, 0x01, 0x0a, 0x03, 0x56, 0x41, 0x44, 0x12, 0x43, 0x0a, 0x06, 0x44, 0x65, 0x74, 0x65, 0x63,
+	0x74, 0x12, 0x1b, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c,
+	0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x0c,
+	0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x21, 0x2e, 0x73,
+	0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x22, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x76, 0x61, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x74, 0x65, 0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x72, 0x34, 0x35,
+	0x2f, 0x73, 0x69, 0x6c, 0x65, 0x72, 0x6f, 0x2d, 0x76, 0x61, 0x64, 0x2d, 0x67, 0x6f, 0x2f, 0x76,
+	0x61, 0x64, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_vad_proto_rawDescOnce sync.Once
+	file_vad_proto_rawDescData = file_vad_proto_rawDesc
+)
+
+func file_vad_proto_rawDescGZIP() []byte {
+	file_vad_proto_rawDescOnce.Do(func() {
+		file_vad_proto_rawDescData = protoimpl.X.CompressGZIP(file_vad_proto_rawDescData)
+	})
+	return file_vad_proto_rawDescData
+}
+
+var file_vad_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_vad_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_vad_proto_goTypes = []interface{}{
+	(AudioEncoding)(0),           // 0: silerovad.v1.AudioEncoding
+	(*AudioFormat)(nil),          // 1: silerovad.v1.AudioFormat
+	(*DetectRequest)(nil),        // 2: silerovad.v1.DetectRequest
+	(*Segment)(nil),              // 3: silerovad.v1.Segment
+	(*DetectResponse)(nil),       // 4: silerovad.v1.DetectResponse
+	(*StreamConfig)(nil),         // 5: silerovad.v1.StreamConfig
+	(*DetectStreamRequest)(nil),  // 6: silerovad.v1.DetectStreamRequest
+	(*SpeechStart)(nil),          // 7: silerovad.v1.SpeechStart
+	(*SpeechEnd)(nil),            // 8: silerovad.v1.SpeechEnd
+	(*Probability)(nil),          // 9: silerovad.v1.Probability
+	(*DetectStreamResponse)(nil), // 10: silerovad.v1.DetectStreamResponse
+}
+var file_vad_proto_depIdxs = []int32{
+	0,  // 0: silerovad.v1.AudioFormat.encoding:type_name -> silerovad.v1.AudioEncoding
+	1,  // 1: silerovad.v1.DetectRequest.format:type_name -> silerovad.v1.AudioFormat
+	3,  // 2: silerovad.v1.DetectResponse.segments:type_name -> silerovad.v1.Segment
+	1,  // 3: silerovad.v1.StreamConfig.format:type_name -> silerovad.v1.AudioFormat
+	5,  // 4: silerovad.v1.DetectStreamRequest.config:type_name -> silerovad.v1.StreamConfig
+	7,  // 5: silerovad.v1.DetectStreamResponse.speech_start:type_name -> silerovad.v1.SpeechStart
+	8,  // 6: silerovad.v1.DetectStreamResponse.speech_end:type_name -> silerovad.v1.SpeechEnd
+	9,  // 7: silerovad.v1.DetectStreamResponse.probability:type_name -> silerovad.v1.Probability
+	2,  // 8: silerovad.v1.VAD.Detect:input_type -> silerovad.v1.DetectRequest
+	6,  // 9: silerovad.v1.VAD.DetectStream:input_type -> silerovad.v1.DetectStreamRequest
+	4,  // 10: silerovad.v1.VAD.Detect:output_type -> silerovad.v1.DetectResponse
+	10, // 11: silerovad.v1.VAD.DetectStream:output_type -> silerovad.v1.DetectStreamResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_vad_proto_init() }
+func file_vad_proto_init() {
+	if File_vad_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_vad_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AudioFormat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Segment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SpeechStart); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SpeechEnd); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Probability); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vad_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectStreamResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_vad_proto_msgTypes[5].OneofWrappers = []interface{}{
+		(*DetectStreamRequest_Config)(nil),
+		(*DetectStreamRequest_Audio)(nil),
+	}
+	file_vad_proto_msgTypes[9].OneofWrappers = []interface{}{
+		(*DetectStreamResponse_SpeechStart)(nil),
+		(*DetectStreamResponse_SpeechEnd)(nil),
+		(*DetectStreamResponse_Probability)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_vad_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_vad_proto_goTypes,
+		DependencyIndexes: file_vad_proto_depIdxs,
+		EnumInfos:         file_vad_proto_enumTypes,
+		MessageInfos:      file_vad_proto_msgTypes,
+	}.Build()
+	File_vad_proto = out.File
+	file_vad_proto_rawDesc = nil
+	file_vad_proto_goTypes = nil
+	file_vad_proto_depIdxs = nil
+}
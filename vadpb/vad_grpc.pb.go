@@ -0,0 +1,168 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: vad.proto
+
+package vadpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VAD_Detect_FullMethodName       = "/silerovad.v1.VAD/Detect"
+	VAD_DetectStream_FullMethodName = "/silerovad.v1.VAD/DetectStream"
+)
+
+// VADClient is the client API for VAD service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// VAD detects speech in audio.
+type VADClient interface {
+	// Detect returns the speech segments of a complete audio input.
+	Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error)
+	// DetectStream detects speech in real time. The first request must hold the
+	// stream config, the following ones audio chunks. Speech events are sent as
+	// audio gets processed. Once the client closes its side of the stream, any
+	// open segment gets ended and the server closes the stream.
+	DetectStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DetectStreamRequest, DetectStreamResponse], error)
+}
+
+type vADClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVADClient(cc grpc.ClientConnInterface) VADClient {
+	return &vADClient{cc}
+}
+
+func (c *vADClient) Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DetectResponse)
+	err := c.cc.Invoke(ctx, VAD_Detect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vADClient) DetectStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DetectStreamRequest, DetectStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VAD_ServiceDesc.Streams[0], VAD_DetectStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DetectStreamRequest, DetectStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VAD_DetectStreamClient = grpc.BidiStreamingClient[DetectStreamRequest, DetectStreamResponse]
+
+// VADServer is the server API for VAD service.
+// All implementations must embed UnimplementedVADServer
+// for forward compatibility.
+//
+// VAD detects speech in audio.
+type VADServer interface {
+	// Detect returns the speech segments of a complete audio input.
+	Detect(context.Context, *DetectRequest) (*DetectResponse, error)
+	// DetectStream detects speech in real time. The first request must hold the
+	// stream config, the following ones audio chunks. Speech events are sent as
+	// audio gets processed. Once the client closes its side of the stream, any
+	// open segment gets ended and the server closes the stream.
+	DetectStream(grpc.BidiStreamingServer[DetectStreamRequest, DetectStreamResponse]) error
+	mustEmbedUnimplementedVADServer()
+}
+
+// UnimplementedVADServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVADServer struct{}
+
+func (UnimplementedVADServer) Detect(context.Context, *DetectRequest) (*DetectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detect not implemented")
+}
+func (UnimplementedVADServer) DetectStream(grpc.BidiStreamingServer[DetectStreamRequest, DetectStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DetectStream not implemented")
+}
+func (UnimplementedVADServer) mustEmbedUnimplementedVADServer() {}
+func (UnimplementedVADServer) testEmbeddedByValue()             {}
+
+// UnsafeVADServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VADServer will
+// result in compilation errors.
+type UnsafeVADServer interface {
+	mustEmbedUnimplementedVADServer()
+}
+
+func RegisterVADServer(s grpc.ServiceRegistrar, srv VADServer) {
+	// If the following call pancis, it indicates UnimplementedVADServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VAD_ServiceDesc, srv)
+}
+
+func _VAD_Detect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VADServer).Detect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VAD_Detect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VADServer).Detect(ctx, req.(*DetectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VAD_DetectStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VADServer).DetectStream(&grpc.GenericServerStream[DetectStreamRequest, DetectStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VAD_DetectStreamServer = grpc.BidiStreamingServer[DetectStreamRequest, DetectStreamResponse]
+
+// VAD_ServiceDesc is the grpc.ServiceDesc for VAD service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VAD_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "silerovad.v1.VAD",
+	HandlerType: (*VADServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Detect",
+			Handler:    _VAD_Detect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DetectStream",
+			Handler:       _VAD_DetectStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "vad.proto",
+}
@@ -0,0 +1,313 @@
+package vadwebrtc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/streamer45/silero-vad-go/speech"
+	"github.com/streamer45/silero-vad-go/stream"
+)
+
+var (
+	// ErrTrackExists is returned when adding a track twice.
+	ErrTrackExists = errors.New("track already exists")
+	// ErrUnknownTrack is returned when writing to or removing a track that
+	// wasn't added.
+	ErrUnknownTrack = errors.New("unknown track")
+	// ErrClosed is returned when using a closed Adapter.
+	ErrClosed = errors.New("adapter is closed")
+)
+
+type Config struct {
+	// The sample rate of the decoded track audio, which gets resampled to the
+	// detector one. Defaults to 48000, the Opus sample rate.
+	SampleRate int
+	// The number of interleaved channels of the decoded audio, which get
+	// downmixed. Defaults to 1.
+	Channels int
+	// The maximum duration to wait for a detector when adding a track. Zero
+	// means waiting as long as the context passed to AddTrack allows.
+	AcquireTimeout time.Duration
+	// The number of events buffered before writes block. Defaults to 64.
+	EventBuffer int
+}
+
+func (c Config) IsValid() error {
+	if c.SampleRate < 0 {
+		return fmt.Errorf("invalid SampleRate: should be a positive number")
+	}
+
+	if c.Channels < 0 {
+		return fmt.Errorf("invalid Channels: should be a positive number")
+	}
+
+	if c.AcquireTimeout < 0 {
+		return fmt.Errorf("invalid AcquireTimeout: should be a positive duration")
+	}
+
+	if c.EventBuffer < 0 {
+		return fmt.Errorf("invalid EventBuffer: should be a positive number")
+	}
+
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	if c.SampleRate == 0 {
+		c.SampleRate = 48000
+	}
+	if c.Channels == 0 {
+		c.Channels = 1
+	}
+	if c.EventBuffer == 0 {
+		c.EventBuffer = 64
+	}
+}
+
+// Event reports a track starting or stopping to speak.
+type Event struct {
+	// The ID of the track, as passed to AddTrack.
+	TrackID string
+	// Whether the track is now speaking.
+	Speaking bool
+	// The time of the change in seconds from the first sample written to the
+	// track.
+	Time float64
+}
+
+// Adapter runs streaming detection over the decoded audio of WebRTC tracks,
+// such as the remote tracks of a Pion SFU, with one detector per track.
+// Tracks are identified by ID, typically TrackRemote.ID(), and can be written
+// to from different goroutines.
+//
+// Events are delivered on a single channel for all tracks. They should be
+// consumed promptly since writes block when it's full.
+type Adapter struct {
+	cfg    Config
+	pool   *speech.Pool
+	events chan Event
+
+	mu     sync.Mutex
+	tracks map[string]*track
+	closed bool
+	// removals tracks in-flight RemoveTrack calls, which may still emit
+	// events, so that Close waits for them before closing the channel.
+	removals sync.WaitGroup
+}
+
+func NewAdapter(pool *speech.Pool, cfg Config) (*Adapter, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("invalid nil pool")
+	}
+
+	if err := cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.setDefaults()
+
+	return &Adapter{
+		cfg:    cfg,
+		pool:   pool,
+		events: make(chan Event, cfg.EventBuffer),
+		tracks: make(map[string]*track),
+	}, nil
+}
+
+// Events returns the channel events are delivered on. It gets closed by Close.
+func (a *Adapter) Events() <-chan Event {
+	return a.events
+}
+
+// AddTrack acquires a detector for the track with the given ID.
+func (a *Adapter) AddTrack(ctx context.Context, id string) error {
+	if err := a.checkAdd(id); err != nil {
+		return err
+	}
+
+	// Not holding the lock while waiting for a detector, so that other tracks
+	// keep being processed.
+	if a.cfg.AcquireTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.cfg.AcquireTimeout)
+		defer cancel()
+	}
+	sd, err := a.pool.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get detector: %w", err)
+	}
+
+	session, err := stream.NewSession(sd, stream.Config{SampleRate: a.cfg.SampleRate})
+	if err != nil {
+		a.put(sd)
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// The track may have been added, or the adapter closed, meanwhile.
+	if err := a.checkAddLocked(id); err != nil {
+		a.put(sd)
+		return err
+	}
+	a.tracks[id] = &track{
+		id:      id,
+		sd:      sd,
+		session: session,
+	}
+
+	return nil
+}
+
+func (a *Adapter) checkAdd(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.checkAddLocked(id)
+}
+
+func (a *Adapter) checkAddLocked(id string) error {
+	if a.closed {
+		return ErrClosed
+	}
+	if _, ok := a.tracks[id]; ok {
+		return fmt.Errorf("failed to add track %q: %w", id, ErrTrackExists)
+	}
+	return nil
+}
+
+// Write processes decoded samples of the track with the given ID, interleaved
+// if the audio has multiple channels, emitting speaking changes.
+func (a *Adapter) Write(ctx context.Context, id string, samples []float32) error {
+	a.mu.Lock()
+	t, ok := a.tracks[id]
+	a.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("failed to write to track %q: %w", id, ErrUnknownTrack)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// The track may have been removed meanwhile.
+	if t.session == nil {
+		return fmt.Errorf("failed to write to track %q: %w", id, ErrUnknownTrack)
+	}
+
+	if a.cfg.Channels > 1 {
+		samples = t.downmix(samples, a.cfg.Channels)
+	}
+
+	events, err := t.session.WriteSamples(ctx, samples)
+	a.emit(t, events)
+	return err
+}
+
+// RemoveTrack flushes the track with the given ID, emitting a last event if it
+// was speaking, and returns its detector to the pool.
+func (a *Adapter) RemoveTrack(id string) error {
+	a.mu.Lock()
+	t, ok := a.tracks[id]
+	if ok {
+		delete(a.tracks, id)
+		a.removals.Add(1)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("failed to remove track %q: %w", id, ErrUnknownTrack)
+	}
+	defer a.removals.Done()
+
+	return a.closeTrack(t)
+}
+
+// Speaking returns whether the track with the given ID is speaking.
+func (a *Adapter) Speaking(id string) bool {
+	a.mu.Lock()
+	t, ok := a.tracks[id]
+	a.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.session != nil && t.session.Speaking()
+}
+
+// Close removes all tracks and closes the events channel.
+func (a *Adapter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return ErrClosed
+	}
+	a.closed = true
+	tracks := a.tracks
+	a.tracks = nil
+	a.mu.Unlock()
+
+	var errs []error
+	for _, t := range tracks {
+		errs = append(errs, a.closeTrack(t))
+	}
+	a.removals.Wait()
+	close(a.events)
+
+	return errors.Join(errs...)
+}
+
+func (a *Adapter) closeTrack(t *track) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Flushing happens on removal, which shouldn't depend on any write context.
+	events, err := t.session.Flush(context.Background())
+	a.emit(t, events)
+	t.session = nil
+	a.put(t.sd)
+	t.sd = nil
+
+	return err
+}
+
+func (a *Adapter) put(sd *speech.Detector) {
+	if err := a.pool.Put(sd); err != nil {
+		slog.Error("failed to return detector", slog.String("err", err.Error()))
+	}
+}
+
+func (a *Adapter) emit(t *track, events []stream.Event) {
+	for _, ev := range events {
+		switch ev.Type {
+		case stream.EventSpeechStart:
+			a.events <- Event{TrackID: t.id, Speaking: true, Time: ev.Start}
+		case stream.EventSpeechEnd:
+			a.events <- Event{TrackID: t.id, Speaking: false, Time: ev.End}
+		}
+	}
+}
+
+// track is the detection state of a single track.
+type track struct {
+	id string
+
+	mu      sync.Mutex
+	sd      *speech.Detector
+	session *stream.Session
+	// Scratch buffer for downmixed samples.
+	mono []float32
+}
+
+func (t *track) downmix(samples []float32, channels int) []float32 {
+	t.mono = t.mono[:0]
+	for i := 0; i+channels <= len(samples); i += channels {
+		var sum float32
+		for _, s := range samples[i : i+channels] {
+			sum += s
+		}
+		t.mono = append(t.mono, sum/float32(channels))
+	}
+	return t.mono
+}
@@ -0,0 +1,174 @@
+package vadwebrtc
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamer45/silero-vad-go/audio"
+	"github.com/streamer45/silero-vad-go/speech"
+)
+
+func TestConfigIsValid(t *testing.T) {
+	require.NoError(t, Config{}.IsValid())
+	require.NoError(t, Config{SampleRate: 48000, Channels: 2, AcquireTimeout: time.Second, EventBuffer: 10}.IsValid())
+	require.EqualError(t, Config{SampleRate: -1}.IsValid(), "invalid SampleRate: should be a positive number")
+	require.EqualError(t, Config{Channels: -1}.IsValid(), "invalid Channels: should be a positive number")
+	require.EqualError(t, Config{AcquireTimeout: -1}.IsValid(), "invalid AcquireTimeout: should be a positive duration")
+	require.EqualError(t, Config{EventBuffer: -1}.IsValid(), "invalid EventBuffer: should be a positive number")
+}
+
+func TestNewAdapter(t *testing.T) {
+	_, err := NewAdapter(nil, Config{})
+	require.EqualError(t, err, "invalid nil pool")
+}
+
+func TestDownmix(t *testing.T) {
+	var tr track
+	require.Equal(t, []float32{0.5, -0.25}, tr.downmix([]float32{1, 0, -0.5, 0, 1}, 2))
+	require.Equal(t, []float32{0.5}, tr.downmix([]float32{0.25, 0.5, 0.75}, 3))
+}
+
+func TestAdapter(t *testing.T) {
+	cfg := speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+	pool, err := speech.NewPool(speech.PoolConfig{DetectorConfig: cfg, MaxSize: 2})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, pool.Close())
+	}()
+
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+
+	sd, err := speech.NewDetector(cfg)
+	require.NoError(t, err)
+	expected, err := sd.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, sd.Destroy())
+	require.NotEmpty(t, expected)
+
+	// Synthesizing the decoded track audio, at the Opus rate.
+	speechTrack := audio.Resample(samples, 16000, 48000)
+	silentTrack := make([]float32, len(speechTrack))
+
+	a, err := NewAdapter(pool, Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, a.AddTrack(ctx, "speech"))
+	require.NoError(t, a.AddTrack(ctx, "silence"))
+	require.ErrorIs(t, a.AddTrack(ctx, "speech"), ErrTrackExists)
+	require.ErrorIs(t, a.Write(ctx, "unknown", speechTrack[:960]), ErrUnknownTrack)
+	require.ErrorIs(t, a.RemoveTrack("unknown"), ErrUnknownTrack)
+
+	done := make(chan []Event, 1)
+	go func() {
+		var events []Event
+		for ev := range a.Events() {
+			events = append(events, ev)
+		}
+		done <- events
+	}()
+
+	// Tracks are written concurrently, in 20ms frames.
+	var wg sync.WaitGroup
+	for id, track := range map[string][]float32{"speech": speechTrack, "silence": silentTrack} {
+		wg.Add(1)
+		go func(id string, track []float32) {
+			defer wg.Done()
+			for i := 0; i < len(track); i += 960 {
+				if err := a.Write(ctx, id, track[i:min(i+960, len(track))]); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(id, track)
+	}
+	wg.Wait()
+	require.False(t, a.Speaking("silence"))
+
+	require.NoError(t, a.Close())
+	require.ErrorIs(t, a.Close(), ErrClosed)
+	require.ErrorIs(t, a.AddTrack(ctx, "other"), ErrClosed)
+	events := <-done
+
+	require.Len(t, events, 2*len(expected))
+	for i, ev := range events {
+		require.Equal(t, "speech", ev.TrackID)
+		seg := expected[i/2]
+		if i%2 == 0 {
+			require.True(t, ev.Speaking)
+			require.InDelta(t, seg.SpeechStartAt, ev.Time, 0.05)
+			continue
+		}
+		require.False(t, ev.Speaking)
+		if seg.SpeechEndAt > 0 {
+			require.InDelta(t, seg.SpeechEndAt, ev.Time, 0.05)
+		}
+	}
+}
+
+func TestAdapterRemoveTrackClose(t *testing.T) {
+	pool, err := speech.NewPool(speech.PoolConfig{
+		DetectorConfig: speech.DetectorConfig{
+			ModelPath:  "../testfiles/silero_vad.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		},
+		MaxSize: 1,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, pool.Close())
+	}()
+
+	pcm, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	// The samples end while speaking.
+	samples := audio.DecodeSamples(nil, pcm, audio.EncodingFloat32LE)
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		a, err := NewAdapter(pool, Config{SampleRate: 16000})
+		require.NoError(t, err)
+		require.NoError(t, a.AddTrack(ctx, "speech"))
+		require.NoError(t, a.Write(ctx, "speech", samples))
+		require.True(t, a.Speaking("speech"))
+
+		// Removing and closing concurrently neither panics nor loses the
+		// last event.
+		var wg sync.WaitGroup
+		var removeErr, closeErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			removeErr = a.RemoveTrack("speech")
+		}()
+		go func() {
+			defer wg.Done()
+			closeErr = a.Close()
+		}()
+		wg.Wait()
+		require.NoError(t, closeErr)
+		if removeErr != nil {
+			require.ErrorIs(t, removeErr, ErrUnknownTrack)
+		}
+
+		var events []Event
+		for ev := range a.Events() {
+			events = append(events, ev)
+		}
+		require.NotEmpty(t, events)
+		require.Equal(t, Event{TrackID: "speech", Speaking: false, Time: events[len(events)-1].Time},
+			events[len(events)-1])
+	}
+}